@@ -0,0 +1,39 @@
+package ast
+
+import (
+	"strings"
+
+	"gotlin/frontend/token"
+)
+
+// Annotation is `@Name` or `@Name(args)` written in front of a declaration,
+// a parameter or an expression. Args and Names are as in a CallExpr.
+type Annotation struct {
+	At    token.Token
+	Name  string
+	Args  []Expr
+	Names []token.Token
+}
+
+// SimpleName is the annotation class name without its package.
+func (a *Annotation) SimpleName() string {
+	return a.Name[strings.LastIndex(a.Name, ".")+1:]
+}
+
+type Annotations []*Annotation
+
+// Find returns the first annotation called name, which may be written
+// either qualified or by its simple name.
+func (a Annotations) Find(name string) (*Annotation, bool) {
+	for _, annotation := range a {
+		if annotation.Name == name || annotation.SimpleName() == name {
+			return annotation, true
+		}
+	}
+	return nil, false
+}
+
+func (a Annotations) Has(name string) bool {
+	_, ok := a.Find(name)
+	return ok
+}
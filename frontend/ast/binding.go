@@ -0,0 +1,59 @@
+package ast
+
+import (
+	"gotlin/frontend/token"
+)
+
+// Kinds of declarations a name can be bound to.
+const (
+	SymbolValue     = "val"
+	SymbolVariable  = "var"
+	SymbolParameter = "parameter"
+	SymbolFunction  = "fun"
+	SymbolClass     = "class"
+	SymbolObject    = "object"
+	SymbolTypeAlias = "typealias"
+	SymbolImport    = "import"
+)
+
+// Storage tells where the value of a declaration lives at run time.
+type Storage int
+
+const (
+	// StorageLocal is a slot in the frame of the function, lambda or
+	// constructor declaring it.
+	StorageLocal Storage = iota
+	// StorageGlobal is a top-level declaration, or a nested class or
+	// object, reached without an instance.
+	StorageGlobal
+	// StorageMember is a member of the instance `this` refers to.
+	StorageMember
+	// StorageBuiltin is provided by the standard library.
+	StorageBuiltin
+)
+
+// Symbol is a declaration names are bound to.
+type Symbol struct {
+	Name    token.Token
+	Kind    string
+	Storage Storage
+	// Slot is the index of a local in the frame of its function, or of a
+	// top-level declaration among those of its package. Locals of blocks
+	// that have ended give their slots back to later ones.
+	Slot int
+	// Decl is the node declaring the symbol: the declaration statement, a
+	// *ParameterWithOptionalType, *ClassParam, *LambdaParameter,
+	// *DestructuringEntry, *EnumEntry, *CatchClause or *ImportDirective,
+	// the *ForStmt of a loop variable, the *LambdaExpr of an implicit `it`
+	// and the property of a backing field. It is nil for built-ins.
+	Decl any
+}
+
+// Binding ties a use of a name to the declaration it refers to. Depth
+// counts the frames between the use and a local declaration: 0 for a local
+// of the function the use is in, more for one it captures from an
+// enclosing function. It is 0 for every other storage.
+type Binding struct {
+	Symbol *Symbol
+	Depth  int
+}
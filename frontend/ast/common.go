@@ -1,11 +1,97 @@
 package ast
 
+import (
+	"strings"
+
+	"gotlin/frontend/token"
+)
+
+// ParameterWithOptionalType is a parameter of a function. A Vararg
+// parameter takes any number of arguments, and one with a DefaultValue may
+// be left out.
 type ParameterWithOptionalType struct {
-	Name string
-	Type Type
+	Annotations  Annotations
+	Name         string
+	Position     token.Pos
+	Type         Type
+	Vararg       bool
+	DefaultValue Expr
 }
 
+// Vararg is the soft keyword of a parameter taking any number of arguments.
+const Vararg = "vararg"
+
 type FunctionBody struct {
 	Expr  Expr
 	Block []Stmt
 }
+
+// Soft keywords naming property accessors and the backing field they may
+// refer to.
+const (
+	Getter       = "get"
+	Setter       = "set"
+	BackingField = "field"
+)
+
+// PropertyAccessor is a custom `get()` or `set(value)` of a property. An
+// accessor written only to change its visibility, `private set`, has no
+// Body.
+type PropertyAccessor struct {
+	Modifiers Modifiers
+	Keyword   token.Token
+	// Parameter is the value a setter is passed.
+	Parameter *ParameterWithOptionalType
+	Body      *FunctionBody
+}
+
+// By is the soft keyword introducing a delegate.
+const By = "by"
+
+// SuperType is an entry of a supertype list. A superclass is invoked with
+// constructor arguments, `: Base(1)`, an interface is not. An interface may
+// be implemented by a Delegate instead, `: List<T> by inner`. Args and
+// Names are as in a CallExpr.
+type SuperType struct {
+	Type     Type
+	Args     []Expr
+	Names    []token.Token
+	Call     bool
+	Delegate Expr
+}
+
+// PackageHeader is `package com.acme.util`.
+type PackageHeader struct {
+	Package token.Token
+	Name    string
+}
+
+// ImportDirective is `import com.acme.util.*`, which imports every
+// declaration of a package, or `import com.acme.util.Strings as S`, which
+// imports one declaration under an optional alias.
+type ImportDirective struct {
+	Import token.Token
+	Path   string
+	All    bool
+	Alias  token.Token
+}
+
+// Package is the package the directive imports from.
+func (d *ImportDirective) Package() string {
+	if d.All {
+		return d.Path
+	}
+	i := strings.LastIndex(d.Path, ".")
+	if i < 0 {
+		return ""
+	}
+	return d.Path[:i]
+}
+
+// ImportedName is the name the imported declaration is visible under.
+func (d *ImportDirective) ImportedName() string {
+	if d.Alias.Spelling != "" {
+		return d.Alias.Spelling
+	}
+	return d.Path[strings.LastIndex(d.Path, ".")+1:]
+}
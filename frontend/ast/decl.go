@@ -0,0 +1,61 @@
+package ast
+
+import (
+	"gotlin/frontend/token"
+)
+
+// DeclaredNames returns the names a declaration statement introduces in the
+// body that holds it. Extensions are left out: they are declared on their
+// receiver. A companion object without a name is called Companion, at its
+// `object` keyword.
+func DeclaredNames(stmt Stmt) []token.Token {
+	switch decl := stmt.(type) {
+	case *FunctionDecl:
+		if decl.Name.Spelling != "" && decl.Receiver == nil {
+			return []token.Token{decl.Name}
+		}
+	case *VariableDecl:
+		if decl.Receiver == nil {
+			return []token.Token{decl.Name}
+		}
+	case *ClassDeclStmt:
+		return []token.Token{decl.Name}
+	case *ObjectDeclStmt:
+		name := decl.Name
+		name.Spelling = decl.ObjectName()
+		if decl.Name.Spelling == "" {
+			name.Position = decl.Object.Position
+		}
+		return []token.Token{name}
+	case *TypeAliasDecl:
+		return []token.Token{decl.Name}
+	case *DestructuringDecl:
+		var names []token.Token
+		for _, entry := range decl.Pattern.Entries {
+			if entry.Name.Spelling != Underscore {
+				names = append(names, entry.Name)
+			}
+		}
+		return names
+	}
+	return nil
+}
+
+// DeclarationModifiers returns the modifiers of a declaration statement.
+func DeclarationModifiers(stmt Stmt) Modifiers {
+	switch decl := stmt.(type) {
+	case *ClassDeclStmt:
+		return decl.Modifiers
+	case *ObjectDeclStmt:
+		return decl.Modifiers
+	case *FunctionDecl:
+		return decl.Modifiers
+	case *VariableDecl:
+		return decl.Modifiers
+	case *TypeAliasDecl:
+		return decl.Modifiers
+	case *DestructuringDecl:
+		return decl.Modifiers
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package ast
+
+import (
+	"fmt"
+
+	"gotlin/frontend/token"
+)
+
+// Underscore names a component that is not used.
+const Underscore = "_"
+
+// DestructuringPattern is `(name, _, age: Int)`, which binds the
+// components of a value to new variables.
+type DestructuringPattern struct {
+	Open    token.Token
+	Entries []*DestructuringEntry
+}
+
+// DestructuringEntry binds one component. Entries named Underscore are
+// skipped.
+type DestructuringEntry struct {
+	Name token.Token
+	Type Type
+}
+
+// ComponentFunction is the name of the function that yields the component
+// bound by the i-th entry, counting from zero.
+func ComponentFunction(i int) string {
+	return fmt.Sprintf("component%d", i+1)
+}
+
+// DestructuringDecl is `val (name, age) = person`.
+type DestructuringDecl struct {
+	Annotations Annotations
+	Modifiers   Modifiers
+	Pattern     *DestructuringPattern
+	Value       Expr
+	ReadOnly    bool
+}
+
+func (s *DestructuringDecl) stmt() {}
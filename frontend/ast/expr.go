@@ -24,31 +24,43 @@ type UnaryExpr struct {
 func (e *UnaryExpr) expr() {}
 
 type IntLiteral struct {
-	Value int64
+	Value    int64
+	Position token.Pos
 }
 
 func (e *IntLiteral) expr() {}
 
 type DoubleLiteral struct {
-	Value float64
+	Value    float64
+	Position token.Pos
 }
 
 func (e *DoubleLiteral) expr() {}
 
 type BoolLiteral struct {
-	Value bool
+	Value    bool
+	Position token.Pos
 }
 
 func (e *BoolLiteral) expr() {}
 
+// NullLiteral is `null`, the only value of type Nothing?.
+type NullLiteral struct {
+	Position token.Pos
+}
+
+func (e *NullLiteral) expr() {}
+
 type StringLiteral struct {
-	Value string
+	Value    string
+	Position token.Pos
 }
 
 func (e *StringLiteral) expr() {}
 
 // fun + parameters optional type
 type FunctionLiteral struct {
+	Fun        token.Token
 	Parameters []*ParameterWithOptionalType
 	Type       Type
 	Body       *FunctionBody
@@ -62,21 +74,197 @@ type GroupingExpr struct {
 
 func (e *GroupingExpr) expr() {}
 
+// LambdaExpr is a lambda literal, `{ (a, b) -> a + b }`. A lambda without
+// declared parameters has the implicit parameter `it` when it takes one.
+type LambdaExpr struct {
+	// Label is the label declared in front of the lambda, if any.
+	Label      token.Token
+	Open       token.Token
+	Parameters []*LambdaParameter
+	Body       []Stmt
+}
+
+func (e *LambdaExpr) expr() {}
+
+// LambdaParameter is a lambda parameter: a name with an optional type, or
+// a destructuring Pattern.
+type LambdaParameter struct {
+	Name    token.Token
+	Type    Type
+	Pattern *DestructuringPattern
+}
+
 type IdentifierExpr struct {
 	Value token.Token
+	// Constant is the value of the `const val` the name refers to, which
+	// the checker folds so that the reference can be inlined. No backend
+	// reads it yet: constants are only checked statically.
+	Constant Expr
+	// Binding is the declaration the name refers to, as the resolver finds
+	// it. It stays nil for names the resolver cannot bind, such as members
+	// of a receiver whose class it does not know.
+	Binding *Binding
 }
 
 func (e *IdentifierExpr) expr() {}
 
+// ThisExpr is `this`, or `this@Label` naming the enclosing class, extension
+// function or lambda whose receiver it refers to.
+type ThisExpr struct {
+	Token token.Token
+	Label token.Token
+}
+
+func (e *ThisExpr) expr() {}
+
+// MemberExpr is `Receiver.Name`, or `Receiver?.Name` when Safe. Members of
+// the receiver's class take precedence over extensions with the same name.
+type MemberExpr struct {
+	Receiver Expr
+	Name     token.Token
+	Safe     bool
+	// Constant is the value of the `const val` the member refers to, as for
+	// IdentifierExpr.
+	Constant Expr
+}
+
+func (e *MemberExpr) expr() {}
+
+// IsExpr is the type check `Expr is Type`, or `Expr !is Type`.
+type IsExpr struct {
+	Expr Expr
+	Op   token.Token
+	Type Type
+}
+
+func (e *IsExpr) expr() {}
+
 type NonNullableExpr struct {
 	Expr Expr
 }
 
 func (e *NonNullableExpr) expr() {}
 
+// CallExpr is `callee(args)`. Names holds the name each argument is passed
+// by, `f(x = 1)`, with an empty one for positional arguments; it may be
+// shorter than Args, and is nil when no argument is named.
 type CallExpr struct {
 	Callee Expr
 	Args   []Expr
+	Names  []token.Token
 }
 
 func (e *CallExpr) expr() {}
+
+// InfixCallExpr is a call to an `infix fun` written as `left Name right`.
+type InfixCallExpr struct {
+	Left  Expr
+	Name  token.Token
+	Right Expr
+}
+
+func (e *InfixCallExpr) expr() {}
+
+// ObjectExpr is an anonymous object, `object : Listener { ... }`.
+type ObjectExpr struct {
+	Object     token.Token
+	SuperTypes []*SuperType
+	Members    []Stmt
+}
+
+func (e *ObjectExpr) expr() {}
+
+// IfExpr is `if (Condition) Then else Else`. Then and Else are a BlockStmt,
+// an AssignStmt or an ExprStmt. Else is nil when there is no else branch.
+type IfExpr struct {
+	If        token.Token
+	Condition Expr
+	Then      Stmt
+	Else      Stmt
+}
+
+func (e *IfExpr) expr() {}
+
+// WhenExpr is `when (Subject) { ... }`. Without a subject, every condition is
+// a Boolean expression.
+type WhenExpr struct {
+	When     token.Token
+	Subject  Expr
+	Branches []*WhenBranch
+}
+
+func (e *WhenExpr) expr() {}
+
+// WhenBranch is `conditions -> Body`, or `else -> Body`. Body is a
+// BlockStmt, an AssignStmt or an ExprStmt.
+type WhenBranch struct {
+	Conditions []*WhenCondition
+	Else       bool
+	Body       Stmt
+}
+
+// WhenCondition matches the subject against Expr: by equality when Op is
+// empty, or by containment when Op is `in` or `!in`. With Op `is` or `!is`
+// the subject is matched against Type instead.
+type WhenCondition struct {
+	Op   token.Token
+	Expr Expr
+	Type Type
+}
+
+// ThrowExpr raises Expr, which must be a Throwable. Its type is Nothing.
+type ThrowExpr struct {
+	Throw token.Token
+	Expr  Expr
+}
+
+func (e *ThrowExpr) expr() {}
+
+// TryExpr runs Body and hands a thrown exception to the first catch clause
+// whose type matches. Used as an expression its value is the last
+// expression of the try block or of the catch block that ran; the finally
+// block runs in every case and never contributes the value.
+type TryExpr struct {
+	Try     token.Token
+	Body    *BlockStmt
+	Catches []*CatchClause
+	Finally *BlockStmt
+}
+
+func (e *TryExpr) expr() {}
+
+// CatchClause is `catch (Name: Type) { Body }`.
+type CatchClause struct {
+	Catch token.Token
+	Name  token.Token
+	Type  Type
+	Body  *BlockStmt
+}
+
+// AnnotatedExpr is an expression preceded by annotations,
+// `@Suppress("UNCHECKED_CAST") value`.
+type AnnotatedExpr struct {
+	Annotations Annotations
+	Expr        Expr
+}
+
+func (e *AnnotatedExpr) expr() {}
+
+// ReturnExpr is `return`, `return value` or `return@label value`. Without a
+// label it returns from the innermost enclosing function.
+type ReturnExpr struct {
+	Return token.Token
+	Label  token.Token
+	Value  Expr
+}
+
+func (e *ReturnExpr) expr() {}
+
+// JumpExpr is `break` or `continue`, optionally naming the loop it applies
+// to, `break@outer`.
+type JumpExpr struct {
+	Jump  token.Token
+	Label token.Token
+}
+
+func (e *JumpExpr) expr() {}
@@ -0,0 +1,56 @@
+package ast
+
+import (
+	"gotlin/frontend/token"
+)
+
+// Modifiers are the soft keywords written in front of a declaration, such as
+// `operator`. The tokens are kept so diagnostics can point at them.
+type Modifiers []token.Token
+
+func (m Modifiers) Has(name string) bool {
+	_, ok := m.Find(name)
+	return ok
+}
+
+func (m Modifiers) Find(name string) (token.Token, bool) {
+	for _, modifier := range m {
+		if modifier.Spelling == name {
+			return modifier, true
+		}
+	}
+	return token.Token{}, false
+}
+
+// Visibility modifiers restrict where a declaration can be used from.
+const (
+	Public    = "public"
+	Private   = "private"
+	Protected = "protected"
+	Internal  = "internal"
+)
+
+// Visibility returns the visibility modifier of a declaration. Declarations
+// without one are public.
+func (m Modifiers) Visibility() (token.Token, bool) {
+	for _, modifier := range m {
+		if IsVisibility(modifier.Spelling) {
+			return modifier, true
+		}
+	}
+	return token.Token{}, false
+}
+
+// IsPrivate reports whether the declaration is private to its file or class.
+func (m Modifiers) IsPrivate() bool {
+	visibility, _ := m.Visibility()
+	return visibility.Spelling == Private
+}
+
+func IsVisibility(modifier string) bool {
+	switch modifier {
+	case Public, Private, Protected, Internal:
+		return true
+	}
+	return false
+}
@@ -28,7 +28,13 @@ type Visitor interface {
 	VisitVariable(identifier *IdentifierExpr) object.Object
 }
 
+// Program is a single source file. Package is nil for files in the root
+// package, and File, the path diagnostics name the file by, is empty for
+// programs that were not loaded from a file.
 type Program struct {
+	File       string
+	Package    *PackageHeader
+	Imports    []*ImportDirective
 	Statements []Stmt
 }
 
@@ -0,0 +1,94 @@
+package ast
+
+import (
+	"gotlin/frontend/token"
+)
+
+// Position returns where expr starts, which diagnostics about the whole
+// expression point at.
+func Position(expr Expr) token.Pos {
+	switch e := expr.(type) {
+	case *BinaryExpr:
+		return Position(e.Left)
+	case *UnaryExpr:
+		return e.Op.Position
+	case *IntLiteral:
+		return e.Position
+	case *DoubleLiteral:
+		return e.Position
+	case *BoolLiteral:
+		return e.Position
+	case *StringLiteral:
+		return e.Position
+	case *NullLiteral:
+		return e.Position
+	case *FunctionLiteral:
+		return e.Fun.Position
+	case *GroupingExpr:
+		return Position(e.Expr)
+	case *LambdaExpr:
+		return e.Open.Position
+	case *IdentifierExpr:
+		return e.Value.Position
+	case *ThisExpr:
+		return e.Token.Position
+	case *MemberExpr:
+		return Position(e.Receiver)
+	case *IsExpr:
+		return Position(e.Expr)
+	case *NonNullableExpr:
+		return Position(e.Expr)
+	case *CallExpr:
+		return Position(e.Callee)
+	case *InfixCallExpr:
+		return Position(e.Left)
+	case *ObjectExpr:
+		return e.Object.Position
+	case *IfExpr:
+		return e.If.Position
+	case *WhenExpr:
+		return e.When.Position
+	case *ThrowExpr:
+		return e.Throw.Position
+	case *TryExpr:
+		return e.Try.Position
+	case *AnnotatedExpr:
+		return Position(e.Expr)
+	case *ReturnExpr:
+		return e.Return.Position
+	case *JumpExpr:
+		return e.Jump.Position
+	}
+	return token.Pos{}
+}
+
+// StmtPosition returns where stmt starts, or the name it declares.
+func StmtPosition(stmt Stmt) token.Pos {
+	switch s := stmt.(type) {
+	case *ExprStmt:
+		return Position(s.Expr)
+	case *AssignStmt:
+		return Position(s.Assigne)
+	case *BlockStmt:
+		if len(s.Statements) > 0 {
+			return StmtPosition(s.Statements[0])
+		}
+	case *VariableDecl:
+		return s.Name.Position
+	case *DestructuringDecl:
+		return s.Pattern.Open.Position
+	case *FunctionDecl:
+		return s.Name.Position
+	case *ClassDeclStmt:
+		return s.Name.Position
+	case *ObjectDeclStmt:
+		return s.Object.Position
+	case *TypeAliasDecl:
+		return s.Name.Position
+	case *ForStmt:
+		return s.For.Position
+	case *WhileStmt:
+		return s.While.Position
+	}
+	return token.Pos{}
+}
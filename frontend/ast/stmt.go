@@ -20,15 +20,62 @@ type ExprStmt struct {
 
 func (s *ExprStmt) stmt() {}
 
+// VariableDecl declares a variable or a property. Extension properties have
+// a Receiver and compute their value with a Getter instead of storing it.
+// `lateinit` and `const` are among the Modifiers. A delegated property hands
+// its reads and writes to the getValue and setValue operators of Delegate.
 type VariableDecl struct {
-	Name     token.Token
-	Type     Type
-	Value    Expr
-	ReadOnly bool
+	Annotations Annotations
+	Modifiers   Modifiers
+	Receiver    Type
+	Name        token.Token
+	Type        Type
+	Value       Expr
+	Delegate    Expr
+	ReadOnly    bool
+	Getter      *PropertyAccessor
+	Setter      *PropertyAccessor
 }
 
 func (s *VariableDecl) stmt() {}
 
+// FunctionDecl declares a named function. Extension functions have a
+// Receiver, which `this` refers to inside the body.
+type FunctionDecl struct {
+	Annotations Annotations
+	Modifiers   Modifiers
+	Receiver    Type
+	Name        token.Token
+	Parameters  []*ParameterWithOptionalType
+	Type        Type
+	Body        *FunctionBody
+}
+
+func (s *FunctionDecl) stmt() {}
+
+// ForStmt is `for (Variable in Iterable) Body`. The loop variable is
+// either a name or a destructuring Pattern, `for ((k, v) in map)`.
+type ForStmt struct {
+	Label    token.Token
+	For      token.Token
+	Variable token.Token
+	Pattern  *DestructuringPattern
+	Iterable Expr
+	Body     Stmt
+}
+
+func (s *ForStmt) stmt() {}
+
+// WhileStmt is `while (Condition) Body`.
+type WhileStmt struct {
+	Label     token.Token
+	While     token.Token
+	Condition Expr
+	Body      Stmt
+}
+
+func (s *WhileStmt) stmt() {}
+
 type AssignStmt struct {
 	Assigne Expr
 	Value   Expr
@@ -40,17 +87,78 @@ type ClassPrimaryConstructor struct {
 	Parameters []ClassParam
 }
 
+// ClassParam is a primary constructor parameter. Parameters declared with
+// `val` or `var` are also properties of the class.
 type ClassParam struct {
+	Annotations  Annotations
+	Modifiers    Modifiers
 	Name         string
+	Position     token.Pos
 	Type         Type
 	DefaultValue Expr
+	Vararg       bool
 	ReadOnly     bool
+	Property     bool
 }
 
 type ClassDeclStmt struct {
+	Annotations        Annotations
+	Modifiers          Modifiers
 	Name               token.Token
+	Interface          bool
 	PrimaryConstructor *ClassPrimaryConstructor
 	Params             []ClassParam
+	SuperTypes         []*SuperType
+	Entries            []*EnumEntry
+	Members            []Stmt
 }
 
 func (t *ClassDeclStmt) stmt() {}
+
+// TypeAliasDecl gives Type another name, `typealias StringMap<V> =
+// Map<String, V>`. TypeParams are replaced by the arguments the alias is
+// used with.
+type TypeAliasDecl struct {
+	Annotations Annotations
+	Modifiers   Modifiers
+	Name        token.Token
+	TypeParams  []token.Token
+	Type        Type
+}
+
+func (t *TypeAliasDecl) stmt() {}
+
+// EnumEntry is an entry of an enum class, `RED(0xFF0000) { ... }`. Members
+// holds the entry's own body, if it declares one. Args and Names are as in
+// a CallExpr.
+type EnumEntry struct {
+	Annotations Annotations
+	Name        token.Token
+	Args        []Expr
+	Names       []token.Token
+	Members     []Stmt
+}
+
+// ObjectDeclStmt declares a singleton, `object Name { ... }`. The name of a
+// companion object is optional and defaults to CompanionName.
+type ObjectDeclStmt struct {
+	Annotations Annotations
+	Modifiers   Modifiers
+	Object      token.Token
+	Name        token.Token
+	SuperTypes  []*SuperType
+	Members     []Stmt
+}
+
+func (t *ObjectDeclStmt) stmt() {}
+
+const CompanionName = "Companion"
+
+// ObjectName returns the declared name, falling back to CompanionName for
+// an unnamed companion object.
+func (t *ObjectDeclStmt) ObjectName() string {
+	if t.Name.Spelling == "" {
+		return CompanionName
+	}
+	return t.Name.Spelling
+}
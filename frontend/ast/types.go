@@ -1,5 +1,9 @@
 package ast
 
+import (
+	"gotlin/frontend/token"
+)
+
 type Type interface {
 	tp()
 }
@@ -10,8 +14,12 @@ type NullableType struct {
 
 func (nt *NullableType) tp() {}
 
+// TypeName names a class, interface or type alias. Args are its type
+// arguments, `Map<String, V>`.
 type TypeName struct {
-	Name string
+	Name     string
+	Args     []Type
+	Position token.Pos
 }
 
 func (id *TypeName) tp() {}
@@ -21,3 +29,12 @@ type ArrayType struct {
 }
 
 func (id *ArrayType) tp() {}
+
+// FunctionType is the type of functions taking Params and returning
+// Return, `(Request) -> Response`.
+type FunctionType struct {
+	Params []Type
+	Return Type
+}
+
+func (ft *FunctionType) tp() {}
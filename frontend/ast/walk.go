@@ -0,0 +1,160 @@
+package ast
+
+// Inspect traverses the statements and expressions below node in source
+// order, calling f for each of them before their children. When f returns
+// false the children of that node are skipped. Types and annotations are not
+// visited.
+func Inspect(node any, f func(node any) bool) {
+	if node == nil || !f(node) {
+		return
+	}
+
+	switch n := node.(type) {
+	case *Program:
+		inspectStmts(n.Statements, f)
+	case *BlockStmt:
+		inspectStmts(n.Statements, f)
+	case *ExprStmt:
+		inspectExpr(n.Expr, f)
+	case *VariableDecl:
+		inspectExpr(n.Value, f)
+		inspectExpr(n.Delegate, f)
+		inspectAccessor(n.Getter, f)
+		inspectAccessor(n.Setter, f)
+	case *FunctionDecl:
+		inspectParameters(n.Parameters, f)
+		inspectBody(n.Body, f)
+	case *DestructuringDecl:
+		inspectExpr(n.Value, f)
+	case *ForStmt:
+		inspectExpr(n.Iterable, f)
+		inspectStmt(n.Body, f)
+	case *WhileStmt:
+		inspectExpr(n.Condition, f)
+		inspectStmt(n.Body, f)
+	case *AssignStmt:
+		inspectExpr(n.Assigne, f)
+		inspectExpr(n.Value, f)
+	case *ClassDeclStmt:
+		if n.PrimaryConstructor != nil {
+			for _, param := range n.PrimaryConstructor.Parameters {
+				inspectExpr(param.DefaultValue, f)
+			}
+		}
+		inspectSuperTypes(n.SuperTypes, f)
+		for _, entry := range n.Entries {
+			inspectExprs(entry.Args, f)
+			inspectStmts(entry.Members, f)
+		}
+		inspectStmts(n.Members, f)
+	case *ObjectDeclStmt:
+		inspectSuperTypes(n.SuperTypes, f)
+		inspectStmts(n.Members, f)
+	case *BinaryExpr:
+		inspectExpr(n.Left, f)
+		inspectExpr(n.Right, f)
+	case *UnaryExpr:
+		inspectExpr(n.Right, f)
+	case *GroupingExpr:
+		inspectExpr(n.Expr, f)
+	case *FunctionLiteral:
+		inspectParameters(n.Parameters, f)
+		inspectBody(n.Body, f)
+	case *LambdaExpr:
+		inspectStmts(n.Body, f)
+	case *MemberExpr:
+		inspectExpr(n.Receiver, f)
+	case *IsExpr:
+		inspectExpr(n.Expr, f)
+	case *NonNullableExpr:
+		inspectExpr(n.Expr, f)
+	case *CallExpr:
+		inspectExpr(n.Callee, f)
+		inspectExprs(n.Args, f)
+	case *InfixCallExpr:
+		inspectExpr(n.Left, f)
+		inspectExpr(n.Right, f)
+	case *ObjectExpr:
+		inspectSuperTypes(n.SuperTypes, f)
+		inspectStmts(n.Members, f)
+	case *IfExpr:
+		inspectExpr(n.Condition, f)
+		inspectStmt(n.Then, f)
+		inspectStmt(n.Else, f)
+	case *WhenExpr:
+		inspectExpr(n.Subject, f)
+		for _, branch := range n.Branches {
+			for _, condition := range branch.Conditions {
+				inspectExpr(condition.Expr, f)
+			}
+			inspectStmt(branch.Body, f)
+		}
+	case *ThrowExpr:
+		inspectExpr(n.Expr, f)
+	case *TryExpr:
+		Inspect(n.Body, f)
+		for _, catch := range n.Catches {
+			Inspect(catch.Body, f)
+		}
+		if n.Finally != nil {
+			Inspect(n.Finally, f)
+		}
+	case *AnnotatedExpr:
+		inspectExpr(n.Expr, f)
+	case *ReturnExpr:
+		inspectExpr(n.Value, f)
+	}
+}
+
+// inspectStmt and inspectExpr skip nil interfaces, which would otherwise
+// reach f as typed nils.
+func inspectStmt(stmt Stmt, f func(node any) bool) {
+	if stmt != nil {
+		Inspect(stmt, f)
+	}
+}
+
+func inspectExpr(expr Expr, f func(node any) bool) {
+	if expr != nil {
+		Inspect(expr, f)
+	}
+}
+
+func inspectStmts(stmts []Stmt, f func(node any) bool) {
+	for _, stmt := range stmts {
+		inspectStmt(stmt, f)
+	}
+}
+
+func inspectExprs(exprs []Expr, f func(node any) bool) {
+	for _, expr := range exprs {
+		inspectExpr(expr, f)
+	}
+}
+
+func inspectBody(body *FunctionBody, f func(node any) bool) {
+	if body == nil {
+		return
+	}
+	inspectExpr(body.Expr, f)
+	inspectStmts(body.Block, f)
+}
+
+func inspectParameters(params []*ParameterWithOptionalType, f func(node any) bool) {
+	for _, param := range params {
+		inspectExpr(param.DefaultValue, f)
+	}
+}
+
+func inspectAccessor(accessor *PropertyAccessor, f func(node any) bool) {
+	if accessor != nil {
+		inspectBody(accessor.Body, f)
+	}
+}
+
+func inspectSuperTypes(superTypes []*SuperType, f func(node any) bool) {
+	for _, superType := range superTypes {
+		inspectExprs(superType.Args, f)
+		inspectExpr(superType.Delegate, f)
+	}
+}
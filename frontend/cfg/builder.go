@@ -0,0 +1,378 @@
+package cfg
+
+import (
+	"gotlin/frontend/ast"
+	"gotlin/frontend/token"
+)
+
+type builder struct {
+	graph *Graph
+	info  Info
+	// current is the block code is added to, nil after a jump until code
+	// that cannot be reached starts a block of its own.
+	current *Block
+	// loops holds the loops around the code, innermost last.
+	loops []*loop
+}
+
+// loop is where the break and continue of a loop jump to.
+type loop struct {
+	label string
+	next  *Block
+	done  *Block
+}
+
+// New builds the graph of a function body. info may be nil, in which case
+// every call is assumed to return and every when without an else branch to
+// be able to take none.
+func New(body []ast.Stmt, info Info) *Graph {
+	b := &builder{graph: &Graph{}, info: info}
+	b.graph.Entry = b.newBlock(KindEntry)
+	b.graph.Entry.Live = true
+	b.current = b.graph.Entry
+	b.stmts(body)
+	b.graph.Exit = b.newBlock(KindExit)
+	b.jump(b.graph.Exit)
+	return b.graph
+}
+
+func (b *builder) newBlock(kind Kind) *Block {
+	block := &Block{Kind: kind, Index: len(b.graph.Blocks)}
+	b.graph.Blocks = append(b.graph.Blocks, block)
+	return block
+}
+
+// link adds an edge from one block to another, which the first makes live.
+// A nil block is code that cannot be reached, which links nowhere.
+func (b *builder) link(from *Block, to *Block) {
+	if from == nil {
+		return
+	}
+	from.Succs = append(from.Succs, to)
+	to.Preds = append(to.Preds, from)
+	if from.Live {
+		markLive(to)
+	}
+}
+
+func markLive(block *Block) {
+	if block.Live {
+		return
+	}
+	block.Live = true
+	for _, succ := range block.Succs {
+		markLive(succ)
+	}
+}
+
+// branch starts a block run after the current one.
+func (b *builder) branch(kind Kind) *Block {
+	block := b.newBlock(kind)
+	b.link(b.current, block)
+	b.current = block
+	return block
+}
+
+// jump ends the current block with a jump to target, or with a return or
+// throw when target is nil.
+func (b *builder) jump(target *Block) {
+	if target != nil {
+		b.link(b.current, target)
+	}
+	b.current = nil
+}
+
+// add appends node to the current block.
+func (b *builder) add(node any) {
+	if b.current == nil {
+		b.current = b.newBlock(KindUnreachable)
+	}
+	b.current.Nodes = append(b.current.Nodes, node)
+}
+
+// cond ends the current block with a condition, which the successors
+// linked next follow from.
+func (b *builder) cond(condition ast.Expr) {
+	b.add(condition)
+	b.current.Cond = condition
+}
+
+func (b *builder) stmts(stmts []ast.Stmt) {
+	for _, stmt := range stmts {
+		b.stmt(stmt)
+	}
+}
+
+func (b *builder) stmt(stmt ast.Stmt) {
+	if block, ok := stmt.(*ast.BlockStmt); ok {
+		b.stmts(block.Statements)
+		return
+	}
+	b.add(stmt)
+	switch s := stmt.(type) {
+	case *ast.ExprStmt:
+		b.expr(s.Expr)
+	case *ast.VariableDecl:
+		b.expr(s.Value)
+		b.expr(s.Delegate)
+	case *ast.DestructuringDecl:
+		b.expr(s.Value)
+	case *ast.AssignStmt:
+		b.expr(s.Assigne)
+		b.expr(s.Value)
+	case *ast.WhileStmt:
+		b.whileStmt(s)
+	case *ast.ForStmt:
+		b.forStmt(s)
+	}
+}
+
+func (b *builder) whileStmt(s *ast.WhileStmt) {
+	head := b.branch(KindWhileLoop)
+	b.expr(s.Condition)
+	b.cond(s.Condition)
+	test := b.current
+	body := b.newBlock(KindWhileBody)
+	b.link(test, body)
+	done := b.newBlock(KindWhileDone)
+	// `while (true)` only ends with a break.
+	if !IsTrue(s.Condition) {
+		b.link(test, done)
+	}
+	b.current = body
+	b.loop(s.Label, s.Body, head, done)
+}
+
+func (b *builder) forStmt(s *ast.ForStmt) {
+	b.expr(s.Iterable)
+	head := b.branch(KindForLoop)
+	body := b.newBlock(KindForBody)
+	b.link(head, body)
+	done := b.newBlock(KindForDone)
+	b.link(head, done)
+	b.current = body
+	b.loop(s.Label, s.Body, head, done)
+}
+
+// loop adds the body of a loop, which starts over at next and ends at done.
+func (b *builder) loop(label token.Token, body ast.Stmt, next *Block, done *Block) {
+	b.loops = append(b.loops, &loop{label: label.Spelling, next: next, done: done})
+	b.stmt(body)
+	b.jump(next)
+	b.loops = b.loops[:len(b.loops)-1]
+	b.current = done
+}
+
+// IsTrue reports whether a condition is the literal `true`, which a loop
+// only leaves with a break.
+func IsTrue(condition ast.Expr) bool {
+	switch c := condition.(type) {
+	case *ast.GroupingExpr:
+		return IsTrue(c.Expr)
+	case *ast.BoolLiteral:
+		return c.Value
+	}
+	return false
+}
+
+func (b *builder) expr(expr ast.Expr) {
+	switch e := expr.(type) {
+	case *ast.GroupingExpr:
+		b.expr(e.Expr)
+	case *ast.AnnotatedExpr:
+		b.expr(e.Expr)
+	case *ast.UnaryExpr:
+		b.expr(e.Right)
+	case *ast.IsExpr:
+		b.expr(e.Expr)
+	case *ast.NonNullableExpr:
+		b.expr(e.Expr)
+	case *ast.MemberExpr:
+		b.expr(e.Receiver)
+	case *ast.BinaryExpr:
+		b.expr(e.Left)
+		switch e.Op.Kind {
+		case token.AND, token.OR, token.ELVIS:
+			b.operator(e)
+		default:
+			b.expr(e.Right)
+		}
+	case *ast.CallExpr:
+		b.expr(e.Callee)
+		for _, arg := range e.Args {
+			b.expr(arg)
+		}
+		b.call(e)
+	case *ast.InfixCallExpr:
+		b.expr(e.Left)
+		b.expr(e.Right)
+		b.call(e)
+	case *ast.IfExpr:
+		b.ifExpr(e)
+	case *ast.WhenExpr:
+		b.when(e)
+	case *ast.TryExpr:
+		b.tryExpr(e)
+	case *ast.ReturnExpr:
+		b.expr(e.Value)
+		b.add(e)
+		b.jump(nil)
+	case *ast.ThrowExpr:
+		b.expr(e.Expr)
+		b.add(e)
+		b.jump(nil)
+	case *ast.JumpExpr:
+		b.add(e)
+		b.jump(b.target(e))
+	}
+}
+
+// operator adds the right operand of `&&`, `||` or `?:`, which runs when
+// the left one, already added, does not decide the value.
+func (b *builder) operator(e *ast.BinaryExpr) {
+	left := b.current
+	if e.Op.Kind != token.ELVIS {
+		b.cond(e.Left)
+		left = b.current
+	}
+	operand := b.newBlock(KindOperand)
+	done := b.newBlock(KindOperatorDone)
+	if e.Op.Kind == token.OR {
+		b.link(left, done)
+		b.link(left, operand)
+	} else {
+		b.link(left, operand)
+		b.link(left, done)
+	}
+	b.current = operand
+	b.expr(e.Right)
+	b.jump(done)
+	b.current = done
+}
+
+// call ends the current block after a call that never returns.
+func (b *builder) call(call ast.Expr) {
+	if b.info != nil && b.info.NoReturn(call) {
+		b.add(call)
+		b.jump(nil)
+	}
+}
+
+// target returns where a break or continue jumps to, nil if it has no
+// loop to leave.
+func (b *builder) target(jump *ast.JumpExpr) *Block {
+	for i := len(b.loops) - 1; i >= 0; i-- {
+		l := b.loops[i]
+		if jump.Label.Spelling != "" && l.label != jump.Label.Spelling {
+			continue
+		}
+		if jump.Jump.Kind == token.CONTINUE {
+			return l.next
+		}
+		return l.done
+	}
+	return nil
+}
+
+func (b *builder) ifExpr(e *ast.IfExpr) {
+	b.expr(e.Condition)
+	b.cond(e.Condition)
+	test := b.current
+	b.branch(KindIfThen)
+	b.stmt(e.Then)
+	then := b.current
+	b.current = test
+	b.branch(KindIfElse)
+	if e.Else != nil {
+		b.stmt(e.Else)
+	}
+	otherwise := b.current
+	done := b.newBlock(KindIfDone)
+	b.link(then, done)
+	b.link(otherwise, done)
+	b.current = done
+}
+
+// when adds a when, which tests the conditions of its branches in turn
+// and runs the body of the first that matches.
+func (b *builder) when(e *ast.WhenExpr) {
+	b.expr(e.Subject)
+	exhaustive := b.info != nil && e.Subject != nil && b.info.Exhaustive(e)
+	var ends []*Block
+	for _, branch := range e.Branches {
+		if branch.Else {
+			exhaustive = true
+			b.branch(KindWhenBranch)
+			b.stmt(branch.Body)
+			ends = append(ends, b.current)
+			break
+		}
+		for _, condition := range branch.Conditions {
+			b.expr(condition.Expr)
+		}
+		if e.Subject == nil && len(branch.Conditions) == 1 {
+			b.cond(branch.Conditions[0].Expr)
+		}
+		test := b.current
+		b.branch(KindWhenBranch)
+		b.stmt(branch.Body)
+		ends = append(ends, b.current)
+		b.current = test
+		b.branch(KindWhenNext)
+	}
+	if !exhaustive {
+		ends = append(ends, b.current)
+	}
+	done := b.newBlock(KindWhenDone)
+	for _, end := range ends {
+		b.link(end, done)
+	}
+	b.current = done
+}
+
+// tryExpr adds a try. Any block of the try body may throw to a catch
+// clause, and any block of the body or a catch clause to the finally
+// block, after which the try only goes on if it completed.
+func (b *builder) tryExpr(e *ast.TryExpr) {
+	first := len(b.graph.Blocks)
+	b.branch(KindTryBody)
+	b.stmts(e.Body.Statements)
+	body := append([]*Block(nil), b.graph.Blocks[first:]...)
+	ends := []*Block{b.current}
+
+	for _, catch := range e.Catches {
+		clause := b.newBlock(KindCatch)
+		for _, block := range body {
+			b.link(block, clause)
+		}
+		b.current = clause
+		b.stmts(catch.Body.Statements)
+		ends = append(ends, b.current)
+	}
+
+	if e.Finally != nil {
+		// The ends of the body and catch clauses are among the blocks that
+		// may throw.
+		throwing := b.graph.Blocks[first:]
+		finally := b.newBlock(KindFinally)
+		for _, block := range throwing {
+			b.link(block, finally)
+		}
+		completes := false
+		for _, end := range ends {
+			completes = completes || end != nil && end.Live
+		}
+		b.current = finally
+		b.stmts(e.Finally.Statements)
+		ends = nil
+		if completes {
+			ends = append(ends, b.current)
+		}
+	}
+
+	done := b.newBlock(KindTryDone)
+	for _, end := range ends {
+		b.link(end, done)
+	}
+	b.current = done
+}
@@ -0,0 +1,128 @@
+// Package cfg builds the control-flow graph of a function body: the blocks
+// of code that run one after another without branching, and the jumps
+// between them. It works on the syntax alone, and learns what depends on
+// types, such as the calls that never return, from an Info the caller
+// provides.
+//
+// Lambdas, anonymous functions and local declarations are not part of the
+// graph of the body they appear in: they run at another time, and have
+// graphs of their own.
+package cfg
+
+import (
+	"gotlin/frontend/ast"
+)
+
+// Graph is the control-flow graph of a function body.
+type Graph struct {
+	// Blocks holds every block of the graph, Entry first.
+	Blocks []*Block
+	// Entry is where the body starts, and Exit where it ends when it runs
+	// to its end without returning or throwing.
+	Entry *Block
+	Exit  *Block
+}
+
+// Block is a sequence of code run without branching.
+type Block struct {
+	Kind Kind
+	// Index is the position of the block in the Blocks of its graph.
+	Index int
+	// Nodes holds the statements of the block in the order they start
+	// running, and among their expressions the conditions, jumps and calls
+	// that never return where they are evaluated. Each is an ast.Stmt or an
+	// ast.Expr.
+	Nodes []any
+	// Cond is the Boolean condition the block ends with, if any, in which
+	// case Succs holds the block run when it is true, then the one run when
+	// it is false.
+	Cond  ast.Expr
+	Succs []*Block
+	Preds []*Block
+	// Live reports whether the block can be reached from the entry.
+	Live bool
+}
+
+// Kind tells which part of the body a block comes from.
+type Kind int
+
+const (
+	KindEntry Kind = iota
+	KindExit
+	// KindUnreachable starts code that follows a jump.
+	KindUnreachable
+	KindIfThen
+	KindIfElse
+	KindIfDone
+	KindWhenBranch
+	KindWhenNext
+	KindWhenDone
+	KindWhileLoop
+	KindWhileBody
+	KindWhileDone
+	KindForLoop
+	KindForBody
+	KindForDone
+	KindTryBody
+	KindCatch
+	KindFinally
+	KindTryDone
+	// KindOperand is the right operand of `&&`, `||` or `?:`, which only
+	// runs depending on the left one, and KindOperatorDone the code after.
+	KindOperand
+	KindOperatorDone
+)
+
+var kindNames = [...]string{
+	KindEntry:        "entry",
+	KindExit:         "exit",
+	KindUnreachable:  "unreachable",
+	KindIfThen:       "if.then",
+	KindIfElse:       "if.else",
+	KindIfDone:       "if.done",
+	KindWhenBranch:   "when.branch",
+	KindWhenNext:     "when.next",
+	KindWhenDone:     "when.done",
+	KindWhileLoop:    "while.loop",
+	KindWhileBody:    "while.body",
+	KindWhileDone:    "while.done",
+	KindForLoop:      "for.loop",
+	KindForBody:      "for.body",
+	KindForDone:      "for.done",
+	KindTryBody:      "try.body",
+	KindCatch:        "catch",
+	KindFinally:      "finally",
+	KindTryDone:      "try.done",
+	KindOperand:      "operand",
+	KindOperatorDone: "operator.done",
+}
+
+func (k Kind) String() string {
+	if k < 0 || int(k) >= len(kindNames) {
+		return "unknown"
+	}
+	return kindNames[k]
+}
+
+// Info tells the builder what the syntax of a body does not.
+type Info interface {
+	// NoReturn reports whether a call never completes, as one to a
+	// function whose result type is Nothing.
+	NoReturn(call ast.Expr) bool
+	// Exhaustive reports whether a when without an else branch covers
+	// every value of its subject, as one over the entries of an enum.
+	Exhaustive(when *ast.WhenExpr) bool
+}
+
+// Unreachable returns the first node of each part of the body that cannot
+// be reached, in the order they appear.
+func (g *Graph) Unreachable() []any {
+	var nodes []any
+	for _, block := range g.Blocks {
+		// Unreachable code starts in a block no code jumps to.
+		if !block.Live && len(block.Preds) == 0 && len(block.Nodes) > 0 {
+			nodes = append(nodes, block.Nodes[0])
+		}
+	}
+	return nodes
+}
@@ -0,0 +1,109 @@
+package cfg
+
+import (
+	"strings"
+	"testing"
+
+	"gotlin/frontend/ast"
+	"gotlin/frontend/parser"
+	"gotlin/frontend/scanner"
+)
+
+// body parses the statements of a function body.
+func body(t *testing.T, input string) []ast.Stmt {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Fatalf("%q: %v", input, r)
+		}
+	}()
+	program := parser.New(scanner.NewScanner(strings.NewReader("fun f() {\n" + input + "\n}"))).Parse()
+	return program.Statements[0].(*ast.FunctionDecl).Body.Block
+}
+
+// failing knows that fail() never returns and that a when over color
+// covers every color.
+type failing struct{}
+
+func (failing) NoReturn(call ast.Expr) bool {
+	c, ok := call.(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	id, ok := c.Callee.(*ast.IdentifierExpr)
+	return ok && id.Value.Spelling == "fail"
+}
+
+func (failing) Exhaustive(when *ast.WhenExpr) bool {
+	id, ok := when.Subject.(*ast.IdentifierExpr)
+	return ok && id.Value.Spelling == "color"
+}
+
+func TestGraph(t *testing.T) {
+	tests := []struct {
+		input string
+		// completes reports whether the body can run to its end, and
+		// unreachable counts the parts of it that cannot run.
+		completes   bool
+		unreachable int
+	}{
+		{"val x = 1\nlog(x)", true, 0},
+		{"return 1\nlog(1)", false, 1},
+		{"throw Exception()\nlog(1)\nlog(2)", false, 1},
+		{"if (c) return 1", true, 0},
+		{"if (c) return 1 else return 2", false, 0},
+		{"if (c) return 1 else return 2\nlog(1)", false, 1},
+		{"val x = if (c) 1 else return 2\nlog(x)", true, 0},
+		{"while (true) { log(1) }", false, 0},
+		{"while (true) { log(1) }\nlog(2)", false, 1},
+		{"while (true) { if (c) break }", true, 0},
+		{"while (c) { continue\nlog(1) }", true, 1},
+		{"outer@ while (true) { for (x in xs) { break@outer } }", true, 0},
+		{"for (x in xs) { return 1 }", true, 0},
+		{"when { c -> return 1\nelse -> return 2 }", false, 0},
+		{"when { c -> return 1\nd -> return 2 }", true, 0},
+		{"when (color) { RED -> return 1\nGREEN -> return 2 }", false, 0},
+		{"try { return 1 } catch (e: Exception) { return 2 }", false, 0},
+		{"try { return 1 } catch (e: Exception) { log(e) }", true, 0},
+		{"try { return 1 } finally { log(1) }\nlog(2)", false, 1},
+		{"try { log(1) } finally { log(2) }", true, 0},
+		{"fail()\nlog(1)", false, 1},
+		{"val x = y ?: return 1\nlog(x)", true, 0},
+		{"val x = c && fail()\nlog(x)", true, 0},
+		{"log(return 1)\nlog(2)", false, 1},
+		{"val f = { return@f }\nlog(f)", true, 0},
+	}
+
+	for _, test := range tests {
+		graph := New(body(t, test.input), failing{})
+		if graph.Exit.Live != test.completes {
+			t.Errorf("%q: exit live is %t, want %t", test.input, graph.Exit.Live, test.completes)
+		}
+		if got := graph.Unreachable(); len(got) != test.unreachable {
+			t.Errorf("%q: unreachable code at %v, want %d parts", test.input, got, test.unreachable)
+		}
+	}
+}
+
+func TestGraph_Blocks(t *testing.T) {
+	graph := New(body(t, "if (c) log(1) else log(2)\nlog(3)"), nil)
+	entry := graph.Entry
+	if entry.Cond == nil || len(entry.Succs) != 2 {
+		t.Fatalf("entry ends with %v and %d successors, want the condition and 2", entry.Cond, len(entry.Succs))
+	}
+	then, otherwise := entry.Succs[0], entry.Succs[1]
+	if then.Kind != KindIfThen || otherwise.Kind != KindIfElse {
+		t.Errorf("entry leads to %s and %s, want %s and %s", then.Kind, otherwise.Kind, KindIfThen, KindIfElse)
+	}
+	done := then.Succs[0]
+	if done.Kind != KindIfDone || otherwise.Succs[0] != done || len(done.Preds) != 2 {
+		t.Errorf("branches lead to %s, want both to reach %s", done.Kind, KindIfDone)
+	}
+	if len(done.Nodes) != 1 || done.Succs[0] != graph.Exit {
+		t.Errorf("%s holds %d nodes, want the last statement before the exit", done.Kind, len(done.Nodes))
+	}
+	for i, block := range graph.Blocks {
+		if block.Index != i || !block.Live {
+			t.Errorf("block %d (%s) has index %d and live %t", i, block.Kind, block.Index, block.Live)
+		}
+	}
+}
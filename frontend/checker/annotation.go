@@ -0,0 +1,35 @@
+package checker
+
+import (
+	"gotlin/frontend/ast"
+)
+
+func (c *Checker) checkAnnotations(annotations ast.Annotations) {
+	for _, annotation := range annotations {
+		for _, arg := range annotation.Args {
+			if !constant(arg) {
+				c.report(annotation.At.Position, "An annotation argument must be a compile-time constant")
+			}
+		}
+	}
+}
+
+// constant reports whether expr is known at compile time: a literal, an
+// operation on literals, or a reference to an enum entry or object.
+func constant(expr ast.Expr) bool {
+	switch e := expr.(type) {
+	case *ast.IntLiteral, *ast.DoubleLiteral, *ast.StringLiteral, *ast.BoolLiteral:
+		return true
+	case *ast.UnaryExpr:
+		return constant(e.Right)
+	case *ast.BinaryExpr:
+		return constant(e.Left) && constant(e.Right)
+	case *ast.GroupingExpr:
+		return constant(e.Expr)
+	case *ast.MemberExpr:
+		_, ok := qualifiedName(e)
+		return ok
+	default:
+		return false
+	}
+}
@@ -0,0 +1,424 @@
+package checker
+
+import (
+	"strings"
+
+	"gotlin/frontend/ast"
+	"gotlin/frontend/diag"
+	"gotlin/frontend/token"
+)
+
+// Checker runs the semantic checks that the grammar alone cannot express
+// and collects every violation instead of stopping at the first one.
+type Checker struct {
+	errors  []error
+	classes map[string]*classInfo
+	// declared lists the classes in declaration order, which diagnostics
+	// follow.
+	declared []*classInfo
+	aliases  map[string]*ast.TypeAliasDecl
+	// topLevel holds the top-level declarations of the package by name.
+	topLevel map[string][]topLevelDecl
+	// file is the index of the file being checked, and path its path,
+	// which diagnostics name.
+	file int
+	path string
+	// constants holds the `const val` declarations by qualified name, and
+	// constDecls the same by declaration.
+	constants  map[string]*constInfo
+	constDecls map[*ast.VariableDecl]*constInfo
+}
+
+// Kinds of declarations whose body holds the statements being checked.
+const (
+	ownerFile      = ""
+	ownerFunction  = "function"
+	ownerClass     = "class"
+	ownerInterface = "interface"
+	ownerObject    = "object"
+)
+
+// scope describes where the code being checked is declared.
+type scope struct {
+	// owner is the kind of declaration whose body directly holds the
+	// statements.
+	owner string
+	// hasThis reports whether `this` refers to a class instance or to the
+	// receiver of an extension.
+	hasThis bool
+	// jumps are the functions, lambdas and loops enclosing the statements,
+	// innermost last, which return, break and continue may target.
+	jumps []jumpTarget
+	// class is the qualified name of the innermost class, interface or
+	// object enclosing the statements, which decides what private and
+	// protected members they can access.
+	class string
+	// receivers are the labels `this@Label` can name: the enclosing classes
+	// whose instances are reachable, extension functions and lambdas,
+	// innermost last.
+	receivers []string
+	// inner reports whether the statements are the members of an inner or
+	// local class, whose instances hold on to those of their surroundings.
+	inner bool
+}
+
+// member reports whether the statements are members of a class, interface
+// or object.
+func (s scope) member() bool {
+	return s.owner == ownerClass || s.owner == ownerInterface || s.owner == ownerObject
+}
+
+// block is the scope of a block nested in the statements, such as a loop
+// body or a when branch.
+func (s scope) block() scope {
+	return scope{owner: ownerFunction, hasThis: s.hasThis, jumps: s.jumps, class: s.class, receivers: s.receivers}
+}
+
+// enter is the scope of the body of a lambda or loop labeled label.
+func (s scope) enter(kind string, label string) scope {
+	inner := s.block()
+	inner.jumps = append(append([]jumpTarget{}, s.jumps...), jumpTarget{kind: kind, label: label})
+	return inner
+}
+
+// function is the scope of the body of the function called name declared
+// in s. Jumps never cross into the code around a function.
+func (s scope) function(name string, hasThis bool) scope {
+	return scope{owner: ownerFunction, hasThis: hasThis, jumps: []jumpTarget{{kind: jumpFunction, label: name}}, class: s.class, receivers: s.receivers}
+}
+
+// members is the scope of the body of the class, interface or object
+// called name declared in s. Only its own instance is reachable from there.
+func (s scope) members(owner string, name string) scope {
+	return scope{owner: owner, hasThis: true, class: qualify(s.class, name), receivers: []string{name}}
+}
+
+// innerMembers is the scope of the body of the inner or local class called
+// name declared in s, from which the receivers of s remain reachable.
+func (s scope) innerMembers(owner string, name string) scope {
+	inner := s.members(owner, name)
+	inner.receivers = append(append([]string{}, s.receivers...), name)
+	inner.inner = true
+	return inner
+}
+
+// withReceiver is s where `this@label` refers to a receiver as well.
+func (s scope) withReceiver(label string) scope {
+	s.receivers = append(append([]string{}, s.receivers...), label)
+	return s
+}
+
+func New() *Checker {
+	return &Checker{}
+}
+
+func (c *Checker) Check(program *ast.Program) []error {
+	return c.CheckPackage([]*ast.Program{program})
+}
+
+// CheckPackage checks the files of a package together, so that each sees
+// the top-level declarations of the others.
+func (c *Checker) CheckPackage(programs []*ast.Program) []error {
+	c.errors = nil
+	c.classes = make(map[string]*classInfo)
+	c.declared = nil
+	c.aliases = make(map[string]*ast.TypeAliasDecl)
+	c.topLevel = make(map[string][]topLevelDecl)
+	c.constants = make(map[string]*constInfo)
+	c.constDecls = make(map[*ast.VariableDecl]*constInfo)
+	for i, program := range programs {
+		c.file, c.path = i, program.File
+		c.collectTopLevel(program.Statements)
+		c.collectConstants(program.Statements, "")
+		c.collectDeclarations(program.Statements, "")
+	}
+	for i, program := range programs {
+		c.file, c.path = i, program.File
+		c.checkStmts(program.Statements, scope{})
+	}
+	return c.errors
+}
+
+func (c *Checker) report(pos token.Pos, format string, args ...any) {
+	err := diag.Errorf(pos, format, args...)
+	err.File = c.path
+	c.errors = append(c.errors, err)
+}
+
+func (c *Checker) checkStmts(stmts []ast.Stmt, s scope) {
+	for _, stmt := range stmts {
+		c.checkStmt(stmt, s)
+	}
+}
+
+func (c *Checker) checkStmt(stmt ast.Stmt, s scope) {
+	switch st := stmt.(type) {
+	case *ast.FunctionDecl:
+		c.checkFunctionDecl(st, s)
+	case *ast.ClassDeclStmt:
+		c.checkClassDecl(st, s)
+	case *ast.ObjectDeclStmt:
+		c.checkObjectDecl(st, s)
+	case *ast.VariableDecl:
+		c.checkVariableDecl(st, s)
+	case *ast.TypeAliasDecl:
+		c.checkTypeAlias(st, s)
+	case *ast.DestructuringDecl:
+		c.checkDestructuringDecl(st, s)
+	case *ast.ForStmt:
+		c.checkFor(st, s)
+	case *ast.WhileStmt:
+		c.checkExpr(st.Condition, s)
+		c.checkStmt(st.Body, s.enter(jumpLoop, st.Label.Spelling))
+	case *ast.ExprStmt:
+		switch e := st.Expr.(type) {
+		case *ast.WhenExpr:
+			c.checkWhen(e, s)
+		case *ast.IfExpr:
+			c.checkIf(e, s, false)
+		default:
+			c.checkExpr(st.Expr, s)
+		}
+	case *ast.AssignStmt:
+		c.checkExpr(st.Assigne, s)
+		c.checkExpr(st.Value, s)
+	case *ast.BlockStmt:
+		c.checkStmts(st.Statements, s.block())
+	}
+}
+
+func (c *Checker) checkFunctionDecl(decl *ast.FunctionDecl, s scope) {
+	c.checkAnnotations(decl.Annotations)
+	c.checkName(decl.Name)
+	c.checkParameters(decl.Parameters)
+	c.checkType(decl.Receiver)
+	c.checkType(decl.Type)
+	c.checkModifiers(decl.Modifiers, targetFunction)
+	c.checkVisibility(decl.Modifiers, targetFunction, s)
+	if decl.Modifiers.Has(modifierOperator) {
+		c.checkOperatorFunction(decl, s)
+	}
+	if decl.Modifiers.Has(modifierInfix) {
+		c.checkInfixFunction(decl, s)
+	}
+
+	body := s.function(decl.Name.Spelling, s.hasThis || decl.Receiver != nil)
+	if decl.Receiver != nil {
+		body = body.withReceiver(decl.Name.Spelling)
+	}
+	c.checkFunctionBody(decl.Body, body)
+}
+
+func (c *Checker) checkFunctionBody(body *ast.FunctionBody, s scope) {
+	if body == nil {
+		return
+	}
+	if body.Expr != nil {
+		c.checkExpr(body.Expr, s)
+	}
+	c.checkStmts(body.Block, s)
+}
+
+func (c *Checker) checkClassDecl(decl *ast.ClassDeclStmt, s scope) {
+	owner := ownerClass
+	if decl.Interface {
+		owner = ownerInterface
+	}
+	c.checkAnnotations(decl.Annotations)
+	c.checkModifiers(decl.Modifiers, owner)
+	c.checkVisibility(decl.Modifiers, owner, s)
+
+	body := s.members(owner, decl.Name.Spelling)
+	if c.checkInner(decl, s) {
+		body = s.innerMembers(owner, decl.Name.Spelling)
+	}
+	if decl.PrimaryConstructor != nil {
+		for _, param := range decl.PrimaryConstructor.Parameters {
+			c.checkAnnotations(param.Annotations)
+			c.checkParamModifiers(param, body)
+			c.checkType(param.Type)
+			if param.DefaultValue != nil {
+				c.checkExpr(param.DefaultValue, scope{})
+			}
+		}
+	}
+	c.checkDataClass(decl)
+	c.checkValueClass(decl, s)
+	c.checkSuperTypes(decl.SuperTypes, scope{})
+	c.checkClassDelegation(decl, s)
+	c.checkEnumEntries(decl, body)
+	c.checkClassBody(decl.Members, body)
+}
+
+func (c *Checker) checkObjectDecl(decl *ast.ObjectDeclStmt, s scope) {
+	c.checkAnnotations(decl.Annotations)
+	c.checkModifiers(decl.Modifiers, ownerObject)
+	c.checkVisibility(decl.Modifiers, ownerObject, s)
+
+	companion, isCompanion := decl.Modifiers.Find(modifierCompanion)
+	switch {
+	case isCompanion && s.owner != ownerClass && s.owner != ownerInterface:
+		c.report(companion.Position, "Companion objects are only allowed inside a class or interface")
+	case !isCompanion && decl.Name.Spelling == "":
+		c.report(decl.Object.Position, "Object declaration must have a name")
+	case s.owner == ownerFunction:
+		c.report(decl.Name.Position, "Named object '%s' is a singleton and cannot be local. Try to use anonymous object instead", decl.Name.Spelling)
+	}
+
+	c.checkSuperTypes(decl.SuperTypes, scope{})
+	c.checkClassBody(decl.Members, s.members(ownerObject, decl.ObjectName()))
+}
+
+// checkClassBody checks the members of a class, interface or object body
+// in the scope s of the body.
+func (c *Checker) checkClassBody(members []ast.Stmt, s scope) {
+	companions := 0
+	for _, member := range members {
+		object, ok := member.(*ast.ObjectDeclStmt)
+		if !ok || !object.Modifiers.Has(modifierCompanion) {
+			continue
+		}
+		companions++
+		if companions > 1 {
+			c.report(object.Object.Position, "Only one companion object is allowed per class")
+		}
+	}
+
+	c.checkStmts(members, s)
+}
+
+func (c *Checker) checkSuperTypes(superTypes []*ast.SuperType, s scope) {
+	for _, superType := range superTypes {
+		for _, arg := range superType.Args {
+			c.checkExpr(arg, s)
+		}
+		if superType.Delegate != nil {
+			c.checkExpr(superType.Delegate, s)
+		}
+	}
+}
+
+func (c *Checker) checkVariableDecl(decl *ast.VariableDecl, s scope) {
+	c.checkAnnotations(decl.Annotations)
+	c.checkName(decl.Name)
+	c.checkType(decl.Receiver)
+	c.checkType(decl.Type)
+	c.checkModifiers(decl.Modifiers, targetProperty)
+	c.checkVisibility(decl.Modifiers, targetProperty, s)
+	if decl.Receiver != nil && decl.Value != nil {
+		c.report(decl.Name.Position, "Extension property cannot be initialized because it has no backing field")
+	}
+
+	if decl.Modifiers.Has(modifierLateinit) {
+		c.checkLateinit(decl)
+	}
+	if decl.Modifiers.Has(modifierConst) {
+		c.checkConst(decl, s)
+	}
+
+	if decl.Value != nil {
+		c.checkExpr(decl.Value, s)
+	}
+	if decl.Delegate != nil {
+		c.checkDelegate(decl, s)
+	}
+	c.checkAccessors(decl, s)
+}
+
+func (c *Checker) checkExpr(expr ast.Expr, s scope) {
+	switch e := expr.(type) {
+	case *ast.ThisExpr:
+		if e.Label.Spelling != "" {
+			c.checkQualifiedThis(e, s)
+		} else if !s.hasThis {
+			c.report(e.Token.Position, "'this' is not defined in this context")
+		}
+	case *ast.BinaryExpr:
+		c.checkExpr(e.Left, s)
+		c.checkExpr(e.Right, s)
+	case *ast.InfixCallExpr:
+		c.checkExpr(e.Left, s)
+		c.checkExpr(e.Right, s)
+	case *ast.UnaryExpr:
+		c.checkExpr(e.Right, s)
+	case *ast.GroupingExpr:
+		c.checkExpr(e.Expr, s)
+	case *ast.NonNullableExpr:
+		c.checkExpr(e.Expr, s)
+	case *ast.IdentifierExpr:
+		c.inlineConstant(e, s)
+	case *ast.MemberExpr:
+		c.checkExpr(e.Receiver, s)
+		c.inlineConstant(e, s)
+	case *ast.IsExpr:
+		c.checkExpr(e.Expr, s)
+		c.checkType(e.Type)
+	case *ast.CallExpr:
+		c.checkSealedInstantiation(e)
+		if callee, ok := e.Callee.(*ast.IdentifierExpr); ok {
+			c.checkTopLevelAccess(callee.Value.Spelling, callee.Value.Position)
+		}
+		c.checkExpr(e.Callee, s)
+		for _, arg := range e.Args {
+			if lambda, ok := arg.(*ast.LambdaExpr); ok {
+				c.checkLambda(lambda, s, jumpLambda, calleeName(e))
+			} else {
+				c.checkExpr(arg, s)
+			}
+		}
+	case *ast.AnnotatedExpr:
+		c.checkAnnotations(e.Annotations)
+		c.checkExpr(e.Expr, s)
+	case *ast.LambdaExpr:
+		c.checkLambda(e, s, jumpClosure, "")
+	case *ast.ReturnExpr:
+		c.checkReturn(e, s)
+	case *ast.JumpExpr:
+		c.checkJump(e, s)
+	case *ast.FunctionLiteral:
+		c.checkParameters(e.Parameters)
+		c.checkType(e.Type)
+		c.checkFunctionBody(e.Body, s.function("", s.hasThis))
+	case *ast.IfExpr:
+		c.checkIf(e, s, true)
+	case *ast.WhenExpr:
+		c.checkWhen(e, s)
+	case *ast.ThrowExpr:
+		c.checkExpr(e.Expr, s)
+	case *ast.TryExpr:
+		c.checkTry(e, s)
+	case *ast.ObjectExpr:
+		c.checkSuperTypes(e.SuperTypes, s)
+		c.checkClassBody(e.Members, scope{owner: ownerObject, hasThis: true, class: s.class, receivers: s.receivers})
+	}
+}
+
+// typeString spells a type the way it is written in source, for messages.
+func typeString(t ast.Type) string {
+	switch tp := t.(type) {
+	case *ast.TypeName:
+		if len(tp.Args) == 0 {
+			return tp.Name
+		}
+		return tp.Name + "<" + typeStrings(tp.Args) + ">"
+	case *ast.NullableType:
+		if _, ok := tp.Type.(*ast.FunctionType); ok {
+			return "(" + typeString(tp.Type) + ")?"
+		}
+		return typeString(tp.Type) + "?"
+	case *ast.FunctionType:
+		return "(" + typeStrings(tp.Params) + ") -> " + typeString(tp.Return)
+	case *ast.ArrayType:
+		return "[]" + typeString(tp.Underlying)
+	default:
+		return "Unit"
+	}
+}
+
+func typeStrings(types []ast.Type) string {
+	spelled := make([]string, len(types))
+	for i, t := range types {
+		spelled[i] = typeString(t)
+	}
+	return strings.Join(spelled, ", ")
+}
@@ -0,0 +1,612 @@
+package checker
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"gotlin/frontend/ast"
+	"gotlin/frontend/parser"
+	"gotlin/frontend/resolver"
+	"gotlin/frontend/scanner"
+)
+
+// parse parses and resolves input. The resolver's errors are left out, as
+// the tests are about those of the checker.
+func parse(input string) *ast.Program {
+	program := parser.New(scanner.NewScanner(strings.NewReader(input))).Parse()
+	resolver.New().Resolve(program)
+	return program
+}
+
+func check(input string) []error {
+	return New().Check(parse(input))
+}
+
+// expectErrors checks src and expects errors mentioning each of want, in
+// order.
+func expectErrors(t *testing.T, src string, want []string) {
+	t.Helper()
+	errs := check(src)
+	if len(errs) != len(want) {
+		t.Errorf("%q: got errors %v, want %v", src, errs, want)
+		return
+	}
+	for i, err := range errs {
+		if !strings.Contains(err.Error(), want[i]) {
+			t.Errorf("%q: error %q does not mention %q", src, err, want[i])
+		}
+	}
+}
+
+func TestChecker_OperatorFunctions(t *testing.T) {
+	tests := []struct {
+		input  string
+		errors []string
+	}{
+		{`class Vector(val x: Int, val y: Int) {
+			operator fun plus(other: Vector): Vector = other
+			operator fun unaryMinus(): Vector = this
+			operator fun compareTo(other: Vector): Int = 0
+			operator fun equals(other: Any?): Boolean = false
+			operator fun invoke() {}
+			operator fun component1(): Int = x
+		}`, nil},
+		{"operator fun plus(a: Int): Int = a", []string{"must be a member or an extension function"}},
+		{"class A { operator fun add(a: A) = a }", []string{"'add' is not an operator function name"}},
+		{"class A { operator fun plus(a: A, b: A) = a }", []string{"must have exactly 1 value parameter(s), got 2"}},
+		{"class A { operator fun unaryMinus(a: A) = a }", []string{"must have exactly 0 value parameter(s), got 1"}},
+		{"class A { operator fun set(i: Int) {} }", []string{"must have at least 2 value parameter(s), got 1"}},
+		{"class A { operator fun compareTo(a: A): Boolean = true }", []string{"'compareTo' must return Int, not Boolean"}},
+		{"class A { operator fun contains(a: A) {} }", []string{"'contains' must return Boolean, not Unit"}},
+		{"class A { operator fun equals(other: A): Boolean = true }", []string{"must override 'equals(other: Any?)'"}},
+		{"operator class A", []string{"Modifier 'operator' is not applicable to 'class'"}},
+	}
+
+	for _, test := range tests {
+		expectErrors(t, test.input, test.errors)
+	}
+}
+
+func TestChecker_InfixFunctions(t *testing.T) {
+	tests := []struct {
+		input  string
+		errors int
+	}{
+		{"class Bits { infix fun and(mask: Bits): Bits = mask }", 0},
+		{"infix fun to(a: Int): Int = a", 1},
+		{"class Bits { infix fun and(a: Bits, b: Bits): Bits = a }", 1},
+		{"class Bits { infix fun flip(): Bits = this }", 1},
+		{"infix class Bits", 1},
+	}
+
+	for _, test := range tests {
+		if errs := check(test.input); len(errs) != test.errors {
+			t.Errorf("%q: got errors %v, want %d", test.input, errs, test.errors)
+		}
+	}
+}
+
+func TestChecker_Extensions(t *testing.T) {
+	tests := []struct {
+		input  string
+		errors []string
+	}{
+		{`fun String.shout() = this + "!"
+		val Int.isEven get() = this % 2 == 0
+		operator fun String.unaryMinus() = this
+		infix fun Int.pow(n: Int) = this`, nil},
+		{"class A { fun f() = fun() = this }", nil},
+		{"fun f() = this", []string{"'this' is not defined in this context"}},
+		{"val x = this", []string{"'this' is not defined in this context"}},
+		{"val Int.twice = 2", []string{"Extension property cannot be initialized"}},
+	}
+
+	for _, test := range tests {
+		expectErrors(t, test.input, test.errors)
+	}
+}
+
+func TestChecker_ObjectDecl(t *testing.T) {
+	tests := []struct {
+		input  string
+		errors []string
+	}{
+		{`object Registry { operator fun invoke() = this }
+		class User { companion object Factory { fun create() = this } }
+		val listener = object : Listener { fun f() = this }`, nil},
+		{"companion object", []string{"Companion objects are only allowed inside a class or interface"}},
+		{"object Outer { companion object }", []string{"Companion objects are only allowed inside a class or interface"}},
+		{"class A { companion object\n companion object B }", []string{"Only one companion object is allowed per class"}},
+		{"object", []string{"Object declaration must have a name"}},
+		{"fun f() { object Local }", []string{"Named object 'Local' is a singleton and cannot be local"}},
+		{"companion class A", []string{"Modifier 'companion' is not applicable to 'class'"}},
+	}
+
+	for _, test := range tests {
+		expectErrors(t, test.input, test.errors)
+	}
+}
+
+func TestChecker_EnumClass(t *testing.T) {
+	enum := `enum class Color(val rgb: Int, val alpha: Int = 255) { RED(1), GREEN(2), BLUE(3) }
+	`
+	tests := []struct {
+		input  string
+		errors []string
+	}{
+		{enum + `val warm = when (c) {
+			Color.RED, Color.GREEN -> true
+			Color.BLUE -> false
+		}`, nil},
+		{enum + `val warm = when (c) {
+			Color.RED -> true
+			else -> false
+		}`, nil},
+		{`when (n) {
+			1 -> log(n)
+		}`, nil},
+		{`val x = when (b) {
+			true -> 1
+			false -> 0
+		}`, nil},
+		{`val x = when (n) {
+			else -> 1
+			1 -> 2
+		}`, []string{"'else' entry must be the last one"}},
+		{"enum object E", []string{"Modifier 'enum' is not applicable to 'object'"}},
+	}
+
+	for _, test := range tests {
+		expectErrors(t, test.input, test.errors)
+	}
+}
+
+func TestChecker_IfExpr(t *testing.T) {
+	tests := []struct {
+		input  string
+		errors []string
+	}{
+		{`fun f(c: Boolean) { if (c) log(1) }`, nil},
+		{`fun f(c: Boolean) = if (c) 1 else 2`, nil},
+		{`fun f(c: Boolean) = if (c) 1`, []string{"'if' must have both main and 'else' branches if used as an expression"}},
+		{`val x = if (c) { 1 }`, []string{"'if' must have both main and 'else' branches if used as an expression"}},
+	}
+
+	for _, test := range tests {
+		expectErrors(t, test.input, test.errors)
+	}
+}
+
+func TestChecker_SealedClass(t *testing.T) {
+	sealed := `sealed class Result {
+		class Ok(val value: Int) : Result()
+		sealed class Err : Result()
+	}
+	class Timeout : Result.Err()
+	object Refused : Result.Err()
+	`
+	tests := []struct {
+		input  string
+		errors []string
+	}{
+		{sealed + `val x = when (r) {
+			is Result.Ok -> 1
+			is Result.Err -> 2
+		}`, nil},
+		{sealed + `val x = when (r) {
+			is Result.Ok -> 1
+			is Timeout -> 2
+			Refused -> 3
+		}`, nil},
+		{sealed + `val x = when (r) {
+			is Result.Ok -> 1
+			else -> 2
+		}`, nil},
+		{sealed + "val r = Result()", []string{"Sealed types cannot be instantiated"}},
+		{sealed + "val r = Result.Err()", []string{"Sealed types cannot be instantiated"}},
+		{sealed + "val r = Result.Ok(1)", nil},
+		{"sealed interface Shape\nclass Square : Shape\nval n = when (s) { is Square -> 4 }", nil},
+		{"sealed fun f() {}", []string{"Modifier 'sealed' is not applicable to 'function'"}},
+		{"data class Point(val x: Int, val y: Int)", nil},
+		{"data class Empty()", []string{"Data class must have at least one primary constructor parameter"}},
+		{"data class Point(val x: Int, y: Int)", []string{"Data class primary constructor must only have property (val / var) parameters"}},
+	}
+
+	for _, test := range tests {
+		expectErrors(t, test.input, test.errors)
+	}
+}
+
+func TestChecker_Exceptions(t *testing.T) {
+	tests := []struct {
+		input  string
+		errors []string
+	}{
+		{`val x = try { parse(s) } catch (e: NumberFormatException) { 0 } finally { log("done") }`, nil},
+		{`class ValidationException(val field: Int) : IllegalArgumentException()
+		fun validate(n: Int) {
+			throw ValidationException(n)
+		}
+		val ok = try { validate(1) } catch (e: ValidationException) { log(e) }`, nil},
+		{`val n = x ?: throw IllegalStateException("missing")`, nil},
+		{`val x = try { 1 } catch (e: Int) { 2 }`, []string{"inferred type is Int but Throwable was expected"}},
+		{`val x = try { 1 } catch (e: Exception?) { 2 }`, []string{"inferred type is Exception? but Throwable was expected"}},
+		{`val x = try { this } finally { }`, []string{"'this' is not defined in this context"}},
+	}
+
+	for _, test := range tests {
+		expectErrors(t, test.input, test.errors)
+	}
+}
+
+func TestChecker_Annotations(t *testing.T) {
+	tests := []struct {
+		input  string
+		errors []string
+	}{
+		{`@Deprecated("use sum", 1 + 2) fun add(a: Int) = a`, nil},
+		{`@Retry(-3, Mode.FAST) fun fetch() = 1`, nil},
+		{`@Deprecated(message()) fun add(a: Int) = a`, []string{"An annotation argument must be a compile-time constant"}},
+		{`class Point(@Json(name()) val x: Int)`, []string{"An annotation argument must be a compile-time constant"}},
+		{`val x = @Suppress(this) 1`, []string{"An annotation argument must be a compile-time constant"}},
+	}
+
+	for _, test := range tests {
+		expectErrors(t, test.input, test.errors)
+	}
+}
+
+func TestChecker_TypeAlias(t *testing.T) {
+	tests := []struct {
+		input  string
+		errors []string
+	}{
+		{`typealias Handler = (Request) -> Response
+		typealias StringMap<V> = Map<String, V>
+		fun route(h: Handler, headers: StringMap<Int>): Handler? = h`, nil},
+		{`typealias Failure = IllegalStateException
+		val x = try { 1 } catch (e: Failure) { 2 }`, nil},
+		{`typealias Count = Int
+		val x = try { 1 } catch (e: Count) { 2 }`, []string{"inferred type is Count but Throwable was expected"}},
+		{`typealias StringMap<V> = Map<String, V>
+		val m: StringMap<Int, Int>? = null`, []string{"1 type arguments expected for StringMap, but 2 were given"}},
+		{`typealias A = List<B>
+		typealias B = (A) -> Int`, []string{
+			"Recursive type alias in expansion: A",
+			"Recursive type alias in expansion: B",
+		}},
+		{`class Outer { typealias Inner = Int }`, []string{"Nested and local type aliases are not supported"}},
+		{`operator typealias Op = Int`, []string{"Modifier 'operator' is not applicable to 'typealias'"}},
+	}
+
+	for _, test := range tests {
+		expectErrors(t, test.input, test.errors)
+	}
+}
+
+func TestChecker_Destructuring(t *testing.T) {
+	tests := []struct {
+		input  string
+		errors []string
+	}{
+		{`val (name, _, _) = person
+		for ((k, v) in entries) { log(k) }
+		val sum = { (a, _), _ -> a }`, nil},
+		{`val (a, a) = pair`, []string{"Redeclaration: a"}},
+		{`for ((k, k) in entries) log(k)`, []string{"Redeclaration: k"}},
+		{`val f = { a, a -> a }`, []string{"Redeclaration: a"}},
+		{`class Point { val (x, y) = pair }`, []string{"Destructuring declarations are only allowed for local variables/values"}},
+		{`val _ = 1`, []string{"Names _, __, ___, ..., are reserved in Kotlin"}},
+		{`for (x in xs) { this }`, []string{"'this' is not defined in this context"}},
+	}
+
+	for _, test := range tests {
+		expectErrors(t, test.input, test.errors)
+	}
+}
+
+func TestChecker_Jumps(t *testing.T) {
+	tests := []struct {
+		input  string
+		errors []string
+	}{
+		{`fun find(xs: List) {
+			outer@ for (x in xs) {
+				for (y in xs) {
+					continue@outer
+					break
+				}
+				while (x) { break@outer }
+			}
+			xs.forEach { x ->
+				return@forEach
+			}
+			xs.forEach lit@{ return@lit 1 }
+			xs.forEach { return }
+			return@find
+		}`, nil},
+		{`fun f() = fun() { return 1 }`, nil},
+		{`val x = 1
+		return`, []string{"'return' is not allowed here"}},
+		{`class A { val x = return }`, []string{"'return' is not allowed here"}},
+		{`fun f() { val g = { return } }`, []string{"'return' is not allowed here"}},
+		{`fun f() { val g = lit@{ return@lit } }`, nil},
+		{`fun f() { return@g }`, []string{"Unresolved label"}},
+		{`fun f() { break }`, []string{"'break' and 'continue' are only allowed inside a loop"}},
+		{`fun f() { for (x in xs) { xs.forEach { break } } }`, []string{"'break' or 'continue' jumps across a function or a class boundary"}},
+		{`fun f() { for (x in xs) { continue@inner } }`, []string{"Unresolved label"}},
+		{`fun f() { xs.forEach { continue@forEach } }`, []string{"The label '@forEach' does not denote a loop"}},
+		{`fun f() { for (x in xs) { class Local { fun g() { break } } } }`, []string{"'break' and 'continue' are only allowed inside a loop"}},
+		{`fun f(x: Int) { while (true) { if (x > 0) break else return } }`, nil},
+		{`fun f() { if (true) break }`, []string{"'break' and 'continue' are only allowed inside a loop"}},
+	}
+
+	for _, test := range tests {
+		expectErrors(t, test.input, test.errors)
+	}
+}
+
+func TestChecker_Visibility(t *testing.T) {
+	tests := []struct {
+		input  string
+		errors []string
+	}{
+		{`class Account(private val id: Int, protected val owner: String) {
+			private fun audit() = id
+			fun report() = this.audit()
+			companion object {
+				private fun create() = 1
+			}
+			fun make() = Account.create()
+		}
+		class Savings : Account(1, "a") {
+			fun who() = this.owner
+		}
+		internal object Registry {
+			internal val size = 0
+		}
+		val n = Registry.size`, nil},
+		{`protected fun f() = 1`, []string{"Modifier 'protected' is not applicable inside 'file'"}},
+		{`fun f() { private val x = 1 }`, []string{"Modifier 'private' is not applicable to 'local property'"}},
+		{`private public class A`, []string{"Modifier 'public' is incompatible with 'private'"}},
+		{`open class Shape { open fun area() = 0 }
+		abstract class Polygon : Shape() {
+			abstract val sides: Int
+			override fun area() = sides
+		}`, nil},
+		{`override class A`, []string{"Modifier 'override' is not applicable to 'class'"}},
+	}
+
+	for _, test := range tests {
+		expectErrors(t, test.input, test.errors)
+	}
+}
+
+func TestChecker_FilePrivateDeclarations(t *testing.T) {
+	var programs []*ast.Program
+	for i, input := range []string{
+		"private fun helper() = 1\nprivate class Secret\nval shared = helper()",
+		"fun use() = helper()\nval s: Secret = make()",
+	} {
+		program := parser.New(scanner.NewScanner(strings.NewReader(input))).Parse()
+		program.File = fmt.Sprintf("file%d.gt", i)
+		programs = append(programs, program)
+	}
+
+	errs := New().CheckPackage(programs)
+	want := []string{"file1.gt: [1, 13] Cannot access 'helper': it is private in file", "file1.gt: [2, 8] Cannot access 'Secret': it is private in file"}
+	if len(errs) != len(want) {
+		t.Fatalf("got errors %v, want %v", errs, want)
+	}
+	for i, err := range errs {
+		if !strings.Contains(err.Error(), want[i]) {
+			t.Errorf("error %q does not mention %q", err, want[i])
+		}
+	}
+}
+
+func TestChecker_Properties(t *testing.T) {
+	tests := []struct {
+		input  string
+		errors []string
+	}{
+		{`class Counter {
+			var count: Int = 0
+				private set
+			var name: String = ""
+				get() = field
+				set(value) { field = value }
+			val size: Int get() = count
+			lateinit var client: Client
+			fun reset() { this.count = 0 }
+		}
+		object Limits {
+			const val MAX = 10
+			const val LABEL = "max " + MAX
+		}
+		const val TWICE = Limits.MAX * 2`, nil},
+		{"val x = 1\n\tset(v) {}", []string{"A 'val'-property cannot have a setter"}},
+		{"private var x = 1\n\tpublic set", []string{"Setter visibility must be the same or less permissive than property visibility"}},
+		{"val x: Int = 1\n\tget() = 2", []string{"Initializer is not allowed here because this property has no backing field"}},
+		{"lateinit val x: String", []string{"'lateinit' modifier is allowed only on mutable properties"}},
+		{`lateinit var x: String = ""`, []string{"'lateinit' modifier is not allowed on properties with initializer"}},
+		{"lateinit var x: String?", []string{"'lateinit' modifier is not allowed on properties of nullable types"}},
+		{"lateinit var x: Int", []string{"'lateinit' modifier is not allowed on properties of primitive types"}},
+		{"const var x = 1", []string{"Modifier 'const' is not applicable to 'vars'"}},
+		{"class A { const val x = 1 }", []string{"Const 'val' are only allowed on top level, in named objects, or in companion objects"}},
+		{"const val x = compute()", []string{"Const 'val' initializer should be a constant value"}},
+		{"const val x = 1 / 0", []string{"Const 'val' initializer should be a constant value"}},
+		{"const val a = b\nconst val b = a", []string{"Const 'val' initializer should be a constant value", "Const 'val' initializer should be a constant value"}},
+		{"const val x: List = 1", []string{"Const 'val' has type 'List'. Only primitives and String are allowed"}},
+		{"val noField: Int\n\tget() = field", []string{"Property must be initialized"}},
+		{"class A {\n\tvar x: Int\n}", []string{"Property must be initialized or be abstract"}},
+		{`abstract class A {
+			abstract val x: Int
+		}
+		interface I {
+			val y: Int
+		}
+		val computed: Int get() = 1
+		fun f() {
+			val local: Int
+		}`, nil},
+	}
+
+	for _, test := range tests {
+		expectErrors(t, test.input, test.errors)
+	}
+}
+
+func TestChecker_InlinesConstants(t *testing.T) {
+	input := `class Config {
+		companion object {
+			const val PREFIX = "v" + VERSION
+		}
+	}
+	const val VERSION = 1 + 1
+	val name = Config.PREFIX
+	val next = VERSION
+	fun shadow(VERSION: Int) = VERSION
+	const val ORDERED = 9007199254740993 > 9007199254740992
+	val ordered = ORDERED`
+
+	program := parse(input)
+	if errs := New().Check(program); len(errs) != 0 {
+		t.Fatalf("Check() reported %v", errs)
+	}
+
+	member := program.Statements[2].(*ast.VariableDecl).Value.(*ast.MemberExpr)
+	if prefix, ok := member.Constant.(*ast.StringLiteral); !ok || prefix.Value != "v2" {
+		t.Errorf("Config.PREFIX is inlined as %#v, want \"v2\"", member.Constant)
+	}
+	id := program.Statements[3].(*ast.VariableDecl).Value.(*ast.IdentifierExpr)
+	if version, ok := id.Constant.(*ast.IntLiteral); !ok || version.Value != 2 {
+		t.Errorf("VERSION is inlined as %#v, want 2", id.Constant)
+	}
+	param := program.Statements[4].(*ast.FunctionDecl).Body.Expr.(*ast.IdentifierExpr)
+	if param.Constant != nil {
+		t.Errorf("a parameter shadowing VERSION is inlined as %#v", param.Constant)
+	}
+	id = program.Statements[6].(*ast.VariableDecl).Value.(*ast.IdentifierExpr)
+	if ordered, ok := id.Constant.(*ast.BoolLiteral); !ok || !ordered.Value {
+		t.Errorf("ORDERED is inlined as %#v, want true", id.Constant)
+	}
+}
+
+func TestChecker_FoldsIntOverflow(t *testing.T) {
+	input := `const val MAX = 2147483647
+	const val NEXT = MAX + 1
+	const val BIG = 4294967296 + 1
+	val next = NEXT
+	val big = BIG`
+
+	program := parse(input)
+	if errs := New().Check(program); len(errs) != 0 {
+		t.Fatalf("Check() reported %v", errs)
+	}
+
+	want := []int64{-2147483648, 4294967297}
+	for i, stmt := range program.Statements[3:] {
+		id := stmt.(*ast.VariableDecl).Value.(*ast.IdentifierExpr)
+		if value, ok := id.Constant.(*ast.IntLiteral); !ok || value.Value != want[i] {
+			t.Errorf("%s is inlined as %#v, want %d", id.Value.Spelling, id.Constant, want[i])
+		}
+	}
+}
+
+func TestChecker_Delegation(t *testing.T) {
+	tests := []struct {
+		input  string
+		errors []string
+	}{
+		{`class Store {
+			operator fun getValue(thisRef: Any?, property: KProperty): String = ""
+			operator fun setValue(thisRef: Any?, property: KProperty, value: String) {}
+		}
+		interface Sink { fun write(s: String) }
+		class Buffer(inner: Sink) : Sink by inner
+		class User(map: Map<String, Any>) {
+			val name: String by map
+			var email: String by Store()
+			private val config by lazy { load() }
+		}`, nil},
+		{"val x: Int by lazy { 1 }\n\tget() = 2", []string{"Delegated property cannot have accessors with non-default implementations"}},
+		{"lateinit var x: String by map", []string{"'lateinit' modifier is not allowed on delegated properties"}},
+		{"const val x: Int by lazy { 1 }", []string{"Const 'val' should not have a delegate"}},
+		{`class Base
+		class Derived(base: Base) : Base by base`, []string{"Only interfaces can be delegated to"}},
+		{`interface Sink
+		interface Logging(inner: Sink) : Sink by inner`, []string{"Delegation is not allowed in interfaces"}},
+	}
+
+	for _, test := range tests {
+		expectErrors(t, test.input, test.errors)
+	}
+}
+
+func TestChecker_NestedClasses(t *testing.T) {
+	tests := []struct {
+		input  string
+		errors []string
+	}{
+		{`class Outer {
+			val x = 1
+			class Nested { fun f() = this@Nested }
+			inner class Inner {
+				inner class Innermost { fun f() = this@Outer.x + this@Inner.hashCode() }
+				fun g() = listOf(1).map { this@Outer.x + it }
+			}
+		}
+		fun String.shout() = this@shout.uppercase()
+		fun count(from: Int) {
+			class Counter { fun next() = from + 1 }
+			log(Counter().next())
+		}`, nil},
+		{`class Outer {
+			val x = 1
+			class Nested { fun f() = this@Outer.x }
+		}`, []string{"Expression is inaccessible from a nested class 'Nested', use 'inner' keyword to make the class inner"}},
+		{"fun f() = this@Outer", []string{"Unresolved label"}},
+		{"inner class A", []string{"Modifier 'inner' is not applicable inside 'file'"}},
+		{"object O { inner class A }", []string{"Modifier 'inner' is not applicable inside 'object'"}},
+		{"fun f() { inner class A }", []string{"Modifier 'inner' is not applicable to 'local class'"}},
+		{"inner object O", []string{"Modifier 'inner' is not applicable to 'object'"}},
+		{"class A { inner class B { class C } }", []string{"Nested class 'C' is not allowed here, use 'inner' keyword to make the class inner"}},
+	}
+
+	for _, test := range tests {
+		expectErrors(t, test.input, test.errors)
+	}
+}
+
+func TestChecker_ValueClasses(t *testing.T) {
+	tests := []struct {
+		input  string
+		errors []string
+	}{
+		{`interface Id
+		@JvmInline
+		value class UserId(val raw: Long) : Id {
+			val isValid get() = raw > 0
+			fun next() = UserId(raw + 1)
+		}
+		@JvmInline
+		value class Email(val address: String)`, nil},
+		{"value class UserId(val raw: Long)", []string{"Value classes without @JvmInline annotation are not yet supported"}},
+		{"@JvmInline\nvalue class Point(val x: Int, val y: Int)", []string{"Value class must have exactly one primary constructor parameter"}},
+		{"@JvmInline\nvalue class Counter(var count: Int)", []string{"Value class primary constructor must only have final read-only (val) property parameter"}},
+		{"@JvmInline\nvalue class Id(raw: Long)", []string{"Value class primary constructor must only have final read-only (val) property parameter"}},
+		{"@JvmInline\nvalue class Id(val raw: Long) { val cached = raw * 2 }", []string{"Value class cannot have properties with backing fields"}},
+		{"@JvmInline\nvalue class Id(val raw: Long) { val name by lazy { raw } }", []string{"Value class cannot have delegated properties"}},
+		{"class Base\n@JvmInline\nvalue class Id(val raw: Long) : Base()", []string{"Value class cannot extend classes"}},
+		{"@JvmInline\nvalue class A(val b: B)\n@JvmInline\nvalue class B(val a: A)", []string{"Value class cannot be recursive", "Value class cannot be recursive"}},
+		{"class Outer { @JvmInline\ninner value class Id(val raw: Long) }", []string{"Value class cannot be local or inner"}},
+		{"@JvmInline\ndata value class Id(val raw: Long)", []string{"Modifier 'data' is incompatible with 'value'"}},
+		{"value interface Id", []string{"Modifier 'value' is not applicable to 'interface'"}},
+	}
+
+	for _, test := range tests {
+		expectErrors(t, test.input, test.errors)
+	}
+}
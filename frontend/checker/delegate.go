@@ -0,0 +1,37 @@
+package checker
+
+import (
+	"gotlin/frontend/ast"
+)
+
+// checkDelegate checks a property delegated with `by`, declared in s.
+func (c *Checker) checkDelegate(decl *ast.VariableDecl, s scope) {
+	c.checkExpr(decl.Delegate, s)
+	for _, accessor := range []*ast.PropertyAccessor{decl.Getter, decl.Setter} {
+		if accessor != nil && accessor.Body != nil {
+			c.report(accessor.Keyword.Position, "Delegated property cannot have accessors with non-default implementations")
+		}
+	}
+}
+
+// checkClassDelegation checks the supertypes a class declared in s
+// implements by delegation, `: List<T> by inner`.
+func (c *Checker) checkClassDelegation(decl *ast.ClassDeclStmt, s scope) {
+	for _, superType := range decl.SuperTypes {
+		name, ok := superType.Type.(*ast.TypeName)
+		if superType.Delegate == nil || !ok {
+			continue
+		}
+		if decl.Interface {
+			c.report(name.Position, "Delegation is not allowed in interfaces")
+			continue
+		}
+		expanded, ok := c.expandType(name).(*ast.TypeName)
+		if !ok {
+			continue
+		}
+		if super := c.resolveClass(expanded.Name, s.class); super != nil && !super.iface {
+			c.report(name.Position, "Only interfaces can be delegated to")
+		}
+	}
+}
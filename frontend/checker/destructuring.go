@@ -0,0 +1,73 @@
+package checker
+
+import (
+	"strings"
+
+	"gotlin/frontend/ast"
+	"gotlin/frontend/token"
+)
+
+func (c *Checker) checkDestructuringDecl(decl *ast.DestructuringDecl, s scope) {
+	c.checkAnnotations(decl.Annotations)
+	c.checkModifiers(decl.Modifiers, targetProperty)
+	if s.member() {
+		c.report(decl.Pattern.Open.Position, "Destructuring declarations are only allowed for local variables/values")
+	}
+
+	c.checkPattern(decl.Pattern)
+	c.checkExpr(decl.Value, s)
+}
+
+// checkPattern reports names a destructuring pattern binds twice. Unused
+// components may all be called `_`.
+func (c *Checker) checkPattern(pattern *ast.DestructuringPattern) {
+	seen := make(map[string]bool)
+	for _, entry := range pattern.Entries {
+		name := entry.Name.Spelling
+		if name != ast.Underscore && seen[name] {
+			c.report(entry.Name.Position, "Redeclaration: %s", name)
+		}
+		seen[name] = true
+		c.checkType(entry.Type)
+	}
+}
+
+func (c *Checker) checkFor(loop *ast.ForStmt, s scope) {
+	c.checkExpr(loop.Iterable, s)
+	if loop.Pattern != nil {
+		c.checkPattern(loop.Pattern)
+	}
+	c.checkStmt(loop.Body, s.enter(jumpLoop, loop.Label.Spelling))
+}
+
+// checkLambda checks a lambda of the given jump kind, which is labeled by
+// the function it is passed to, implicit, unless it declares its own label.
+func (c *Checker) checkLambda(lambda *ast.LambdaExpr, s scope, kind string, implicit string) {
+	seen := make(map[string]bool)
+	for _, param := range lambda.Parameters {
+		c.checkType(param.Type)
+		if param.Pattern != nil {
+			c.checkPattern(param.Pattern)
+			continue
+		}
+
+		name := param.Name.Spelling
+		if name != ast.Underscore && seen[name] {
+			c.report(param.Name.Position, "Redeclaration: %s", name)
+		}
+		seen[name] = true
+	}
+	label := implicit
+	if lambda.Label.Spelling != "" {
+		label = lambda.Label.Spelling
+	}
+	c.checkStmts(lambda.Body, s.enter(kind, label).withReceiver(label))
+}
+
+// checkName reports declarations named `_`, which is reserved for unused
+// components and lambda parameters.
+func (c *Checker) checkName(name token.Token) {
+	if strings.Trim(name.Spelling, ast.Underscore) == "" && name.Spelling != "" {
+		c.report(name.Position, "Names _, __, ___, ..., are reserved in Kotlin")
+	}
+}
@@ -0,0 +1,19 @@
+package checker
+
+import (
+	"gotlin/frontend/ast"
+)
+
+const modifierEnum = "enum"
+
+// checkEnumEntries checks the entries of an enum class whose body has scope
+// s.
+func (c *Checker) checkEnumEntries(decl *ast.ClassDeclStmt, s scope) {
+	for _, entry := range decl.Entries {
+		c.checkAnnotations(entry.Annotations)
+		for _, arg := range entry.Args {
+			c.checkExpr(arg, scope{})
+		}
+		c.checkClassBody(entry.Members, s.members(ownerObject, entry.Name.Spelling))
+	}
+}
@@ -0,0 +1,83 @@
+package checker
+
+import (
+	"gotlin/frontend/ast"
+	"gotlin/frontend/object"
+)
+
+// primitiveTypes are the built-in types that can never be thrown.
+var primitiveTypes = map[string]bool{
+	"Any":     true,
+	"Int":     true,
+	"Long":    true,
+	"Double":  true,
+	"Char":    true,
+	"String":  true,
+	"Boolean": true,
+	"Unit":    true,
+	"Nothing": true,
+}
+
+func (c *Checker) checkTry(try *ast.TryExpr, s scope) {
+	block := s.block()
+	c.checkStmts(try.Body.Statements, block)
+	for _, clause := range try.Catches {
+		c.checkType(clause.Type)
+		if name, ok := c.expandType(clause.Type).(*ast.TypeName); !ok || c.throwable(name.Name) == throwableNo {
+			c.report(clause.Name.Position, "Type mismatch: inferred type is %s but Throwable was expected", typeString(clause.Type))
+		}
+		c.checkStmts(clause.Body.Statements, block)
+	}
+	if try.Finally != nil {
+		c.checkStmts(try.Finally.Statements, block)
+	}
+}
+
+// throwability is whether a class is known to extend Throwable.
+type throwability int
+
+const (
+	throwableUnknown throwability = iota
+	throwableYes
+	throwableNo
+)
+
+// throwable reports whether the class called name extends Throwable, either
+// directly through the built-in hierarchy or through declared classes.
+// Names the checker knows nothing about are left to the type checker.
+func (c *Checker) throwable(name string) throwability {
+	return c.throwableFrom(name, "", make(map[*classInfo]bool))
+}
+
+func (c *Checker) throwableFrom(name string, outer string, seen map[*classInfo]bool) throwability {
+	if _, ok := object.LookupThrowableClass(name); ok {
+		return throwableYes
+	}
+	if primitiveTypes[name] {
+		return throwableNo
+	}
+
+	info := c.resolveClass(name, outer)
+	if info == nil {
+		return throwableUnknown
+	}
+	if seen[info] {
+		return throwableNo
+	}
+	seen[info] = true
+
+	result := throwableNo
+	for _, superType := range info.supers {
+		tp, ok := superType.(*ast.TypeName)
+		if !ok {
+			continue
+		}
+		switch c.throwableFrom(tp.Name, info.outer, seen) {
+		case throwableYes:
+			return throwableYes
+		case throwableUnknown:
+			result = throwableUnknown
+		}
+	}
+	return result
+}
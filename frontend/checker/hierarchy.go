@@ -0,0 +1,164 @@
+package checker
+
+import (
+	"strings"
+
+	"gotlin/frontend/ast"
+	"gotlin/frontend/token"
+)
+
+const (
+	modifierSealed = "sealed"
+	modifierData   = "data"
+)
+
+// classInfo describes a class, interface or object declared in the program.
+type classInfo struct {
+	// name is qualified with the names of the enclosing declarations.
+	name   string
+	pos    token.Pos
+	object bool
+	sealed bool
+	iface  bool
+	// underlying is the type of the property of a value class, which
+	// stands for its instances, and nil for other classes.
+	underlying ast.Type
+	// outer is the qualified name of the enclosing declaration, if any.
+	outer     string
+	supers    []ast.Type
+	companion bool
+}
+
+// collectDeclarations records the classes and objects declared
+// anywhere in stmts before any of them is checked, so that checks can refer
+// to declarations that appear later in the file.
+func (c *Checker) collectDeclarations(stmts []ast.Stmt, outer string) {
+	for _, stmt := range stmts {
+		switch decl := stmt.(type) {
+		case *ast.ClassDeclStmt:
+			info := c.addClass(decl.Name.Spelling, decl.Name.Position, outer, decl.SuperTypes)
+			info.sealed = decl.Modifiers.Has(modifierSealed)
+			info.iface = decl.Interface
+			if decl.Modifiers.Has(modifierValue) && decl.PrimaryConstructor != nil && len(decl.PrimaryConstructor.Parameters) == 1 {
+				info.underlying = decl.PrimaryConstructor.Parameters[0].Type
+			}
+			c.collectDeclarations(decl.Members, info.name)
+		case *ast.TypeAliasDecl:
+			if outer == "" {
+				c.aliases[decl.Name.Spelling] = decl
+			}
+		case *ast.ObjectDeclStmt:
+			info := c.addClass(decl.ObjectName(), decl.Object.Position, outer, decl.SuperTypes)
+			info.object = true
+			info.companion = decl.Modifiers.Has(modifierCompanion)
+			c.collectConstants(decl.Members, info.name)
+			c.collectDeclarations(decl.Members, info.name)
+		}
+	}
+}
+
+func (c *Checker) addClass(name string, pos token.Pos, outer string, superTypes []*ast.SuperType) *classInfo {
+	info := &classInfo{name: qualify(outer, name), pos: pos, outer: outer}
+	for _, superType := range superTypes {
+		info.supers = append(info.supers, superType.Type)
+	}
+	c.classes[info.name] = info
+	c.declared = append(c.declared, info)
+	return info
+}
+
+func qualify(outer string, name string) string {
+	if outer == "" {
+		return name
+	}
+	return outer + "." + name
+}
+
+// resolveClass finds the class a name written inside outer refers to. Names
+// are looked up from the innermost enclosing declaration outwards, then, as
+// a last resort, by their unqualified name when that is unambiguous.
+func (c *Checker) resolveClass(name string, outer string) *classInfo {
+	for {
+		if info, ok := c.classes[qualify(outer, name)]; ok {
+			return info
+		}
+		if outer == "" {
+			break
+		}
+		if i := strings.LastIndex(outer, "."); i >= 0 {
+			outer = outer[:i]
+		} else {
+			outer = ""
+		}
+	}
+
+	var found *classInfo
+	for qualified, info := range c.classes {
+		if strings.HasSuffix(qualified, "."+name) {
+			if found != nil {
+				return nil
+			}
+			found = info
+		}
+	}
+	return found
+}
+
+func (c *Checker) checkDataClass(decl *ast.ClassDeclStmt) {
+	if !decl.Modifiers.Has(modifierData) {
+		return
+	}
+
+	if decl.PrimaryConstructor == nil || len(decl.PrimaryConstructor.Parameters) == 0 {
+		c.report(decl.Name.Position, "Data class must have at least one primary constructor parameter")
+		return
+	}
+	for _, param := range decl.PrimaryConstructor.Parameters {
+		if !param.Property {
+			c.report(decl.Name.Position, "Data class primary constructor must only have property (val / var) parameters")
+			return
+		}
+	}
+}
+
+// checkSealedInstantiation reports calls to the constructor of a sealed
+// class, which is implicitly abstract.
+func (c *Checker) checkSealedInstantiation(call *ast.CallExpr) {
+	var name token.Token
+	switch callee := call.Callee.(type) {
+	case *ast.IdentifierExpr:
+		name = callee.Value
+	case *ast.MemberExpr:
+		qualified, ok := qualifiedName(callee)
+		if !ok {
+			return
+		}
+		name = callee.Name
+		name.Spelling = qualified
+	default:
+		return
+	}
+
+	if info := c.resolveClass(name.Spelling, ""); info != nil && info.sealed && !info.object {
+		c.report(name.Position, "Sealed types cannot be instantiated")
+	}
+}
+
+// qualifiedName spells a chain of member accesses on a name, `Result.Ok`.
+func qualifiedName(expr ast.Expr) (string, bool) {
+	switch e := expr.(type) {
+	case *ast.IdentifierExpr:
+		return e.Value.Spelling, true
+	case *ast.MemberExpr:
+		if e.Safe {
+			return "", false
+		}
+		receiver, ok := qualifiedName(e.Receiver)
+		if !ok {
+			return "", false
+		}
+		return receiver + "." + e.Name.Spelling, true
+	default:
+		return "", false
+	}
+}
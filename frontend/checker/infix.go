@@ -0,0 +1,21 @@
+package checker
+
+import (
+	"gotlin/frontend/ast"
+)
+
+const modifierInfix = "infix"
+
+func (c *Checker) checkInfixFunction(decl *ast.FunctionDecl, s scope) {
+	const inapplicable = "'infix' modifier is inapplicable on this function: "
+	pos := decl.Name.Position
+
+	if !s.member() && decl.Receiver == nil {
+		c.report(pos, inapplicable+"must be a member or an extension function")
+		return
+	}
+
+	if len(decl.Parameters) != 1 {
+		c.report(pos, inapplicable+"must have a single value parameter")
+	}
+}
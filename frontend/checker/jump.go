@@ -0,0 +1,90 @@
+package checker
+
+import (
+	"gotlin/frontend/ast"
+)
+
+// Kinds of code a jump can leave.
+const (
+	jumpFunction = "function"
+	jumpLambda   = "lambda"
+	jumpClosure  = "closure"
+	jumpLoop     = "loop"
+)
+
+type jumpTarget struct {
+	kind  string
+	label string
+}
+
+// checkReturn checks that a return has a function or lambda to return
+// from. An unlabeled return inside a lambda passed to a call returns from
+// the enclosing function; one inside a lambda used as a value, a closure,
+// has no function to return from.
+func (c *Checker) checkReturn(ret *ast.ReturnExpr, s scope) {
+	if ret.Value != nil {
+		c.checkExpr(ret.Value, s)
+	}
+
+	label := ret.Label.Spelling
+	for i := len(s.jumps) - 1; i >= 0; i-- {
+		target := s.jumps[i]
+		if target.kind == jumpLoop {
+			continue
+		}
+		if label == "" && target.kind == jumpFunction || label != "" && target.label == label {
+			return
+		}
+		if label == "" && target.kind == jumpClosure {
+			break
+		}
+	}
+
+	if label != "" {
+		c.report(ret.Label.Position, "Unresolved label")
+		return
+	}
+	c.report(ret.Return.Position, "'return' is not allowed here")
+}
+
+// checkJump checks that a break or continue has a loop to leave within the
+// function or lambda it is written in.
+func (c *Checker) checkJump(jump *ast.JumpExpr, s scope) {
+	label := jump.Label.Spelling
+	crossed := false
+	for i := len(s.jumps) - 1; i >= 0; i-- {
+		target := s.jumps[i]
+		matches := label == "" || target.label == label
+		switch {
+		case target.kind == jumpLoop && matches:
+			if crossed {
+				c.report(jump.Jump.Position, "'break' or 'continue' jumps across a function or a class boundary")
+			}
+			return
+		case target.kind != jumpLoop && label != "" && target.label == label:
+			c.report(jump.Label.Position, "The label '@%s' does not denote a loop", label)
+			return
+		case target.kind != jumpLoop:
+			crossed = true
+		}
+	}
+
+	if label != "" {
+		c.report(jump.Label.Position, "Unresolved label")
+		return
+	}
+	c.report(jump.Jump.Position, "'break' and 'continue' are only allowed inside a loop")
+}
+
+// calleeName is the name of the function a call invokes, which labels the
+// lambdas passed to it: `xs.forEach { return@forEach }`.
+func calleeName(call *ast.CallExpr) string {
+	switch callee := call.Callee.(type) {
+	case *ast.IdentifierExpr:
+		return callee.Value.Spelling
+	case *ast.MemberExpr:
+		return callee.Name.Spelling
+	default:
+		return ""
+	}
+}
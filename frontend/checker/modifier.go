@@ -0,0 +1,59 @@
+package checker
+
+import (
+	"gotlin/frontend/ast"
+)
+
+const (
+	modifierCompanion = "companion"
+	modifierOpen      = "open"
+	modifierAbstract  = "abstract"
+	modifierOverride  = "override"
+
+	targetFunction  = "function"
+	targetProperty  = "property"
+	targetTypeAlias = "typealias"
+)
+
+// modifierTargets lists the declarations each modifier may be applied to.
+var modifierTargets = map[string][]string{
+	modifierOperator:  {targetFunction},
+	modifierInfix:     {targetFunction},
+	modifierCompanion: {ownerObject},
+	modifierEnum:      {ownerClass},
+	modifierSealed:    {ownerClass, ownerInterface},
+	modifierData:      {ownerClass, ownerObject},
+	modifierInner:     {ownerClass},
+	modifierValue:     {ownerClass},
+	modifierLateinit:  {targetProperty},
+	modifierConst:     {targetProperty},
+	modifierOpen:      {ownerClass, targetFunction, targetProperty},
+	modifierAbstract:  {ownerClass, targetFunction, targetProperty},
+	modifierOverride:  {targetFunction, targetProperty},
+
+	ast.Public:    declarationTargets,
+	ast.Private:   declarationTargets,
+	ast.Protected: declarationTargets,
+	ast.Internal:  declarationTargets,
+}
+
+// declarationTargets are the declarations a visibility applies to: every
+// kind of named declaration and property accessors.
+var declarationTargets = []string{targetFunction, targetProperty, ownerClass, ownerInterface, ownerObject, targetTypeAlias, targetGetter, targetSetter}
+
+func (c *Checker) checkModifiers(modifiers ast.Modifiers, target string) {
+	for _, modifier := range modifiers {
+		if !applicable(modifier.Spelling, target) {
+			c.report(modifier.Position, "Modifier '%s' is not applicable to '%s'", modifier.Spelling, target)
+		}
+	}
+}
+
+func applicable(modifier string, target string) bool {
+	for _, t := range modifierTargets[modifier] {
+		if t == target {
+			return true
+		}
+	}
+	return false
+}
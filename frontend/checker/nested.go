@@ -0,0 +1,57 @@
+package checker
+
+import (
+	"strings"
+
+	"gotlin/frontend/ast"
+)
+
+const modifierInner = "inner"
+
+// checkInner checks where a class declared in s may be declared given
+// whether it is inner, and reports whether its instances hold on to those of
+// their surroundings: inner classes hold the instance of the enclosing class
+// and local classes capture the function they are declared in.
+func (c *Checker) checkInner(decl *ast.ClassDeclStmt, s scope) bool {
+	inner, isInner := decl.Modifiers.Find(modifierInner)
+	switch {
+	case !isInner:
+		if s.inner && s.member() {
+			c.report(decl.Name.Position, "Nested class '%s' is not allowed here, use 'inner' keyword to make the class inner", decl.Name.Spelling)
+		}
+		return s.owner == ownerFunction
+	case s.owner == ownerFunction:
+		c.report(inner.Position, "Modifier 'inner' is not applicable to 'local class'")
+		return true
+	case s.owner == ownerFile:
+		c.report(inner.Position, "Modifier 'inner' is not applicable inside 'file'")
+		return false
+	case s.owner != ownerClass:
+		c.report(inner.Position, "Modifier 'inner' is not applicable inside '%s'", s.owner)
+		return false
+	}
+	return true
+}
+
+// checkQualifiedThis checks that `this@Label` names a receiver reachable
+// from s. An enclosing class whose instance is out of reach because a nested
+// class lies in between gets a more helpful message.
+func (c *Checker) checkQualifiedThis(this *ast.ThisExpr, s scope) {
+	label := this.Label.Spelling
+	for _, receiver := range s.receivers {
+		if receiver == label {
+			return
+		}
+	}
+	for class := s.class; class != ""; class = outerName(class) {
+		if simpleName(class) == label {
+			c.report(this.Label.Position, "Expression is inaccessible from a nested class '%s', use 'inner' keyword to make the class inner", s.receivers[0])
+			return
+		}
+	}
+	c.report(this.Label.Position, "Unresolved label")
+}
+
+func simpleName(name string) string {
+	return name[strings.LastIndex(name, ".")+1:]
+}
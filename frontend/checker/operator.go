@@ -0,0 +1,119 @@
+package checker
+
+import (
+	"strconv"
+	"strings"
+
+	"gotlin/frontend/ast"
+)
+
+const (
+	modifierOperator = "operator"
+
+	unbounded = -1
+)
+
+type operatorSignature struct {
+	minParams int
+	maxParams int
+	returns   string
+}
+
+// operatorSignatures lists the functions that may be declared `operator`,
+// with their arity and, where the language fixes it, their return type.
+var operatorSignatures = map[string]operatorSignature{
+	"unaryPlus":  {0, 0, ""},
+	"unaryMinus": {0, 0, ""},
+	"not":        {0, 0, ""},
+	"inc":        {0, 0, ""},
+	"dec":        {0, 0, ""},
+
+	"plus":       {1, 1, ""},
+	"minus":      {1, 1, ""},
+	"times":      {1, 1, ""},
+	"div":        {1, 1, ""},
+	"rem":        {1, 1, ""},
+	"rangeTo":    {1, 1, ""},
+	"rangeUntil": {1, 1, ""},
+
+	"plusAssign":  {1, 1, "Unit"},
+	"minusAssign": {1, 1, "Unit"},
+	"timesAssign": {1, 1, "Unit"},
+	"divAssign":   {1, 1, "Unit"},
+	"remAssign":   {1, 1, "Unit"},
+
+	"compareTo": {1, 1, "Int"},
+	"equals":    {1, 1, "Boolean"},
+	"contains":  {1, 1, "Boolean"},
+
+	"invoke": {0, unbounded, ""},
+	"get":    {1, unbounded, ""},
+	"set":    {2, unbounded, ""},
+
+	"iterator": {0, 0, ""},
+	"next":     {0, 0, ""},
+	"hasNext":  {0, 0, "Boolean"},
+
+	"getValue":        {2, 2, ""},
+	"setValue":        {3, 3, ""},
+	"provideDelegate": {2, 2, ""},
+}
+
+func lookupOperatorSignature(name string) (operatorSignature, bool) {
+	if signature, ok := operatorSignatures[name]; ok {
+		return signature, true
+	}
+
+	// component1, component2, ... back destructuring declarations
+	if suffix, ok := strings.CutPrefix(name, "component"); ok {
+		if n, err := strconv.Atoi(suffix); err == nil && n > 0 {
+			return operatorSignature{0, 0, ""}, true
+		}
+	}
+
+	return operatorSignature{}, false
+}
+
+func (c *Checker) checkOperatorFunction(decl *ast.FunctionDecl, s scope) {
+	const inapplicable = "'operator' modifier is inapplicable on this function: "
+	pos := decl.Name.Position
+
+	if !s.member() && decl.Receiver == nil {
+		c.report(pos, inapplicable+"must be a member or an extension function")
+		return
+	}
+
+	signature, ok := lookupOperatorSignature(decl.Name.Spelling)
+	if !ok {
+		c.report(pos, inapplicable+"'%s' is not an operator function name", decl.Name.Spelling)
+		return
+	}
+
+	params := len(decl.Parameters)
+	switch {
+	case signature.minParams == signature.maxParams && params != signature.minParams:
+		c.report(pos, inapplicable+"must have exactly %d value parameter(s), got %d", signature.minParams, params)
+	case params < signature.minParams:
+		c.report(pos, inapplicable+"must have at least %d value parameter(s), got %d", signature.minParams, params)
+	case signature.maxParams != unbounded && params > signature.maxParams:
+		c.report(pos, inapplicable+"must have at most %d value parameter(s), got %d", signature.maxParams, params)
+	}
+
+	if decl.Name.Spelling == "equals" && params == 1 && typeString(decl.Parameters[0].Type) != "Any?" {
+		c.report(pos, inapplicable+"must override 'equals(other: Any?)'")
+	}
+
+	if signature.returns == "" {
+		return
+	}
+
+	// An expression body without a declared type cannot be verified until
+	// return types are inferred.
+	if decl.Type == nil && decl.Body != nil && decl.Body.Expr != nil {
+		return
+	}
+
+	if returns := typeString(decl.Type); returns != signature.returns {
+		c.report(pos, inapplicable+"'%s' must return %s, not %s", decl.Name.Spelling, signature.returns, returns)
+	}
+}
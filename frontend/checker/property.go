@@ -0,0 +1,449 @@
+package checker
+
+import (
+	"strconv"
+	"strings"
+
+	"gotlin/frontend/ast"
+	"gotlin/frontend/token"
+)
+
+const (
+	modifierLateinit = "lateinit"
+	modifierConst    = "const"
+
+	targetGetter = "getter"
+	targetSetter = "setter"
+)
+
+// valueTypes are the types whose values are not references. They can be
+// the type of a `const val` but not of a `lateinit var`.
+var valueTypes = map[string]bool{
+	"Int":     true,
+	"Long":    true,
+	"Short":   true,
+	"Byte":    true,
+	"Double":  true,
+	"Float":   true,
+	"Char":    true,
+	"Boolean": true,
+}
+
+// visibilityRanks orders the visibilities from the least to the most
+// permissive.
+var visibilityRanks = map[string]int{
+	ast.Private:   0,
+	ast.Protected: 1,
+	ast.Internal:  2,
+	ast.Public:    3,
+}
+
+// checkAccessors checks the getter and setter of a property declared in s.
+func (c *Checker) checkAccessors(decl *ast.VariableDecl, s scope) {
+	hasThis := s.hasThis || decl.Receiver != nil
+	if getter := decl.Getter; getter != nil {
+		c.checkModifiers(getter.Modifiers, targetGetter)
+		if visibility, ok := getter.Modifiers.Visibility(); ok && visibility.Spelling != visibilityOf(decl.Modifiers) {
+			c.report(visibility.Position, "Getter visibility must be the same as property visibility")
+		}
+		c.checkFunctionBody(getter.Body, s.function("", hasThis))
+	}
+	if setter := decl.Setter; setter != nil {
+		c.checkModifiers(setter.Modifiers, targetSetter)
+		if decl.ReadOnly {
+			c.report(setter.Keyword.Position, "A 'val'-property cannot have a setter")
+		}
+		if visibility, ok := setter.Modifiers.Visibility(); ok && visibilityRanks[visibility.Spelling] > visibilityRanks[visibilityOf(decl.Modifiers)] {
+			c.report(visibility.Position, "Setter visibility must be the same or less permissive than property visibility")
+		}
+		if setter.Parameter != nil {
+			c.checkType(setter.Parameter.Type)
+		}
+		c.checkFunctionBody(setter.Body, s.function("", hasThis))
+	}
+
+	if decl.Receiver == nil && decl.Value != nil && !hasBackingField(decl) {
+		c.report(decl.Name.Position, "Initializer is not allowed here because this property has no backing field")
+	}
+	if needsInitializer(decl, s) {
+		if s.member() {
+			c.report(decl.Name.Position, "Property must be initialized or be abstract")
+		} else {
+			c.report(decl.Name.Position, "Property must be initialized")
+		}
+	}
+}
+
+// needsInitializer reports whether a property declared in s has a backing
+// field that nothing initializes. Local variables may be assigned later,
+// and the properties of interfaces are abstract.
+func needsInitializer(decl *ast.VariableDecl, s scope) bool {
+	switch {
+	case s.owner == ownerFunction, s.owner == ownerInterface:
+		return false
+	case decl.Value != nil, decl.Delegate != nil:
+		return false
+	case decl.Modifiers.Has(modifierLateinit), decl.Modifiers.Has(modifierAbstract):
+		return false
+	}
+	return hasBackingField(decl)
+}
+
+// hasBackingField reports whether a property stores its value: when one of
+// its accessors is the default one or refers to the value through `field`.
+func hasBackingField(decl *ast.VariableDecl) bool {
+	if decl.Receiver != nil {
+		return false
+	}
+	accessors := []*ast.PropertyAccessor{decl.Getter}
+	if !decl.ReadOnly {
+		accessors = append(accessors, decl.Setter)
+	}
+	for _, accessor := range accessors {
+		if accessor == nil || accessor.Body == nil || usesField(accessor.Body) {
+			return true
+		}
+	}
+	return false
+}
+
+func usesField(body *ast.FunctionBody) bool {
+	found := false
+	inspect := func(node any) bool {
+		if id, ok := node.(*ast.IdentifierExpr); ok && id.Value.Spelling == ast.BackingField {
+			found = true
+		}
+		return !found
+	}
+	if body.Expr != nil {
+		ast.Inspect(body.Expr, inspect)
+	}
+	for _, stmt := range body.Block {
+		ast.Inspect(stmt, inspect)
+	}
+	return found
+}
+
+func (c *Checker) checkLateinit(decl *ast.VariableDecl) {
+	lateinit, _ := decl.Modifiers.Find(modifierLateinit)
+	_, nullable := decl.Type.(*ast.NullableType)
+	switch {
+	case decl.ReadOnly:
+		c.report(lateinit.Position, "'lateinit' modifier is allowed only on mutable properties")
+	case decl.Receiver != nil:
+		c.report(lateinit.Position, "'lateinit' modifier is not allowed on extension properties")
+	case decl.Value != nil:
+		c.report(lateinit.Position, "'lateinit' modifier is not allowed on properties with initializer")
+	case decl.Delegate != nil:
+		c.report(lateinit.Position, "'lateinit' modifier is not allowed on delegated properties")
+	case decl.Getter != nil && decl.Getter.Body != nil || decl.Setter != nil && decl.Setter.Body != nil:
+		c.report(lateinit.Position, "'lateinit' modifier is not allowed on properties with a custom getter or setter")
+	case nullable:
+		c.report(lateinit.Position, "'lateinit' modifier is not allowed on properties of nullable types")
+	case valueTypes[typeString(c.expandType(decl.Type))]:
+		c.report(lateinit.Position, "'lateinit' modifier is not allowed on properties of primitive types")
+	}
+}
+
+// checkConst checks a `const val` declared in s, whose value must be known
+// at compile time.
+func (c *Checker) checkConst(decl *ast.VariableDecl, s scope) {
+	modifier, _ := decl.Modifiers.Find(modifierConst)
+	switch {
+	case !decl.ReadOnly:
+		c.report(modifier.Position, "Modifier 'const' is not applicable to 'vars'")
+		return
+	case s.owner != ownerFile && s.owner != ownerObject:
+		c.report(modifier.Position, "Const 'val' are only allowed on top level, in named objects, or in companion objects")
+		return
+	case decl.Getter != nil:
+		c.report(decl.Getter.Keyword.Position, "Const 'val' should not have a getter")
+		return
+	case decl.Delegate != nil:
+		c.report(decl.Name.Position, "Const 'val' should not have a delegate")
+		return
+	case decl.Value == nil:
+		c.report(decl.Name.Position, "Const 'val' should have an initializer")
+		return
+	}
+
+	if decl.Type != nil {
+		name := typeString(c.expandType(decl.Type))
+		if !valueTypes[name] && name != "String" {
+			c.report(decl.Name.Position, "Const 'val' has type '%s'. Only primitives and String are allowed", typeString(decl.Type))
+			return
+		}
+	}
+	if _, ok := c.constantValue(c.constants[qualify(s.class, decl.Name.Spelling)]); !ok {
+		c.report(decl.Name.Position, "Const 'val' initializer should be a constant value")
+	}
+}
+
+// constInfo is a `const val` of the package, folded on first use.
+type constInfo struct {
+	decl *ast.VariableDecl
+	// class is the qualified name of the object declaring the constant, if
+	// any, which names in its initializer are resolved from.
+	class   string
+	value   ast.Expr
+	folded  bool
+	folding bool
+}
+
+// collectConstants records the `const val` declarations among stmts, which
+// are the top-level declarations of a file or the members of the object
+// called class.
+func (c *Checker) collectConstants(stmts []ast.Stmt, class string) {
+	for _, stmt := range stmts {
+		decl, ok := stmt.(*ast.VariableDecl)
+		if ok && decl.ReadOnly && decl.Modifiers.Has(modifierConst) {
+			info := &constInfo{decl: decl, class: class}
+			c.constants[qualify(class, decl.Name.Spelling)] = info
+			c.constDecls[decl] = info
+		}
+	}
+}
+
+// constantRef returns the constant expr refers to, if any. A plain name
+// refers to the constant the resolver bound it to, so that a local
+// declaration shadows a constant of the same name.
+func (c *Checker) constantRef(expr ast.Expr, s scope) *constInfo {
+	switch e := expr.(type) {
+	case *ast.IdentifierExpr:
+		if e.Binding == nil {
+			return nil
+		}
+		if decl, ok := e.Binding.Symbol.Decl.(*ast.VariableDecl); ok {
+			return c.constDecls[decl]
+		}
+	case *ast.MemberExpr:
+		name, ok := qualifiedName(e.Receiver)
+		if !ok {
+			return nil
+		}
+		owner := c.resolveClass(name, s.class)
+		if owner == nil {
+			return nil
+		}
+		if info, ok := c.constants[qualify(owner.name, e.Name.Spelling)]; ok {
+			return info
+		}
+		if companion := c.companionOf(owner); companion != nil {
+			return c.constants[qualify(companion.name, e.Name.Spelling)]
+		}
+	}
+	return nil
+}
+
+// constantValue folds the initializer of a constant into a literal. It
+// fails for initializers that are not constant, including those that refer
+// back to the constant itself.
+func (c *Checker) constantValue(info *constInfo) (ast.Expr, bool) {
+	if info == nil || info.folding {
+		return nil, false
+	}
+	if !info.folded {
+		info.folding = true
+		info.value, _ = c.fold(info.decl.Value, scope{owner: ownerObject, class: info.class})
+		info.folding = false
+		info.folded = true
+	}
+	return info.value, info.value != nil
+}
+
+// fold evaluates a constant expression written in s into a literal.
+func (c *Checker) fold(expr ast.Expr, s scope) (ast.Expr, bool) {
+	switch e := expr.(type) {
+	case *ast.IntLiteral, *ast.DoubleLiteral, *ast.StringLiteral, *ast.BoolLiteral:
+		return e, true
+	case *ast.GroupingExpr:
+		return c.fold(e.Expr, s)
+	case *ast.IdentifierExpr, *ast.MemberExpr:
+		return c.constantValue(c.constantRef(e, s))
+	case *ast.UnaryExpr:
+		right, ok := c.fold(e.Right, s)
+		if !ok {
+			return nil, false
+		}
+		return foldUnary(e.Op.Kind, right)
+	case *ast.BinaryExpr:
+		left, ok := c.fold(e.Left, s)
+		if !ok {
+			return nil, false
+		}
+		right, ok := c.fold(e.Right, s)
+		if !ok {
+			return nil, false
+		}
+		return foldBinary(e.Op.Kind, left, right)
+	}
+	return nil, false
+}
+
+func foldUnary(op token.Kind, right ast.Expr) (ast.Expr, bool) {
+	switch r := right.(type) {
+	case *ast.IntLiteral:
+		switch op {
+		case token.DASH:
+			if isInt(r.Value) {
+				return &ast.IntLiteral{Value: int64(-int32(r.Value))}, true
+			}
+			return &ast.IntLiteral{Value: -r.Value}, true
+		case token.PLUS:
+			return r, true
+		}
+	case *ast.DoubleLiteral:
+		switch op {
+		case token.DASH:
+			return &ast.DoubleLiteral{Value: -r.Value}, true
+		case token.PLUS:
+			return r, true
+		}
+	case *ast.BoolLiteral:
+		if op == token.NOT {
+			return &ast.BoolLiteral{Value: !r.Value}, true
+		}
+	}
+	return nil, false
+}
+
+func foldBinary(op token.Kind, left ast.Expr, right ast.Expr) (ast.Expr, bool) {
+	if l, ok := left.(*ast.StringLiteral); ok && op == token.PLUS {
+		return &ast.StringLiteral{Value: l.Value + literalString(right)}, true
+	}
+
+	switch l := left.(type) {
+	case *ast.IntLiteral:
+		switch r := right.(type) {
+		case *ast.IntLiteral:
+			return foldInt(op, l.Value, r.Value)
+		case *ast.DoubleLiteral:
+			return foldDouble(op, float64(l.Value), r.Value)
+		}
+	case *ast.DoubleLiteral:
+		switch r := right.(type) {
+		case *ast.IntLiteral:
+			return foldDouble(op, l.Value, float64(r.Value))
+		case *ast.DoubleLiteral:
+			return foldDouble(op, l.Value, r.Value)
+		}
+	case *ast.BoolLiteral:
+		if r, ok := right.(*ast.BoolLiteral); ok {
+			switch op {
+			case token.AND:
+				return &ast.BoolLiteral{Value: l.Value && r.Value}, true
+			case token.OR:
+				return &ast.BoolLiteral{Value: l.Value || r.Value}, true
+			case token.EQ_EQ:
+				return &ast.BoolLiteral{Value: l.Value == r.Value}, true
+			case token.NOT_EQ:
+				return &ast.BoolLiteral{Value: l.Value != r.Value}, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// foldInt folds an operation on integers. Operations on Int values wrap
+// around at 32 bits, as they do when evaluated; a literal too large for an
+// Int is a Long. Division by zero is not constant: it throws when
+// evaluated.
+func foldInt(op token.Kind, l int64, r int64) (ast.Expr, bool) {
+	var value int64
+	switch op {
+	case token.PLUS:
+		value = l + r
+	case token.DASH:
+		value = l - r
+	case token.STAR:
+		value = l * r
+	case token.SLASH, token.PERCENT:
+		if r == 0 {
+			return nil, false
+		}
+		if op == token.SLASH {
+			value = l / r
+		} else {
+			value = l % r
+		}
+	default:
+		return compare(op, l < r, l == r, l > r)
+	}
+	if isInt(l) && isInt(r) {
+		value = int64(int32(value))
+	}
+	return &ast.IntLiteral{Value: value}, true
+}
+
+// isInt reports whether an integer constant is an Int rather than a Long.
+func isInt(value int64) bool {
+	return value == int64(int32(value))
+}
+
+func foldDouble(op token.Kind, l float64, r float64) (ast.Expr, bool) {
+	switch op {
+	case token.PLUS:
+		return &ast.DoubleLiteral{Value: l + r}, true
+	case token.DASH:
+		return &ast.DoubleLiteral{Value: l - r}, true
+	case token.STAR:
+		return &ast.DoubleLiteral{Value: l * r}, true
+	case token.SLASH:
+		return &ast.DoubleLiteral{Value: l / r}, true
+	}
+	return compare(op, l < r, l == r, l > r)
+}
+
+// compare folds a comparison of two numbers given how they compare, which
+// is none of the three ways when one is NaN.
+func compare(op token.Kind, less bool, equal bool, greater bool) (ast.Expr, bool) {
+	switch op {
+	case token.LT:
+		return &ast.BoolLiteral{Value: less}, true
+	case token.LTE:
+		return &ast.BoolLiteral{Value: less || equal}, true
+	case token.GT:
+		return &ast.BoolLiteral{Value: greater}, true
+	case token.GTE:
+		return &ast.BoolLiteral{Value: greater || equal}, true
+	case token.EQ_EQ:
+		return &ast.BoolLiteral{Value: equal}, true
+	case token.NOT_EQ:
+		return &ast.BoolLiteral{Value: !equal}, true
+	}
+	return nil, false
+}
+
+// literalString spells a literal the way string concatenation does.
+func literalString(literal ast.Expr) string {
+	switch l := literal.(type) {
+	case *ast.StringLiteral:
+		return l.Value
+	case *ast.IntLiteral:
+		return strconv.FormatInt(l.Value, 10)
+	case *ast.DoubleLiteral:
+		spelled := strconv.FormatFloat(l.Value, 'f', -1, 64)
+		if !strings.ContainsAny(spelled, ".NI") {
+			spelled += ".0"
+		}
+		return spelled
+	case *ast.BoolLiteral:
+		return strconv.FormatBool(l.Value)
+	}
+	return ""
+}
+
+// inlineConstant records on a reference to a constant the literal it
+// stands for.
+func (c *Checker) inlineConstant(expr ast.Expr, s scope) {
+	value, ok := c.constantValue(c.constantRef(expr, s))
+	if !ok {
+		return
+	}
+	switch e := expr.(type) {
+	case *ast.IdentifierExpr:
+		e.Constant = value
+	case *ast.MemberExpr:
+		e.Constant = value
+	}
+}
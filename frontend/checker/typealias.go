@@ -0,0 +1,144 @@
+package checker
+
+import (
+	"gotlin/frontend/ast"
+	"gotlin/frontend/token"
+)
+
+func (c *Checker) checkTypeAlias(decl *ast.TypeAliasDecl, s scope) {
+	c.checkAnnotations(decl.Annotations)
+	c.checkModifiers(decl.Modifiers, targetTypeAlias)
+	c.checkVisibility(decl.Modifiers, targetTypeAlias, s)
+	if s.owner != ownerFile {
+		c.report(decl.Name.Position, "Nested and local type aliases are not supported")
+		return
+	}
+
+	name := decl.Name.Spelling
+	if _, recursive := c.expand(decl.Type, map[string]bool{name: true}); recursive {
+		c.report(decl.Name.Position, "Recursive type alias in expansion: %s", name)
+		return
+	}
+	c.checkType(decl.Type)
+}
+
+func (c *Checker) checkParameters(params []*ast.ParameterWithOptionalType) {
+	for _, param := range params {
+		c.checkAnnotations(param.Annotations)
+		c.checkType(param.Type)
+	}
+}
+
+// checkType checks the type arguments given to the type aliases t uses.
+func (c *Checker) checkType(t ast.Type) {
+	switch tp := t.(type) {
+	case *ast.TypeName:
+		c.checkTopLevelAccess(tp.Name, tp.Position)
+		if alias, ok := c.aliases[tp.Name]; ok && len(tp.Args) != len(alias.TypeParams) {
+			c.report(tp.Position, "%d type arguments expected for %s, but %d were given", len(alias.TypeParams), tp.Name, len(tp.Args))
+		}
+		for _, arg := range tp.Args {
+			c.checkType(arg)
+		}
+	case *ast.NullableType:
+		c.checkType(tp.Type)
+	case *ast.ArrayType:
+		c.checkType(tp.Underlying)
+	case *ast.FunctionType:
+		for _, param := range tp.Params {
+			c.checkType(param)
+		}
+		c.checkType(tp.Return)
+	}
+}
+
+// expandType replaces the type aliases t uses with the types they stand
+// for. Checks relate expanded types but report them as written, so
+// messages show the alias name rather than its expansion.
+func (c *Checker) expandType(t ast.Type) ast.Type {
+	expanded, _ := c.expand(t, make(map[string]bool))
+	return expanded
+}
+
+// expand expands t, reporting whether it ran into an alias whose
+// expansion refers back to itself. seen holds the aliases being expanded.
+func (c *Checker) expand(t ast.Type, seen map[string]bool) (ast.Type, bool) {
+	switch tp := t.(type) {
+	case *ast.TypeName:
+		args := make([]ast.Type, len(tp.Args))
+		recursive := false
+		for i, arg := range tp.Args {
+			var r bool
+			args[i], r = c.expand(arg, seen)
+			recursive = recursive || r
+		}
+
+		alias, ok := c.aliases[tp.Name]
+		if !ok {
+			return &ast.TypeName{Name: tp.Name, Args: args, Position: tp.Position}, recursive
+		}
+		if seen[tp.Name] || recursive {
+			return tp, true
+		}
+		if len(args) != len(alias.TypeParams) {
+			return tp, false
+		}
+
+		seen[tp.Name] = true
+		defer delete(seen, tp.Name)
+		expanded, r := c.expand(substitute(alias.Type, alias.TypeParams, args), seen)
+		return expanded, r
+	case *ast.NullableType:
+		inner, recursive := c.expand(tp.Type, seen)
+		if _, ok := inner.(*ast.NullableType); ok {
+			return inner, recursive
+		}
+		return &ast.NullableType{Type: inner}, recursive
+	case *ast.ArrayType:
+		inner, recursive := c.expand(tp.Underlying, seen)
+		return &ast.ArrayType{Underlying: inner}, recursive
+	case *ast.FunctionType:
+		fn := &ast.FunctionType{Params: make([]ast.Type, len(tp.Params))}
+		recursive := false
+		for i, param := range tp.Params {
+			var r bool
+			fn.Params[i], r = c.expand(param, seen)
+			recursive = recursive || r
+		}
+		var r bool
+		fn.Return, r = c.expand(tp.Return, seen)
+		return fn, recursive || r
+	default:
+		return t, false
+	}
+}
+
+// substitute replaces the type parameters of an alias with its arguments.
+func substitute(t ast.Type, params []token.Token, args []ast.Type) ast.Type {
+	switch tp := t.(type) {
+	case *ast.TypeName:
+		for i, param := range params {
+			if tp.Name == param.Spelling && len(tp.Args) == 0 {
+				return args[i]
+			}
+		}
+		substituted := &ast.TypeName{Name: tp.Name, Args: make([]ast.Type, len(tp.Args)), Position: tp.Position}
+		for i, arg := range tp.Args {
+			substituted.Args[i] = substitute(arg, params, args)
+		}
+		return substituted
+	case *ast.NullableType:
+		return &ast.NullableType{Type: substitute(tp.Type, params, args)}
+	case *ast.ArrayType:
+		return &ast.ArrayType{Underlying: substitute(tp.Underlying, params, args)}
+	case *ast.FunctionType:
+		fn := &ast.FunctionType{Params: make([]ast.Type, len(tp.Params))}
+		for i, param := range tp.Params {
+			fn.Params[i] = substitute(param, params, args)
+		}
+		fn.Return = substitute(tp.Return, params, args)
+		return fn
+	default:
+		return t
+	}
+}
@@ -0,0 +1,81 @@
+package checker
+
+import (
+	"gotlin/frontend/ast"
+)
+
+const (
+	modifierValue = "value"
+
+	annotationJvmInline = "JvmInline"
+)
+
+// checkValueClass checks a value class declared in s. Its instances are
+// represented by the value of its single property, so it may hold no other
+// state and cannot take part in a class hierarchy. Value classes are only
+// checked statically: nothing compiles them yet, so that representation
+// exists only as object.Boxed.
+func (c *Checker) checkValueClass(decl *ast.ClassDeclStmt, s scope) {
+	value, ok := decl.Modifiers.Find(modifierValue)
+	if !ok || decl.Interface {
+		return
+	}
+
+	for _, incompatible := range []string{modifierData, modifierEnum, modifierSealed} {
+		if modifier, ok := decl.Modifiers.Find(incompatible); ok {
+			c.report(modifier.Position, "Modifier '%s' is incompatible with '%s'", modifier.Spelling, value.Spelling)
+		}
+	}
+	if !decl.Annotations.Has(annotationJvmInline) {
+		c.report(value.Position, "Value classes without @JvmInline annotation are not yet supported")
+	}
+	if s.owner == ownerFunction || decl.Modifiers.Has(modifierInner) {
+		c.report(decl.Name.Position, "Value class cannot be local or inner")
+	}
+
+	if decl.PrimaryConstructor == nil || len(decl.PrimaryConstructor.Parameters) != 1 {
+		c.report(decl.Name.Position, "Value class must have exactly one primary constructor parameter")
+	} else if param := decl.PrimaryConstructor.Parameters[0]; !param.Property || !param.ReadOnly {
+		c.report(param.Position, "Value class primary constructor must only have final read-only (val) property parameter")
+	} else if c.recursiveValueClass(c.classes[qualify(s.class, decl.Name.Spelling)]) {
+		c.report(param.Position, "Value class cannot be recursive")
+	}
+
+	for _, superType := range decl.SuperTypes {
+		if superType.Call {
+			c.report(decl.Name.Position, "Value class cannot extend classes")
+		}
+	}
+	for _, member := range decl.Members {
+		property, ok := member.(*ast.VariableDecl)
+		switch {
+		case !ok || property.Receiver != nil:
+		case property.Delegate != nil:
+			c.report(property.Name.Position, "Value class cannot have delegated properties")
+		case hasBackingField(property):
+			c.report(property.Name.Position, "Value class cannot have properties with backing fields")
+		}
+	}
+}
+
+// recursiveValueClass reports whether the underlying type of a value class
+// leads back to it through the underlying types of other value classes.
+func (c *Checker) recursiveValueClass(info *classInfo) bool {
+	seen := make(map[*classInfo]bool)
+	for current := info; current != nil && current.underlying != nil; {
+		name, ok := c.expandType(current.underlying).(*ast.TypeName)
+		if !ok {
+			return false
+		}
+		next := c.resolveClass(name.Name, current.outer)
+		if next == info {
+			return true
+		}
+		if seen[next] {
+			return false
+		}
+		seen[next] = true
+		current = next
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package checker
+
+import (
+	"strings"
+
+	"gotlin/frontend/ast"
+	"gotlin/frontend/token"
+)
+
+// topLevelDecl describes a top-level declaration of the package.
+type topLevelDecl struct {
+	visibility string
+	pos        token.Pos
+	file       int
+}
+
+func visibilityOf(modifiers ast.Modifiers) string {
+	visibility, ok := modifiers.Visibility()
+	if !ok {
+		return ast.Public
+	}
+	return visibility.Spelling
+}
+
+// collectTopLevel records the top-level declarations of the file being
+// checked.
+func (c *Checker) collectTopLevel(stmts []ast.Stmt) {
+	for _, stmt := range stmts {
+		visibility := visibilityOf(ast.DeclarationModifiers(stmt))
+		for _, name := range ast.DeclaredNames(stmt) {
+			c.topLevel[name.Spelling] = append(c.topLevel[name.Spelling], topLevelDecl{
+				visibility: visibility,
+				pos:        name.Position,
+				file:       c.file,
+			})
+		}
+	}
+}
+
+// checkVisibility reports the visibility modifiers of a declaration of kind
+// target that do not fit where it is declared.
+func (c *Checker) checkVisibility(modifiers ast.Modifiers, target string, s scope) {
+	var first token.Token
+	for _, modifier := range modifiers {
+		if !ast.IsVisibility(modifier.Spelling) {
+			continue
+		}
+		if first.Spelling != "" {
+			c.report(modifier.Position, "Modifier '%s' is incompatible with '%s'", modifier.Spelling, first.Spelling)
+			continue
+		}
+		first = modifier
+
+		switch {
+		case s.owner == ownerFunction:
+			c.report(modifier.Position, "Modifier '%s' is not applicable to 'local %s'", modifier.Spelling, target)
+		case modifier.Spelling == ast.Protected && !s.member():
+			c.report(modifier.Position, "Modifier '%s' is not applicable inside 'file'", modifier.Spelling)
+		}
+	}
+}
+
+// checkParamModifiers checks the modifiers of a primary constructor
+// parameter of the class whose body has scope s.
+func (c *Checker) checkParamModifiers(param ast.ClassParam, s scope) {
+	c.checkModifiers(param.Modifiers, targetProperty)
+	c.checkVisibility(param.Modifiers, targetProperty, s)
+}
+
+// checkTopLevelAccess reports a reference to a top-level declaration that
+// is private to another file of the package.
+func (c *Checker) checkTopLevelAccess(name string, pos token.Pos) {
+	decls := c.topLevel[name]
+	for _, decl := range decls {
+		if decl.file == c.file || decl.visibility != ast.Private {
+			return
+		}
+	}
+	if len(decls) > 0 {
+		c.report(pos, "Cannot access '%s': it is private in file (declared at %s)", name, decls[0].pos)
+	}
+}
+
+func (c *Checker) companionOf(info *classInfo) *classInfo {
+	if info == nil {
+		return nil
+	}
+	for _, candidate := range c.declared {
+		if candidate.companion && candidate.outer == info.name {
+			return candidate
+		}
+	}
+	return nil
+}
+
+func outerName(name string) string {
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		return name[:i]
+	}
+	return ""
+}
@@ -0,0 +1,36 @@
+package checker
+
+import (
+	"gotlin/frontend/ast"
+)
+
+// checkWhen checks a `when`. Whether it covers every value of its subject
+// depends on the type of the subject, which the type checker knows.
+func (c *Checker) checkWhen(when *ast.WhenExpr, s scope) {
+	if when.Subject != nil {
+		c.checkExpr(when.Subject, s)
+	}
+
+	for i, branch := range when.Branches {
+		if branch.Else && i != len(when.Branches)-1 {
+			c.report(when.When.Position, "'else' entry must be the last one in a when-expression")
+		}
+		for _, condition := range branch.Conditions {
+			c.checkExpr(condition.Expr, s)
+			c.checkType(condition.Type)
+		}
+		c.checkStmt(branch.Body, s.block())
+	}
+}
+
+// checkIf checks an `if`. Used as an expression it must have an else
+// branch.
+func (c *Checker) checkIf(ifExpr *ast.IfExpr, s scope, asExpression bool) {
+	c.checkExpr(ifExpr.Condition, s)
+	c.checkStmt(ifExpr.Then, s.block())
+	if ifExpr.Else != nil {
+		c.checkStmt(ifExpr.Else, s.block())
+	} else if asExpression {
+		c.report(ifExpr.If.Position, "'if' must have both main and 'else' branches if used as an expression")
+	}
+}
@@ -0,0 +1,29 @@
+// Package diag holds the diagnostics the frontend passes report about
+// source code.
+package diag
+
+import (
+	"fmt"
+
+	"gotlin/frontend/token"
+)
+
+// Error is a diagnostic located in a source file. File is the path of the
+// file, empty when the code checked was not loaded from one.
+type Error struct {
+	File    string
+	Pos     token.Pos
+	Message string
+}
+
+// Errorf returns the diagnostic at pos with the formatted message.
+func Errorf(pos token.Pos, format string, args ...any) *Error {
+	return &Error{Pos: pos, Message: fmt.Sprintf(format, args...)}
+}
+
+func (e *Error) Error() string {
+	if e.File == "" {
+		return fmt.Sprintf("%s %s", e.Pos, e.Message)
+	}
+	return fmt.Sprintf("%s: %s %s", e.File, e.Pos, e.Message)
+}
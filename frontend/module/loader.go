@@ -0,0 +1,346 @@
+// Package module loads programs spread over several files and packages.
+// Packages map to directories below a source root, so `package com.acme.util`
+// lives in com/acme/util, and every file of a directory belongs to the same
+// package.
+package module
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gotlin/frontend/ast"
+	"gotlin/frontend/diag"
+	"gotlin/frontend/parser"
+	"gotlin/frontend/scanner"
+	"gotlin/frontend/token"
+	"gotlin/frontend/types"
+)
+
+// SourceExtension is the extension of the source files a package is made of.
+const SourceExtension = ".gt"
+
+// File is a parsed source file.
+type File struct {
+	Path    string
+	Program *ast.Program
+}
+
+// Package is the set of files sharing a package name. Their top-level
+// declarations are visible to each other without imports.
+type Package struct {
+	Name  string
+	Files []*File
+	// Declarations holds the top-level declarations by name. Functions may
+	// be overloaded, so a name can have several.
+	Declarations map[string][]ast.Stmt
+	// Imports are the packages the files import from.
+	Imports []*Package
+}
+
+// Programs returns the parsed files of the package.
+func (p *Package) Programs() []*ast.Program {
+	programs := make([]*ast.Program, len(p.Files))
+	for i, file := range p.Files {
+		programs[i] = file.Program
+	}
+	return programs
+}
+
+// Module is a loaded program: the entry package and everything it imports.
+type Module struct {
+	Entry *Package
+	// Packages lists every loaded package after the packages it imports.
+	Packages []*Package
+}
+
+// Exports returns the names each loaded package declares at its top level
+// and other packages can import: those with a declaration that is not
+// private to its file.
+func (m *Module) Exports() map[string][]string {
+	exports := make(map[string][]string, len(m.Packages))
+	for _, pkg := range m.Packages {
+		names := make([]string, 0, len(pkg.Declarations))
+		for name, decls := range pkg.Declarations {
+			for _, decl := range decls {
+				if !ast.DeclarationModifiers(decl).IsPrivate() {
+					names = append(names, name)
+					break
+				}
+			}
+		}
+		sort.Strings(names)
+		exports[pkg.Name] = names
+	}
+	return exports
+}
+
+// Loader resolves packages against a source root.
+type Loader struct {
+	root     fs.FS
+	packages map[string]*Package
+	// loading is the chain of packages whose imports are being resolved,
+	// used to detect import cycles.
+	loading []string
+	order   []*Package
+	errors  []error
+}
+
+func NewLoader(root fs.FS) *Loader {
+	return &Loader{root: root}
+}
+
+// SourceRoot locates the source root of the entry file at the given OS
+// path: the directory its package path is relative to, so that the entry
+// of `package com.acme` in src/com/acme has the root src. It returns the
+// root and the entry's path below it. An entry that cannot be read, or
+// whose directory does not end with its package path, is taken to sit in
+// the root package, where Load reports the problem.
+func SourceRoot(entry string) (fs.FS, string) {
+	if abs, err := filepath.Abs(entry); err == nil {
+		entry = abs
+	}
+	dir, base := filepath.Split(filepath.Clean(entry))
+	dir = filepath.Clean(dir)
+	program, err := NewLoader(os.DirFS(dir)).parseFile(base)
+	if err != nil || program.Package == nil {
+		return os.DirFS(dir), base
+	}
+	pkgDir := filepath.FromSlash(packageDir(program.Package.Name))
+	if dir != pkgDir && !strings.HasSuffix(dir, string(filepath.Separator)+pkgDir) {
+		return os.DirFS(dir), base
+	}
+	root := "."
+	if prefix := dir[:len(dir)-len(pkgDir)]; prefix != "" {
+		root = filepath.Clean(prefix)
+	}
+	return os.DirFS(root), path.Join(packageDir(program.Package.Name), base)
+}
+
+// Load loads the package of the entry file, found below the source root,
+// along with every package it imports, and nothing else. Problems are
+// collected rather than stopping the load at the first one.
+func (l *Loader) Load(entry string) (*Module, []error) {
+	l.packages = make(map[string]*Package)
+	l.loading = nil
+	l.order = nil
+	l.errors = nil
+
+	if _, err := fs.Stat(l.root, entry); err != nil {
+		l.errors = append(l.errors, fmt.Errorf("entry %s: %w", entry, err))
+		return &Module{}, l.errors
+	}
+	name := packageName(path.Dir(entry))
+	pkg := l.loadPackage(name, entry, token.Pos{})
+	return &Module{Entry: pkg, Packages: l.order}, l.errors
+}
+
+func (l *Loader) report(file string, pos token.Pos, format string, args ...any) {
+	err := diag.Errorf(pos, format, args...)
+	err.File = file
+	l.errors = append(l.errors, err)
+}
+
+func packageName(dir string) string {
+	if dir == "." {
+		return ""
+	}
+	return strings.ReplaceAll(dir, "/", ".")
+}
+
+func packageDir(name string) string {
+	if name == "" {
+		return "."
+	}
+	return strings.ReplaceAll(name, ".", "/")
+}
+
+// loadPackage loads the package called name unless it already is. from and
+// pos locate the import that requires it, for error messages.
+func (l *Loader) loadPackage(name string, from string, pos token.Pos) *Package {
+	for i, loading := range l.loading {
+		if loading == name {
+			cycle := append(append([]string{}, l.loading[i:]...), name)
+			l.report(from, pos, "Cyclic import: %s", strings.Join(cycle, " -> "))
+			return l.packages[name]
+		}
+	}
+	if pkg, ok := l.packages[name]; ok {
+		return pkg
+	}
+
+	pkg := &Package{Name: name, Declarations: make(map[string][]ast.Stmt)}
+	l.packages[name] = pkg
+	l.loading = append(l.loading, name)
+	defer func() { l.loading = l.loading[:len(l.loading)-1] }()
+
+	if !l.parsePackage(pkg) {
+		return pkg
+	}
+	l.collectDeclarations(pkg)
+	l.resolveImports(pkg)
+	l.order = append(l.order, pkg)
+	return pkg
+}
+
+func (l *Loader) parsePackage(pkg *Package) bool {
+	dir := packageDir(pkg.Name)
+	entries, err := fs.ReadDir(l.root, dir)
+	if err != nil {
+		l.errors = append(l.errors, fmt.Errorf("package %s: %w", pkg.Name, err))
+		return false
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != SourceExtension {
+			continue
+		}
+		file := path.Join(dir, entry.Name())
+		program, err := l.parseFile(file)
+		if err != nil {
+			l.errors = append(l.errors, fmt.Errorf("%s: %w", file, err))
+			continue
+		}
+
+		declared := ""
+		if program.Package != nil {
+			declared = program.Package.Name
+		}
+		if declared != pkg.Name {
+			var pos token.Pos
+			if program.Package != nil {
+				pos = program.Package.Package.Position
+			}
+			l.report(file, pos, "Package directive '%s' does not match the file location '%s'", declared, dir)
+			continue
+		}
+		pkg.Files = append(pkg.Files, &File{Path: file, Program: program})
+	}
+	return true
+}
+
+// parseFile parses a source file. The parser panics on syntax errors, which
+// are turned back into errors here, and records those it recovers from.
+func (l *Loader) parseFile(file string) (program *ast.Program, err error) {
+	f, err := l.root.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	defer func() {
+		if r := recover(); r != nil {
+			if e, ok := r.(error); ok {
+				err = e
+				return
+			}
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	p := parser.New(scanner.NewScanner(f))
+	program = p.Parse()
+	if errs := p.Errors(); len(errs) > 0 {
+		return nil, errs[0]
+	}
+	program.File = file
+	return program, nil
+}
+
+// collectDeclarations records the top-level declarations of every file of
+// pkg and reports the names declared twice, other than overloaded
+// functions.
+func (l *Loader) collectDeclarations(pkg *Package) {
+	for _, file := range pkg.Files {
+		for _, stmt := range file.Program.Statements {
+			for _, name := range ast.DeclaredNames(stmt) {
+				for _, previous := range pkg.Declarations[name.Spelling] {
+					if !isFunction(stmt) || !isFunction(previous) {
+						l.report(file.Path, name.Position, "Redeclaration: %s", name.Spelling)
+						break
+					}
+				}
+				pkg.Declarations[name.Spelling] = append(pkg.Declarations[name.Spelling], stmt)
+			}
+		}
+	}
+}
+
+func isFunction(stmt ast.Stmt) bool {
+	_, ok := stmt.(*ast.FunctionDecl)
+	return ok
+}
+
+// resolveImports loads the packages pkg imports from and checks that every
+// imported name is declared in them.
+func (l *Loader) resolveImports(pkg *Package) {
+	imported := make(map[*Package]bool)
+	for _, file := range pkg.Files {
+		for _, directive := range file.Program.Imports {
+			target, name := l.importTarget(directive)
+			if types.IsStandardPackage(target) {
+				if _, ok := types.LookupBuiltin(name); name != "" && !ok {
+					l.report(file.Path, directive.Import.Position, "Unresolved reference: %s", name)
+				}
+				continue
+			}
+			dep := pkg
+			if target != pkg.Name {
+				dep = l.loadPackage(target, file.Path, directive.Import.Position)
+			}
+			if name != "" && len(dep.Declarations[name]) == 0 && len(dep.Files) > 0 {
+				l.report(file.Path, directive.Import.Position, "Unresolved reference: %s", name)
+			}
+			if name != "" {
+				l.checkImportAccess(file, dep, name, directive.Import.Position)
+			}
+			if dep == pkg {
+				continue
+			}
+			if !imported[dep] {
+				imported[dep] = true
+				pkg.Imports = append(pkg.Imports, dep)
+			}
+		}
+	}
+	sort.Slice(pkg.Imports, func(i, j int) bool { return pkg.Imports[i].Name < pkg.Imports[j].Name })
+}
+
+// checkImportAccess reports an import of name from dep when every
+// declaration of the name is private to another file. Everything a Loader
+// loads forms one module, so internal declarations can always be imported.
+func (l *Loader) checkImportAccess(from *File, dep *Package, name string, pos token.Pos) {
+	var site *File
+	var declared token.Pos
+	for _, file := range dep.Files {
+		for _, stmt := range file.Program.Statements {
+			for _, declName := range ast.DeclaredNames(stmt) {
+				if declName.Spelling != name {
+					continue
+				}
+				if file == from || !ast.DeclarationModifiers(stmt).IsPrivate() {
+					return
+				}
+				if site == nil {
+					site, declared = file, declName.Position
+				}
+			}
+		}
+	}
+	if site != nil {
+		l.report(from.Path, pos, "Cannot access '%s': it is private in file (declared at %s %s)", name, site.Path, declared)
+	}
+}
+
+// importTarget splits an import into the package it imports from and the
+// declaration it imports, which is empty for `import pkg.*`.
+func (l *Loader) importTarget(directive *ast.ImportDirective) (string, string) {
+	if directive.All {
+		return directive.Path, ""
+	}
+	pkg := directive.Package()
+	return pkg, strings.TrimPrefix(directive.Path[len(pkg):], ".")
+}
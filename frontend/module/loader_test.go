@@ -0,0 +1,165 @@
+package module
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func load(t *testing.T, files map[string]string, entry string) (*Module, []error) {
+	t.Helper()
+	root := fstest.MapFS{}
+	for name, source := range files {
+		root[name] = &fstest.MapFile{Data: []byte(source)}
+	}
+	return NewLoader(root).Load(entry)
+}
+
+func TestLoader_Load(t *testing.T) {
+	module, errs := load(t, map[string]string{
+		"main.gt": `import com.acme.util.*
+import com.acme.text.Strings as S
+val greeting = S.upper(shout())
+`,
+		"helpers.gt": `fun shout() = 1
+`,
+		"com/acme/util/math.gt": `package com.acme.util
+fun twice(n: Int) = n + n
+fun twice(n: Long) = n + n
+`,
+		"com/acme/util/names.gt": `package com.acme.util
+val name = twice(2)
+private fun helper() = 0
+`,
+		"com/acme/text/strings.gt": `package com.acme.text
+import com.acme.util.twice
+object Strings
+`,
+		"com/acme/text/notes.txt": "not a source file",
+		"com/acme/unused/broken.gt": `package com.acme.unused
+val = 1
+`,
+	}, "main.gt")
+
+	if len(errs) != 0 {
+		t.Fatalf("Load() reported %v", errs)
+	}
+	if len(module.Entry.Files) != 2 {
+		t.Errorf("entry package has %d files, want 2", len(module.Entry.Files))
+	}
+
+	var order []string
+	for _, pkg := range module.Packages {
+		order = append(order, pkg.Name)
+	}
+	if got := strings.Join(order, ", "); got != "com.acme.util, com.acme.text, " {
+		t.Errorf("packages load in order %q, want dependencies first", got)
+	}
+
+	util := module.Packages[0]
+	if len(util.Declarations["twice"]) != 2 || len(util.Declarations["name"]) != 1 {
+		t.Errorf("com.acme.util declares %v", util.Declarations)
+	}
+	if got := strings.Join(module.Exports()["com.acme.util"], ", "); got != "name, twice" {
+		t.Errorf("com.acme.util exports %q, want \"name, twice\"", got)
+	}
+	if len(module.Entry.Imports) != 2 {
+		t.Errorf("entry package imports %d packages, want 2", len(module.Entry.Imports))
+	}
+}
+
+func TestLoader_Errors(t *testing.T) {
+	tests := []struct {
+		files  map[string]string
+		errors []string
+	}{
+		{map[string]string{
+			"main.gt": "import a.*\n",
+			"a/a.gt":  "package a\nimport b.*\n",
+			"b/b.gt":  "package b\nimport a.*\n",
+		}, []string{"b/b.gt: [2, 1] Cyclic import: a -> b -> a"}},
+		{map[string]string{
+			"main.gt": "import a.Missing\n",
+			"a/a.gt":  "package a\nclass Present\n",
+		}, []string{"main.gt: [1, 1] Unresolved reference: Missing"}},
+		{map[string]string{
+			"main.gt":  "class Point\n",
+			"other.gt": "val Point = 1\n",
+		}, []string{"other.gt: [1, 5] Redeclaration: Point"}},
+		{map[string]string{
+			"main.gt":  "val x = 1\n",
+			"other.gt": "package misplaced\n",
+		}, []string{"Package directive 'misplaced' does not match the file location '.'"}},
+		{map[string]string{
+			"main.gt": "import nowhere.*\n",
+		}, []string{"package nowhere"}},
+		{map[string]string{
+			"main.gt": "import kotlin.properties.Delegates\nimport kotlin.collections.*\nimport kotlin.properties.Missing\n",
+		}, []string{"main.gt: [3, 1] Unresolved reference: Missing"}},
+		{map[string]string{
+			"main.gt": "import a.secret\nimport a.shared\n",
+			"a/a.gt":  "package a\nprivate val secret = 1\ninternal val shared = 2\n",
+		}, []string{"main.gt: [1, 1] Cannot access 'secret': it is private in file (declared at a/a.gt [2, 13])"}},
+		{map[string]string{
+			"main.gt": "val = 1\n",
+		}, []string{"main.gt: expected one of [<identifier>]"}},
+		{map[string]string{
+			"other.gt": "val x = 1\n",
+		}, []string{"entry main.gt"}},
+	}
+
+	for _, test := range tests {
+		_, errs := load(t, test.files, "main.gt")
+		if len(errs) != len(test.errors) {
+			t.Errorf("%v: got errors %v, want %v", test.files, errs, test.errors)
+			continue
+		}
+		for i, err := range errs {
+			if !strings.Contains(err.Error(), test.errors[i]) {
+				t.Errorf("error %q does not mention %q", err, test.errors[i])
+			}
+		}
+	}
+}
+
+func TestSourceRoot(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"src/com/acme/App.gt":   "package com.acme\n",
+		"src/Main.gt":           "val x = 1\n",
+		"src/misplaced/Misc.gt": "package com.acme\n",
+	}
+	for name, source := range files {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(source), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		entry string
+		file  string
+	}{
+		{"src/com/acme/App.gt", "com/acme/App.gt"},
+		{"src/Main.gt", "Main.gt"},
+		{"src/misplaced/Misc.gt", "Misc.gt"},
+		{"src/Missing.gt", "Missing.gt"},
+	}
+
+	for _, test := range tests {
+		root, file := SourceRoot(filepath.Join(dir, filepath.FromSlash(test.entry)))
+		if file != test.file {
+			t.Errorf("%s: entry is %q below the root, want %q", test.entry, file, test.file)
+			continue
+		}
+		if _, err := fs.Stat(root, file); err != nil && test.entry != "src/Missing.gt" {
+			t.Errorf("%s: %v", test.entry, err)
+		}
+	}
+}
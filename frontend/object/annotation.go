@@ -0,0 +1,36 @@
+package object
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Annotation is the runtime view of an annotation, as returned when a
+// script reflects on an annotated class.
+type Annotation struct {
+	Name string
+	Args []Object
+}
+
+func (a *Annotation) Type() Type { return Type(a.Name) }
+
+func (a *Annotation) Inspect() string {
+	if len(a.Args) == 0 {
+		return "@" + a.Name
+	}
+	args := make([]string, len(a.Args))
+	for i, arg := range a.Args {
+		args[i] = arg.Inspect()
+	}
+	return fmt.Sprintf("@%s(%s)", a.Name, strings.Join(args, ", "))
+}
+
+// FindAnnotation returns the annotation of class c called name.
+func (c *Class) FindAnnotation(name string) (*Annotation, bool) {
+	for _, annotation := range c.Annotations {
+		if annotation.Name == name {
+			return annotation, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,53 @@
+package object
+
+import "fmt"
+
+// Divide implements `/` on integral values. Division by zero throws an
+// ArithmeticException rather than producing a value.
+func Divide(left Object, right Object) (Object, error) {
+	return integral("/", left, right, func(l int64, r int64) int64 { return l / r })
+}
+
+// Remainder implements `%` on integral values, with the sign of the dividend.
+func Remainder(left Object, right Object) (Object, error) {
+	return integral("%", left, right, func(l int64, r int64) int64 { return l % r })
+}
+
+func integral(op string, left Object, right Object, apply func(int64, int64) int64) (Object, error) {
+	switch l := left.(type) {
+	case *Int:
+		switch r := right.(type) {
+		case *Int:
+			if r.Value == 0 {
+				return nil, DivisionByZero()
+			}
+			return &Int{Value: int64(int32(apply(l.Value, r.Value)))}, nil
+		case *Long:
+			if r.Value == 0 {
+				return nil, DivisionByZero()
+			}
+			return &Long{Value: apply(l.Value, r.Value)}, nil
+		}
+	case *Long:
+		switch r := right.(type) {
+		case *Int, *Long:
+			value := integralValue(r)
+			if value == 0 {
+				return nil, DivisionByZero()
+			}
+			return &Long{Value: apply(l.Value, value)}, nil
+		}
+	}
+	return nil, fmt.Errorf("operator '%s' is not defined for %s and %s", op, left.Type(), right.Type())
+}
+
+func integralValue(obj Object) int64 {
+	switch o := obj.(type) {
+	case *Int:
+		return o.Value
+	case *Long:
+		return o.Value
+	default:
+		return 0
+	}
+}
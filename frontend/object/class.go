@@ -0,0 +1,182 @@
+package object
+
+import "fmt"
+
+// Class is the runtime description of a class, interface or object
+// declaration.
+type Class struct {
+	Name       string
+	Super      *Class
+	Interfaces []*Class
+	// Companion holds the companion object, whose members are reachable
+	// through the class name.
+	Companion *Singleton
+	// Components names the primary constructor properties of a data class,
+	// which componentN() returns in order.
+	Components []string
+	// Annotations are those of the declaration, in source order.
+	Annotations []*Annotation
+	// Delegations are the interfaces implemented by delegation.
+	Delegations []*Delegation
+	// Inner reports whether instances are created from an instance of the
+	// enclosing class, which they keep as Outer.
+	Inner bool
+	// Underlying names the single property of a value class, whose value
+	// stands for the instances. It is empty for other classes.
+	Underlying string
+}
+
+// Delegation records an interface a class implements by delegation,
+// `: List<T> by inner`. Calls to members of the interface that the class
+// does not override are forwarded to the object held in Field.
+type Delegation struct {
+	Interface *Class
+	Field     string
+}
+
+// IsSubclassOf reports whether instances of c are also instances of other.
+func (c *Class) IsSubclassOf(other *Class) bool {
+	if c == other {
+		return true
+	}
+	if c.Super != nil && c.Super.IsSubclassOf(other) {
+		return true
+	}
+	for _, iface := range c.Interfaces {
+		if iface.IsSubclassOf(other) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsInstance implements `obj is C`. Objects that are not created from a
+// class are never instances of one.
+func (c *Class) IsInstance(obj Object) bool {
+	switch o := obj.(type) {
+	case *Instance:
+		return o.Class.IsSubclassOf(c)
+	case *EnumEntry:
+		return o.Class.IsSubclassOf(c)
+	case *Exception:
+		return o.Class.IsSubclassOf(c)
+	case *Boxed:
+		return o.Class.IsSubclassOf(c)
+	default:
+		return false
+	}
+}
+
+// Instance is an object created from a Class. Anonymous objects are
+// instances of a class without a name.
+type Instance struct {
+	Class  *Class
+	Fields map[string]Object
+	// Outer is the instance of the enclosing class an instance of an inner
+	// class was created from.
+	Outer *Instance
+}
+
+func NewInstance(class *Class) *Instance {
+	return &Instance{
+		Class:  class,
+		Fields: make(map[string]Object),
+	}
+}
+
+// NewInnerInstance creates an instance of the inner class from outer,
+// `outer.Inner()`.
+func NewInnerInstance(class *Class, outer *Instance) *Instance {
+	instance := NewInstance(class)
+	instance.Outer = outer
+	return instance
+}
+
+// Enclosing implements `this@Label` inside an inner class: it returns i or
+// the first of its outer instances whose class is called label.
+func (i *Instance) Enclosing(label string) (*Instance, bool) {
+	for instance := i; instance != nil; instance = instance.Outer {
+		if instance.Class.Name == label {
+			return instance, true
+		}
+	}
+	return nil, false
+}
+
+func (i *Instance) Inspect() string {
+	if i.Class.Name == "" {
+		return fmt.Sprintf("<anonymous>@%p", i)
+	}
+	return fmt.Sprintf("%s@%p", i.Class.Name, i)
+}
+func (i *Instance) Type() Type { return Type(i.Class.Name) }
+
+// DelegateFor returns the object the members of iface are forwarded to when
+// the class of i, or one of its superclasses, implements iface by
+// delegation.
+func (i *Instance) DelegateFor(iface *Class) (Object, bool) {
+	for class := i.Class; class != nil; class = class.Super {
+		for _, delegation := range class.Delegations {
+			if delegation.Interface.IsSubclassOf(iface) {
+				delegate, ok := i.Fields[delegation.Field]
+				return delegate, ok
+			}
+		}
+	}
+	return nil, false
+}
+
+// Lateinit reads the field backing a lateinit property, which throws until
+// the property is first assigned. Nothing evaluates properties yet, so
+// lateinit is only checked statically and nothing calls Lateinit outside
+// tests.
+func (i *Instance) Lateinit(name string) (Object, error) {
+	value, ok := i.Fields[name]
+	if !ok {
+		return nil, UninitializedPropertyAccess(name)
+	}
+	return value, nil
+}
+
+// Singleton holds the instance of an `object` declaration. The instance is
+// only created, and its initializer run, on first access. Object
+// declarations are only checked statically for now: nothing evaluates them,
+// so nothing creates a Singleton outside tests.
+type Singleton struct {
+	Class        *Class
+	init         func(instance *Instance) error
+	instance     *Instance
+	err          error
+	initializing bool
+}
+
+func NewSingleton(class *Class, init func(instance *Instance) error) *Singleton {
+	return &Singleton{
+		Class: class,
+		init:  init,
+	}
+}
+
+// Instance returns the singleton, initializing it on first use. An
+// initializer that refers back to its own object sees the partially
+// initialized instance, as on the JVM. A failed initialization is reported
+// again on every later access.
+func (s *Singleton) Instance() (*Instance, error) {
+	if s.instance != nil && (s.initializing || s.err == nil) {
+		return s.instance, nil
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	s.instance = NewInstance(s.Class)
+	if s.init != nil {
+		s.initializing = true
+		s.err = s.init(s.instance)
+		s.initializing = false
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.instance, nil
+}
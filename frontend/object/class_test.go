@@ -0,0 +1,167 @@
+package object
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSingleton_Instance(t *testing.T) {
+	class := &Class{Name: "Registry"}
+	calls := 0
+	var registry *Singleton
+	registry = NewSingleton(class, func(instance *Instance) error {
+		calls++
+		self, err := registry.Instance()
+		if err != nil || self != instance {
+			t.Errorf("initializer sees %v, %v, want its own instance", self, err)
+		}
+		instance.Fields["size"] = &Int{Value: 0}
+		return nil
+	})
+
+	if calls != 0 {
+		t.Fatalf("initializer ran before first access")
+	}
+
+	first, err := registry.Instance()
+	if err != nil {
+		t.Fatalf("Instance() returned %v", err)
+	}
+	second, _ := registry.Instance()
+	if first != second || calls != 1 {
+		t.Errorf("Instance() created %d instances, want 1", calls)
+	}
+	if first.Fields["size"].Inspect() != "0" {
+		t.Errorf("field size is %s, want 0", first.Fields["size"].Inspect())
+	}
+}
+
+func TestSingleton_FailedInitialization(t *testing.T) {
+	failure := errors.New("boom")
+	broken := NewSingleton(&Class{Name: "Broken"}, func(*Instance) error {
+		return failure
+	})
+
+	for i := 0; i < 2; i++ {
+		if _, err := broken.Instance(); err != failure {
+			t.Errorf("access %d returned %v, want %v", i, err, failure)
+		}
+	}
+}
+
+func TestClass_IsSubclassOf(t *testing.T) {
+	listener := &Class{Name: "Listener"}
+	base := &Class{Name: "Base", Interfaces: []*Class{listener}}
+	anonymous := &Class{Super: base}
+
+	if !anonymous.IsSubclassOf(listener) {
+		t.Errorf("anonymous object should implement Listener")
+	}
+	if base.IsSubclassOf(anonymous) {
+		t.Errorf("Base should not be a subclass of its subclass")
+	}
+}
+
+func TestClass_IsInstance(t *testing.T) {
+	result := &Class{Name: "Result"}
+	ok := &Class{Name: "Result.Ok", Super: result}
+	err := &Class{Name: "Result.Err", Super: result}
+	color := &Class{Name: "Color"}
+	entry := NewEnum(color).AddEntry("RED", nil)
+
+	tests := []struct {
+		class *Class
+		obj   Object
+		want  bool
+	}{
+		{result, NewInstance(ok), true},
+		{ok, NewInstance(ok), true},
+		{err, NewInstance(ok), false},
+		{ok, NewInstance(result), false},
+		{color, entry, true},
+		{result, entry, false},
+		{result, &Int{Value: 1}, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.class.IsInstance(tt.obj); got != tt.want {
+			t.Errorf("%s is %s = %v, want %v", tt.obj.Inspect(), tt.class.Name, got, tt.want)
+		}
+	}
+}
+
+func TestClass_FindAnnotation(t *testing.T) {
+	class := &Class{Name: "Legacy", Annotations: []*Annotation{
+		{Name: "Deprecated", Args: []Object{&String{Value: "use Modern"}}},
+		{Name: "Test"},
+	}}
+
+	deprecated, ok := class.FindAnnotation("Deprecated")
+	if !ok || deprecated.Inspect() != "@Deprecated(use Modern)" {
+		t.Errorf("FindAnnotation(Deprecated) is %v, %v", deprecated, ok)
+	}
+	if test, ok := class.FindAnnotation("Test"); !ok || test.Inspect() != "@Test" {
+		t.Errorf("FindAnnotation(Test) is %v, %v", test, ok)
+	}
+	if _, ok := class.FindAnnotation("JvmName"); ok {
+		t.Errorf("FindAnnotation(JvmName) found an annotation Legacy does not have")
+	}
+}
+
+func TestInstance_Lateinit(t *testing.T) {
+	instance := NewInstance(&Class{Name: "Service"})
+
+	_, err := instance.Lateinit("client")
+	var exception *Exception
+	if !errors.As(err, &exception) || !exception.Catches(RuntimeExceptionClass) {
+		t.Fatalf("reading an unassigned lateinit property returned %v, want an exception", err)
+	}
+	if want := "UninitializedPropertyAccessException: lateinit property client has not been initialized"; exception.Inspect() != want {
+		t.Errorf("exception is %q, want %q", exception.Inspect(), want)
+	}
+
+	instance.Fields["client"] = &String{Value: "http"}
+	if value, err := instance.Lateinit("client"); err != nil || value.Inspect() != "http" {
+		t.Errorf("Lateinit(client) is %v, %v after assignment", value, err)
+	}
+}
+
+func TestInstance_DelegateFor(t *testing.T) {
+	collection := &Class{Name: "Collection"}
+	list := &Class{Name: "List", Interfaces: []*Class{collection}}
+	logging := &Class{Name: "LoggingList", Interfaces: []*Class{list}, Delegations: []*Delegation{{Interface: list, Field: "inner"}}}
+	verbose := &Class{Name: "VerboseList", Super: logging}
+
+	inner := NewInstance(&Class{Name: "ArrayList", Interfaces: []*Class{list}})
+	instance := NewInstance(verbose)
+	instance.Fields["inner"] = inner
+
+	if delegate, ok := instance.DelegateFor(collection); !ok || delegate != inner {
+		t.Errorf("DelegateFor(Collection) is %v, %v, want the inner list", delegate, ok)
+	}
+	if _, ok := instance.DelegateFor(&Class{Name: "Comparable"}); ok {
+		t.Errorf("DelegateFor(Comparable) found a delegate for an interface that is not delegated")
+	}
+}
+
+func TestInstance_Enclosing(t *testing.T) {
+	outer := NewInstance(&Class{Name: "Outer"})
+	inner := NewInnerInstance(&Class{Name: "Inner", Inner: true}, outer)
+	innermost := NewInnerInstance(&Class{Name: "Innermost", Inner: true}, inner)
+
+	tests := []struct {
+		label string
+		want  *Instance
+	}{
+		{"Innermost", innermost},
+		{"Inner", inner},
+		{"Outer", outer},
+		{"Other", nil},
+	}
+	for _, test := range tests {
+		got, ok := innermost.Enclosing(test.label)
+		if got != test.want || ok != (test.want != nil) {
+			t.Errorf("Enclosing(%q) is %v, %v, want %v", test.label, got, ok, test.want)
+		}
+	}
+}
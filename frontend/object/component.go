@@ -0,0 +1,22 @@
+package object
+
+import "fmt"
+
+// Component implements `componentN()`, which destructuring declarations
+// call for the n-th component of obj, counting from one.
+func Component(obj Object, n int) (Object, error) {
+	switch o := obj.(type) {
+	case *Pair:
+		switch n {
+		case 1:
+			return o.First, nil
+		case 2:
+			return o.Second, nil
+		}
+	case *Instance:
+		if n >= 1 && n <= len(o.Class.Components) {
+			return o.Fields[o.Class.Components[n-1]], nil
+		}
+	}
+	return nil, fmt.Errorf("Destructuring declaration initializer of type %s must have a 'component%d()' function", obj.Type(), n)
+}
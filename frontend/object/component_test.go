@@ -0,0 +1,33 @@
+package object
+
+import "testing"
+
+func TestComponent(t *testing.T) {
+	person := NewInstance(&Class{Name: "Person", Components: []string{"name", "age"}})
+	person.Fields["name"] = &String{Value: "Ada"}
+	person.Fields["age"] = &Int{Value: 36}
+	pair := &Pair{First: &Int{Value: 1}, Second: TRUE}
+
+	tests := []struct {
+		obj  Object
+		n    int
+		want string
+	}{
+		{pair, 1, "1"},
+		{pair, 2, "true"},
+		{person, 1, "Ada"},
+		{person, 2, "36"},
+	}
+	for _, test := range tests {
+		got, err := Component(test.obj, test.n)
+		if err != nil || got.Inspect() != test.want {
+			t.Errorf("component%d() of %s is %v, %v, want %s", test.n, test.obj.Inspect(), got, err, test.want)
+		}
+	}
+
+	for _, obj := range []Object{pair, person, &Int{Value: 1}} {
+		if _, err := Component(obj, 3); err == nil {
+			t.Errorf("component3() of %s should fail", obj.Inspect())
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package object
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Property describes a delegated property to its delegate, as the
+// KProperty passed to getValue and setValue does.
+type Property struct {
+	Name string
+}
+
+func (p *Property) Inspect() string { return "property " + p.Name }
+func (p *Property) Type() Type      { return "KProperty" }
+
+// Delegate is the object a delegated property, `val x by delegate`, hands
+// its reads to through the getValue operator. thisRef is the object owning
+// the property, or NULL for a top-level or local one.
+type Delegate interface {
+	Object
+	GetValue(thisRef Object, property *Property) (Object, error)
+}
+
+// MutableDelegate also receives the writes of a `var` through setValue.
+type MutableDelegate interface {
+	Delegate
+	SetValue(thisRef Object, property *Property, value Object) error
+}
+
+// Lazy backs `by lazy { ... }`: the initializer runs on the first read and
+// its value is returned from then on. An initializer that fails runs again
+// on the next read.
+type Lazy struct {
+	initializer func() (Object, error)
+	value       Object
+}
+
+func NewLazy(initializer func() (Object, error)) *Lazy {
+	return &Lazy{initializer: initializer}
+}
+
+// IsInitialized reports whether the value has been computed.
+func (l *Lazy) IsInitialized() bool {
+	return l.value != nil
+}
+
+func (l *Lazy) GetValue(thisRef Object, property *Property) (Object, error) {
+	if l.value == nil {
+		value, err := l.initializer()
+		if err != nil {
+			return nil, err
+		}
+		l.value = value
+	}
+	return l.value, nil
+}
+
+func (l *Lazy) Inspect() string {
+	if l.value == nil {
+		return "Lazy value not initialized yet."
+	}
+	return l.value.Inspect()
+}
+func (l *Lazy) Type() Type { return "Lazy" }
+
+// Observable backs Delegates.observable and Delegates.vetoable. It stores
+// the value of the property and is told of every write, which a vetoable
+// property may reject.
+type Observable struct {
+	value Object
+	// beforeChange decides whether a write happens. It is nil for an
+	// observable property.
+	beforeChange func(property *Property, old Object, new Object) (bool, error)
+	// afterChange is called once a write has happened. It is nil for a
+	// vetoable property.
+	afterChange func(property *Property, old Object, new Object) error
+}
+
+// Observe implements Delegates.observable(initial, onChange), whose
+// onChange runs after every write.
+func Observe(initial Object, onChange func(property *Property, old Object, new Object) error) *Observable {
+	return &Observable{value: initial, afterChange: onChange}
+}
+
+// Veto implements Delegates.vetoable(initial, onChange), whose onChange runs
+// before every write and keeps the old value by returning false.
+func Veto(initial Object, onChange func(property *Property, old Object, new Object) (bool, error)) *Observable {
+	return &Observable{value: initial, beforeChange: onChange}
+}
+
+func (o *Observable) GetValue(thisRef Object, property *Property) (Object, error) {
+	return o.value, nil
+}
+
+func (o *Observable) SetValue(thisRef Object, property *Property, value Object) error {
+	old := o.value
+	if o.beforeChange != nil {
+		accepted, err := o.beforeChange(property, old, value)
+		if err != nil || !accepted {
+			return err
+		}
+	}
+	o.value = value
+	if o.afterChange != nil {
+		return o.afterChange(property, old, value)
+	}
+	return nil
+}
+
+func (o *Observable) Inspect() string {
+	return fmt.Sprintf("ObservableProperty(value=%s)", o.value.Inspect())
+}
+func (o *Observable) Type() Type { return "ObservableProperty" }
+
+// Map holds the entries a map-backed property, `val name: String by map`,
+// reads under the name of the property. Only a mutable map backs a `var`.
+type Map struct {
+	Entries map[string]Object
+	Mutable bool
+}
+
+func NewMap(mutable bool) *Map {
+	return &Map{Entries: make(map[string]Object), Mutable: mutable}
+}
+
+func (m *Map) GetValue(thisRef Object, property *Property) (Object, error) {
+	value, ok := m.Entries[property.Name]
+	if !ok {
+		return nil, NewException(NoSuchElementExceptionClass, fmt.Sprintf("Key %s is missing in the map.", property.Name))
+	}
+	return value, nil
+}
+
+func (m *Map) SetValue(thisRef Object, property *Property, value Object) error {
+	if !m.Mutable {
+		return NewException(UnsupportedOperationExceptionClass, "Operation is not supported for read-only collection")
+	}
+	m.Entries[property.Name] = value
+	return nil
+}
+
+// Inspect spells the entries in key order, `{a=1, b=2}`.
+func (m *Map) Inspect() string {
+	keys := make([]string, 0, len(m.Entries))
+	for key := range m.Entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	entries := make([]string, len(keys))
+	for i, key := range keys {
+		entries[i] = key + "=" + m.Entries[key].Inspect()
+	}
+	return "{" + strings.Join(entries, ", ") + "}"
+}
+func (m *Map) Type() Type {
+	if m.Mutable {
+		return "MutableMap"
+	}
+	return "Map"
+}
@@ -0,0 +1,78 @@
+package object
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLazy_GetValue(t *testing.T) {
+	calls := 0
+	lazy := NewLazy(func() (Object, error) {
+		calls++
+		return &Int{Value: 42}, nil
+	})
+	property := &Property{Name: "answer"}
+
+	if lazy.IsInitialized() || lazy.Inspect() != "Lazy value not initialized yet." {
+		t.Errorf("lazy is initialized before the first read")
+	}
+	for i := 0; i < 2; i++ {
+		value, err := lazy.GetValue(NULL, property)
+		if err != nil || value.Inspect() != "42" {
+			t.Errorf("GetValue() is %v, %v, want 42", value, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("initializer ran %d times, want once", calls)
+	}
+}
+
+func TestObservable_SetValue(t *testing.T) {
+	property := &Property{Name: "count"}
+
+	var changes []string
+	observable := Observe(&Int{Value: 0}, func(p *Property, old Object, new Object) error {
+		changes = append(changes, p.Name+": "+old.Inspect()+" -> "+new.Inspect())
+		return nil
+	})
+	if err := observable.SetValue(NULL, property, &Int{Value: 1}); err != nil {
+		t.Fatalf("SetValue() returned %v", err)
+	}
+	if value, _ := observable.GetValue(NULL, property); value.Inspect() != "1" || len(changes) != 1 || changes[0] != "count: 0 -> 1" {
+		t.Errorf("observable holds %v after changes %v", value, changes)
+	}
+
+	positive := Veto(&Int{Value: 1}, func(p *Property, old Object, new Object) (bool, error) {
+		return new.(*Int).Value > 0, nil
+	})
+	for _, value := range []int64{-5, 7} {
+		if err := positive.SetValue(NULL, property, &Int{Value: value}); err != nil {
+			t.Fatalf("SetValue(%d) returned %v", value, err)
+		}
+	}
+	if value, _ := positive.GetValue(NULL, property); value.Inspect() != "7" {
+		t.Errorf("vetoable holds %v, want the rejected write skipped", value)
+	}
+}
+
+func TestMap_Delegate(t *testing.T) {
+	user := NewMap(false)
+	user.Entries["name"] = &String{Value: "Ada"}
+
+	if value, err := user.GetValue(NULL, &Property{Name: "name"}); err != nil || value.Inspect() != "Ada" {
+		t.Errorf("GetValue(name) is %v, %v", value, err)
+	}
+
+	_, err := user.GetValue(NULL, &Property{Name: "age"})
+	var exception *Exception
+	if !errors.As(err, &exception) || exception.Inspect() != "NoSuchElementException: Key age is missing in the map." {
+		t.Errorf("reading a missing key returned %v", err)
+	}
+	if err := user.SetValue(NULL, &Property{Name: "name"}, NULL); !errors.As(err, &exception) || !exception.Catches(UnsupportedOperationExceptionClass) {
+		t.Errorf("writing through a read-only map returned %v", err)
+	}
+
+	var _ MutableDelegate = user
+	var _ MutableDelegate = &Observable{}
+	var _ Delegate = &Lazy{}
+}
@@ -0,0 +1,70 @@
+package object
+
+import "fmt"
+
+// Enum holds the entries of an enum class in declaration order.
+type Enum struct {
+	Class   *Class
+	entries []*EnumEntry
+}
+
+// EnumEntry is the single instance of an enum entry. Entries that declare a
+// body are instances of an anonymous subclass of the enum class.
+type EnumEntry struct {
+	*Instance
+	Name    string
+	Ordinal int
+	enum    *Enum
+}
+
+func NewEnum(class *Class) *Enum {
+	return &Enum{Class: class}
+}
+
+// AddEntry appends the next entry. body is the class of the entry's own
+// body, or nil when it has none.
+func (e *Enum) AddEntry(name string, body *Class) *EnumEntry {
+	class := e.Class
+	if body != nil {
+		if body.Super == nil {
+			body.Super = e.Class
+		}
+		class = body
+	}
+
+	entry := &EnumEntry{
+		Instance: NewInstance(class),
+		Name:     name,
+		Ordinal:  len(e.entries),
+		enum:     e,
+	}
+	e.entries = append(e.entries, entry)
+	return entry
+}
+
+// Entries returns the entries in declaration order, backing both `entries`
+// and `values()`. The slice is a copy.
+func (e *Enum) Entries() []*EnumEntry {
+	entries := make([]*EnumEntry, len(e.entries))
+	copy(entries, e.entries)
+	return entries
+}
+
+// ValueOf returns the entry called name, backing `valueOf(name)`. When
+// there is none it fails with the IllegalArgumentException valueOf throws.
+func (e *Enum) ValueOf(name string) (*EnumEntry, error) {
+	for _, entry := range e.entries {
+		if entry.Name == name {
+			return entry, nil
+		}
+	}
+	return nil, NewException(IllegalArgumentExceptionClass, fmt.Sprintf("No enum constant %s.%s", e.Class.Name, name))
+}
+
+func (e *EnumEntry) Inspect() string { return e.Name }
+func (e *EnumEntry) Type() Type      { return Type(e.enum.Class.Name) }
+
+// CompareTo orders entries by their ordinal.
+func (e *EnumEntry) CompareTo(other *EnumEntry) int {
+	return e.Ordinal - other.Ordinal
+}
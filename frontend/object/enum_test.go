@@ -0,0 +1,41 @@
+package object
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEnum(t *testing.T) {
+	color := NewEnum(&Class{Name: "Color"})
+	red := color.AddEntry("RED", nil)
+	green := color.AddEntry("GREEN", &Class{})
+	color.AddEntry("BLUE", nil)
+
+	if green.Ordinal != 1 || green.Inspect() != "GREEN" || green.Type() != "Color" {
+		t.Errorf("unexpected entry %s ordinal %d type %s", green.Inspect(), green.Ordinal, green.Type())
+	}
+	if !green.Class.IsSubclassOf(color.Class) {
+		t.Errorf("an entry with a body should subclass its enum class")
+	}
+	if red.CompareTo(green) >= 0 {
+		t.Errorf("RED should compare before GREEN")
+	}
+
+	entries := color.Entries()
+	if len(entries) != 3 || entries[2].Name != "BLUE" {
+		t.Errorf("unexpected entries %v", entries)
+	}
+	entries[0] = nil
+	if color.Entries()[0] != red {
+		t.Errorf("Entries() should return a copy")
+	}
+
+	if entry, err := color.ValueOf("RED"); err != nil || entry != red {
+		t.Errorf("ValueOf(RED) is %v, %v", entry, err)
+	}
+	_, err := color.ValueOf("PURPLE")
+	var exception *Exception
+	if !errors.As(err, &exception) || !exception.Catches(IllegalArgumentExceptionClass) || exception.Message != "No enum constant Color.PURPLE" {
+		t.Errorf("ValueOf(PURPLE) returned %v", err)
+	}
+}
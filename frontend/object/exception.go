@@ -0,0 +1,102 @@
+package object
+
+import "fmt"
+
+// The built-in Throwable hierarchy. Scripts extend it by declaring classes
+// whose Super is one of these.
+var (
+	ThrowableClass                            = &Class{Name: "Throwable"}
+	ErrorClass                                = &Class{Name: "Error", Super: ThrowableClass}
+	ExceptionClass                            = &Class{Name: "Exception", Super: ThrowableClass}
+	RuntimeExceptionClass                     = &Class{Name: "RuntimeException", Super: ExceptionClass}
+	IllegalArgumentExceptionClass             = &Class{Name: "IllegalArgumentException", Super: RuntimeExceptionClass}
+	NumberFormatExceptionClass                = &Class{Name: "NumberFormatException", Super: IllegalArgumentExceptionClass}
+	IllegalStateExceptionClass                = &Class{Name: "IllegalStateException", Super: RuntimeExceptionClass}
+	ArithmeticExceptionClass                  = &Class{Name: "ArithmeticException", Super: RuntimeExceptionClass}
+	NullPointerExceptionClass                 = &Class{Name: "NullPointerException", Super: RuntimeExceptionClass}
+	ClassCastExceptionClass                   = &Class{Name: "ClassCastException", Super: RuntimeExceptionClass}
+	IndexOutOfBoundsExceptionClass            = &Class{Name: "IndexOutOfBoundsException", Super: RuntimeExceptionClass}
+	NoSuchElementExceptionClass               = &Class{Name: "NoSuchElementException", Super: RuntimeExceptionClass}
+	UnsupportedOperationExceptionClass        = &Class{Name: "UnsupportedOperationException", Super: RuntimeExceptionClass}
+	UninitializedPropertyAccessExceptionClass = &Class{Name: "UninitializedPropertyAccessException", Super: RuntimeExceptionClass}
+)
+
+var throwableClasses = map[string]*Class{}
+
+func init() {
+	for _, class := range []*Class{
+		ThrowableClass,
+		ErrorClass,
+		ExceptionClass,
+		RuntimeExceptionClass,
+		IllegalArgumentExceptionClass,
+		NumberFormatExceptionClass,
+		IllegalStateExceptionClass,
+		ArithmeticExceptionClass,
+		NullPointerExceptionClass,
+		ClassCastExceptionClass,
+		IndexOutOfBoundsExceptionClass,
+		NoSuchElementExceptionClass,
+		UnsupportedOperationExceptionClass,
+		UninitializedPropertyAccessExceptionClass,
+	} {
+		throwableClasses[class.Name] = class
+	}
+}
+
+// LookupThrowableClass returns the built-in Throwable class called name.
+func LookupThrowableClass(name string) (*Class, bool) {
+	class, ok := throwableClasses[name]
+	return class, ok
+}
+
+// Exception is a thrown, or throwable, instance of a Throwable class. It is
+// also a Go error, so runtime faults travel as ordinary errors until a
+// catch clause matches them.
+type Exception struct {
+	*Instance
+	Message string
+	// Cause is the exception this one wraps, or nil.
+	Cause *Exception
+}
+
+// NewException creates an instance of class, which must be a Throwable.
+func NewException(class *Class, message string) *Exception {
+	return &Exception{
+		Instance: NewInstance(class),
+		Message:  message,
+	}
+}
+
+func (e *Exception) Inspect() string {
+	if e.Message == "" {
+		return e.Class.Name
+	}
+	return fmt.Sprintf("%s: %s", e.Class.Name, e.Message)
+}
+
+func (e *Exception) Error() string { return e.Inspect() }
+
+func (e *Exception) Unwrap() error {
+	if e.Cause == nil {
+		return nil
+	}
+	return e.Cause
+}
+
+// Catches reports whether a catch clause for class handles e.
+func (e *Exception) Catches(class *Class) bool {
+	return e.Class.IsSubclassOf(class)
+}
+
+// DivisionByZero returns the ArithmeticException integer division and
+// remainder by zero raise.
+func DivisionByZero() *Exception {
+	return NewException(ArithmeticExceptionClass, "/ by zero")
+}
+
+// UninitializedPropertyAccess returns the exception reading a lateinit
+// property before its first assignment raises.
+func UninitializedPropertyAccess(name string) *Exception {
+	return NewException(UninitializedPropertyAccessExceptionClass, fmt.Sprintf("lateinit property %s has not been initialized", name))
+}
@@ -0,0 +1,71 @@
+package object
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestException_Catches(t *testing.T) {
+	err := DivisionByZero()
+	for _, class := range []*Class{ArithmeticExceptionClass, RuntimeExceptionClass, ExceptionClass, ThrowableClass} {
+		if !err.Catches(class) {
+			t.Errorf("catch (e: %s) does not catch %s", class.Name, err.Inspect())
+		}
+	}
+	if err.Catches(IllegalStateExceptionClass) || err.Catches(ErrorClass) {
+		t.Errorf("%s caught by an unrelated clause", err.Inspect())
+	}
+
+	custom := &Class{Name: "ValidationException", Super: IllegalArgumentExceptionClass}
+	if !NewException(custom, "bad input").Catches(RuntimeExceptionClass) {
+		t.Errorf("user exceptions should be caught through the built-in hierarchy")
+	}
+}
+
+func TestException_Error(t *testing.T) {
+	cause := NewException(IllegalStateExceptionClass, "")
+	err := NewException(RuntimeExceptionClass, "wrapped")
+	err.Cause = cause
+
+	if err.Error() != "RuntimeException: wrapped" {
+		t.Errorf("Error() is %q", err.Error())
+	}
+	if cause.Inspect() != "IllegalStateException" {
+		t.Errorf("Inspect() is %q", cause.Inspect())
+	}
+	var target *Exception
+	if !errors.As(errors.Unwrap(err), &target) || target != cause {
+		t.Errorf("Unwrap() does not reach the cause")
+	}
+	if class, ok := LookupThrowableClass("NullPointerException"); !ok || !class.IsSubclassOf(RuntimeExceptionClass) {
+		t.Errorf("NullPointerException is not a built-in RuntimeException")
+	}
+}
+
+func TestDivide(t *testing.T) {
+	tests := []struct {
+		fn          func(Object, Object) (Object, error)
+		left, right Object
+		want        string
+	}{
+		{Divide, &Int{Value: 7}, &Int{Value: 2}, "3"},
+		{Divide, &Int{Value: -7}, &Int{Value: 2}, "-3"},
+		{Divide, &Int{Value: -2147483648}, &Int{Value: -1}, "-2147483648"},
+		{Divide, &Long{Value: 10}, &Int{Value: 4}, "2"},
+		{Remainder, &Int{Value: -7}, &Int{Value: 2}, "-1"},
+	}
+	for _, test := range tests {
+		got, err := test.fn(test.left, test.right)
+		if err != nil || got.Inspect() != test.want {
+			t.Errorf("%s, %s gives %v, %v, want %s", test.left.Inspect(), test.right.Inspect(), got, err, test.want)
+		}
+	}
+
+	for _, fn := range []func(Object, Object) (Object, error){Divide, Remainder} {
+		_, err := fn(&Int{Value: 1}, &Long{Value: 0})
+		var exception *Exception
+		if !errors.As(err, &exception) || !exception.Catches(ArithmeticExceptionClass) || exception.Message != "/ by zero" {
+			t.Errorf("division by zero returned %v, want ArithmeticException: / by zero", err)
+		}
+	}
+}
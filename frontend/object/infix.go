@@ -0,0 +1,220 @@
+package object
+
+import (
+	"fmt"
+	"math"
+)
+
+// InfixFunction implements a standard library `infix fun` on runtime values.
+type InfixFunction func(left Object, right Object) (Object, error)
+
+var infixFunctions = map[string]InfixFunction{
+	"to":     to,
+	"and":    and,
+	"or":     or,
+	"xor":    xor,
+	"shl":    shl,
+	"shr":    shr,
+	"ushr":   ushr,
+	"until":  until,
+	"downTo": downTo,
+	"step":   step,
+}
+
+func LookupInfixFunction(name string) (InfixFunction, bool) {
+	fn, ok := infixFunctions[name]
+	return fn, ok
+}
+
+func unsupported(name string, left Object, right Object) error {
+	return fmt.Errorf("infix function '%s' is not defined for %s and %s", name, left.Type(), right.Type())
+}
+
+func to(left Object, right Object) (Object, error) {
+	return &Pair{First: left, Second: right}, nil
+}
+
+func and(left Object, right Object) (Object, error) {
+	switch l := left.(type) {
+	case *Boolean:
+		if r, ok := right.(*Boolean); ok {
+			return nativeBool(l.Value && r.Value), nil
+		}
+	case *Int:
+		if r, ok := right.(*Int); ok {
+			return &Int{Value: l.Value & r.Value}, nil
+		}
+	case *Long:
+		if r, ok := right.(*Long); ok {
+			return &Long{Value: l.Value & r.Value}, nil
+		}
+	}
+	return nil, unsupported("and", left, right)
+}
+
+func or(left Object, right Object) (Object, error) {
+	switch l := left.(type) {
+	case *Boolean:
+		if r, ok := right.(*Boolean); ok {
+			return nativeBool(l.Value || r.Value), nil
+		}
+	case *Int:
+		if r, ok := right.(*Int); ok {
+			return &Int{Value: l.Value | r.Value}, nil
+		}
+	case *Long:
+		if r, ok := right.(*Long); ok {
+			return &Long{Value: l.Value | r.Value}, nil
+		}
+	}
+	return nil, unsupported("or", left, right)
+}
+
+func xor(left Object, right Object) (Object, error) {
+	switch l := left.(type) {
+	case *Boolean:
+		if r, ok := right.(*Boolean); ok {
+			return nativeBool(l.Value != r.Value), nil
+		}
+	case *Int:
+		if r, ok := right.(*Int); ok {
+			return &Int{Value: l.Value ^ r.Value}, nil
+		}
+	case *Long:
+		if r, ok := right.(*Long); ok {
+			return &Long{Value: l.Value ^ r.Value}, nil
+		}
+	}
+	return nil, unsupported("xor", left, right)
+}
+
+// Shifts follow the JVM: Int shifts use the low 5 bits of the distance and
+// wrap at 32 bits, Long shifts use the low 6 bits.
+
+func shl(left Object, right Object) (Object, error) {
+	r, ok := right.(*Int)
+	if !ok {
+		return nil, unsupported("shl", left, right)
+	}
+	switch l := left.(type) {
+	case *Int:
+		return &Int{Value: int64(int32(l.Value) << (r.Value & 31))}, nil
+	case *Long:
+		return &Long{Value: l.Value << (r.Value & 63)}, nil
+	}
+	return nil, unsupported("shl", left, right)
+}
+
+func shr(left Object, right Object) (Object, error) {
+	r, ok := right.(*Int)
+	if !ok {
+		return nil, unsupported("shr", left, right)
+	}
+	switch l := left.(type) {
+	case *Int:
+		return &Int{Value: int64(int32(l.Value) >> (r.Value & 31))}, nil
+	case *Long:
+		return &Long{Value: l.Value >> (r.Value & 63)}, nil
+	}
+	return nil, unsupported("shr", left, right)
+}
+
+func ushr(left Object, right Object) (Object, error) {
+	r, ok := right.(*Int)
+	if !ok {
+		return nil, unsupported("ushr", left, right)
+	}
+	switch l := left.(type) {
+	case *Int:
+		return &Int{Value: int64(int32(uint32(l.Value) >> (r.Value & 31)))}, nil
+	case *Long:
+		return &Long{Value: int64(uint64(l.Value) >> (r.Value & 63))}, nil
+	}
+	return nil, unsupported("ushr", left, right)
+}
+
+func until(left Object, right Object) (Object, error) {
+	switch l := left.(type) {
+	case *Int:
+		if r, ok := right.(*Int); ok {
+			if r.Value == math.MinInt64 {
+				return NewIntRange(1, 0), nil
+			}
+			return NewIntRange(l.Value, r.Value-1), nil
+		}
+	case *Long:
+		if r, ok := right.(*Long); ok {
+			if r.Value == math.MinInt64 {
+				return NewLongRange(1, 0), nil
+			}
+			return NewLongRange(l.Value, r.Value-1), nil
+		}
+	case *Char:
+		if r, ok := right.(*Char); ok {
+			if r.Value == 0 {
+				return NewCharRange(1, 0), nil
+			}
+			return NewCharRange(l.Value, r.Value-1), nil
+		}
+	}
+	return nil, unsupported("until", left, right)
+}
+
+func downTo(left Object, right Object) (Object, error) {
+	switch l := left.(type) {
+	case *Int:
+		if r, ok := right.(*Int); ok {
+			return NewIntProgression(l.Value, r.Value, -1)
+		}
+	case *Long:
+		if r, ok := right.(*Long); ok {
+			return NewLongProgression(l.Value, r.Value, -1)
+		}
+	case *Char:
+		if r, ok := right.(*Char); ok {
+			return NewCharProgression(l.Value, r.Value, -1)
+		}
+	}
+	return nil, unsupported("downTo", left, right)
+}
+
+// step keeps the direction of the progression and replaces the magnitude of
+// its step, so `10 downTo 0 step 2` counts down by two.
+func step(left Object, right Object) (Object, error) {
+	var n int64
+	switch r := right.(type) {
+	case *Int:
+		n = r.Value
+	case *Long:
+		n = r.Value
+	default:
+		return nil, unsupported("step", left, right)
+	}
+	if n <= 0 {
+		return nil, fmt.Errorf("Step must be positive, was: %d.", n)
+	}
+
+	switch l := left.(type) {
+	case *IntRange:
+		return NewIntProgression(l.first, l.last, withDirection(l.step, n))
+	case *LongRange:
+		return NewLongProgression(l.first, l.last, withDirection(l.step, n))
+	case *CharRange:
+		return NewCharProgression(rune(l.first), rune(l.last), withDirection(l.step, n))
+	}
+	return nil, unsupported("step", left, right)
+}
+
+func withDirection(current int64, step int64) int64 {
+	if current < 0 {
+		return -step
+	}
+	return step
+}
+
+func nativeBool(value bool) *Boolean {
+	if value {
+		return TRUE
+	}
+	return FALSE
+}
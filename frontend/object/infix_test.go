@@ -0,0 +1,56 @@
+package object
+
+import "testing"
+
+func TestInfixFunctions(t *testing.T) {
+	tests := []struct {
+		name        string
+		left, right Object
+		want        string
+	}{
+		{"to", &Int{Value: 1}, TRUE, "(1, true)"},
+		{"and", &Int{Value: 12}, &Int{Value: 10}, "8"},
+		{"or", &Int{Value: 12}, &Int{Value: 10}, "14"},
+		{"xor", TRUE, TRUE, "false"},
+		{"shl", &Int{Value: 1}, &Int{Value: 31}, "-2147483648"},
+		{"shl", &Long{Value: 1}, &Int{Value: 31}, "2147483648"},
+		{"shr", &Int{Value: -8}, &Int{Value: 1}, "-4"},
+		{"ushr", &Int{Value: -1}, &Int{Value: 28}, "15"},
+		{"until", &Int{Value: 0}, &Int{Value: 5}, "0..4"},
+		{"downTo", &Int{Value: 10}, &Int{Value: 0}, "10 downTo 0 step 1"},
+		{"until", &Char{Value: 'a'}, &Char{Value: 'd'}, "a..c"},
+	}
+
+	for _, test := range tests {
+		fn, ok := LookupInfixFunction(test.name)
+		if !ok {
+			t.Fatalf("infix function %s is not defined", test.name)
+		}
+		got, err := fn(test.left, test.right)
+		if err != nil {
+			t.Errorf("%s %s %s returned %v", test.left.Inspect(), test.name, test.right.Inspect(), err)
+			continue
+		}
+		if got.Inspect() != test.want {
+			t.Errorf("%s %s %s is %s, want %s", test.left.Inspect(), test.name, test.right.Inspect(), got.Inspect(), test.want)
+		}
+	}
+}
+
+func TestInfixFunctions_Step(t *testing.T) {
+	down, _ := downTo(&Int{Value: 10}, &Int{Value: 0})
+	stepped, err := step(down, &Int{Value: 2})
+	if err != nil {
+		t.Fatalf("step returned %v", err)
+	}
+	if stepped.Inspect() != "10 downTo 0 step 2" {
+		t.Errorf("10 downTo 0 step 2 is %s", stepped.Inspect())
+	}
+
+	if _, err = step(NewIntRange(1, 10), &Int{Value: 0}); err == nil {
+		t.Errorf("expected an error for a non-positive step")
+	}
+	if _, err = step(&Int{Value: 1}, &Int{Value: 2}); err == nil {
+		t.Errorf("expected an error for a step on an Int")
+	}
+}
@@ -0,0 +1,10 @@
+package object
+
+type Iterator interface {
+	HasNext() bool
+	Next() Object
+}
+
+type Iterable interface {
+	Iterator() Iterator
+}
@@ -6,6 +6,9 @@ type Type string
 
 const (
 	IntType     Type = "Int"
+	LongType    Type = "Long"
+	CharType    Type = "Char"
+	StringType  Type = "String"
 	BooleanType Type = "Boolean"
 	NullType    Type = "null"
 	UnitType    Type = "Unit"
@@ -30,6 +33,27 @@ type Int struct {
 func (i *Int) Inspect() string { return fmt.Sprintf("%d", i.Value) }
 func (i *Int) Type() Type      { return IntType }
 
+type Long struct {
+	Value int64
+}
+
+func (l *Long) Inspect() string { return fmt.Sprintf("%d", l.Value) }
+func (l *Long) Type() Type      { return LongType }
+
+type Char struct {
+	Value rune
+}
+
+func (c *Char) Inspect() string { return string(c.Value) }
+func (c *Char) Type() Type      { return CharType }
+
+type String struct {
+	Value string
+}
+
+func (s *String) Inspect() string { return s.Value }
+func (s *String) Type() Type      { return StringType }
+
 type Boolean struct {
 	Value bool
 }
@@ -0,0 +1,15 @@
+package object
+
+import "fmt"
+
+const PairType Type = "Pair"
+
+type Pair struct {
+	First  Object
+	Second Object
+}
+
+func (p *Pair) Inspect() string {
+	return fmt.Sprintf("(%s, %s)", p.First.Inspect(), p.Second.Inspect())
+}
+func (p *Pair) Type() Type { return PairType }
@@ -0,0 +1,252 @@
+package object
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	IntRangeType        Type = "IntRange"
+	IntProgressionType  Type = "IntProgression"
+	LongRangeType       Type = "LongRange"
+	LongProgressionType Type = "LongProgression"
+	CharRangeType       Type = "CharRange"
+	CharProgressionType Type = "CharProgression"
+)
+
+var errZeroStep = errors.New("Step must be non-zero.")
+
+// progression holds the bounds shared by the Int, Long and Char ranges.
+// Elements are never materialized, iterators walk from first to last by step.
+type progression struct {
+	first int64
+	last  int64
+	step  int64
+}
+
+func newProgression(start int64, end int64, step int64) (progression, error) {
+	if step == 0 {
+		return progression{}, errZeroStep
+	}
+	return progression{
+		first: start,
+		last:  progressionLastElement(start, end, step),
+		step:  step,
+	}, nil
+}
+
+// progressionLastElement returns the last element reachable from start by
+// step that does not pass end, so `1..10 step 4` ends at 9.
+func progressionLastElement(start int64, end int64, step int64) int64 {
+	switch {
+	case step > 0 && start < end:
+		return end - differenceModulo(end, start, step)
+	case step < 0 && start > end:
+		return end + differenceModulo(start, end, -step)
+	default:
+		return end
+	}
+}
+
+func differenceModulo(a int64, b int64, c int64) int64 {
+	return mod(mod(a, c)-mod(b, c), c)
+}
+
+func mod(a int64, b int64) int64 {
+	m := a % b
+	if m < 0 {
+		return m + b
+	}
+	return m
+}
+
+func (p progression) isEmpty() bool {
+	if p.step > 0 {
+		return p.first > p.last
+	}
+	return p.first < p.last
+}
+
+func (p progression) contains(value int64) bool {
+	if p.isEmpty() {
+		return false
+	}
+	if p.step > 0 && (value < p.first || value > p.last) {
+		return false
+	}
+	if p.step < 0 && (value > p.first || value < p.last) {
+		return false
+	}
+	return (value-p.first)%p.step == 0
+}
+
+func (p progression) reversed() progression {
+	return progression{first: p.last, last: p.first, step: -p.step}
+}
+
+func (p progression) inspect(format func(int64) string) string {
+	switch {
+	case p.step == 1:
+		return fmt.Sprintf("%s..%s", format(p.first), format(p.last))
+	case p.step > 0:
+		return fmt.Sprintf("%s..%s step %d", format(p.first), format(p.last), p.step)
+	default:
+		return fmt.Sprintf("%s downTo %s step %d", format(p.first), format(p.last), -p.step)
+	}
+}
+
+type progressionIterator struct {
+	next    int64
+	last    int64
+	step    int64
+	hasNext bool
+	box     func(int64) Object
+}
+
+func (p progression) iterator(box func(int64) Object) Iterator {
+	return &progressionIterator{
+		next:    p.first,
+		last:    p.last,
+		step:    p.step,
+		hasNext: !p.isEmpty(),
+		box:     box,
+	}
+}
+
+func (it *progressionIterator) HasNext() bool {
+	return it.hasNext
+}
+
+func (it *progressionIterator) Next() Object {
+	if !it.hasNext {
+		return NULL
+	}
+	value := it.next
+	if value == it.last {
+		it.hasNext = false
+	} else {
+		it.next += it.step
+	}
+	return it.box(value)
+}
+
+type IntRange struct {
+	progression
+}
+
+func NewIntRange(start int64, endInclusive int64) *IntRange {
+	return &IntRange{progression{first: start, last: endInclusive, step: 1}}
+}
+
+func NewIntProgression(start int64, endInclusive int64, step int64) (*IntRange, error) {
+	p, err := newProgression(start, endInclusive, step)
+	if err != nil {
+		return nil, err
+	}
+	return &IntRange{p}, nil
+}
+
+func (r *IntRange) Type() Type {
+	if r.step == 1 {
+		return IntRangeType
+	}
+	return IntProgressionType
+}
+
+func (r *IntRange) Inspect() string     { return r.inspect(formatInt) }
+func (r *IntRange) First() Object       { return boxInt(r.first) }
+func (r *IntRange) Last() Object        { return boxInt(r.last) }
+func (r *IntRange) Step() Object        { return boxInt(r.step) }
+func (r *IntRange) IsEmpty() bool       { return r.isEmpty() }
+func (r *IntRange) Reversed() *IntRange { return &IntRange{r.reversed()} }
+func (r *IntRange) Iterator() Iterator  { return r.iterator(boxInt) }
+
+func (r *IntRange) Contains(value Object) bool {
+	v, ok := value.(*Int)
+	return ok && r.contains(v.Value)
+}
+
+type LongRange struct {
+	progression
+}
+
+func NewLongRange(start int64, endInclusive int64) *LongRange {
+	return &LongRange{progression{first: start, last: endInclusive, step: 1}}
+}
+
+func NewLongProgression(start int64, endInclusive int64, step int64) (*LongRange, error) {
+	p, err := newProgression(start, endInclusive, step)
+	if err != nil {
+		return nil, err
+	}
+	return &LongRange{p}, nil
+}
+
+func (r *LongRange) Type() Type {
+	if r.step == 1 {
+		return LongRangeType
+	}
+	return LongProgressionType
+}
+
+func (r *LongRange) Inspect() string      { return r.inspect(formatInt) }
+func (r *LongRange) First() Object        { return boxLong(r.first) }
+func (r *LongRange) Last() Object         { return boxLong(r.last) }
+func (r *LongRange) Step() Object         { return boxLong(r.step) }
+func (r *LongRange) IsEmpty() bool        { return r.isEmpty() }
+func (r *LongRange) Reversed() *LongRange { return &LongRange{r.reversed()} }
+func (r *LongRange) Iterator() Iterator   { return r.iterator(boxLong) }
+
+func (r *LongRange) Contains(value Object) bool {
+	switch v := value.(type) {
+	case *Long:
+		return r.contains(v.Value)
+	case *Int:
+		return r.contains(v.Value)
+	default:
+		return false
+	}
+}
+
+type CharRange struct {
+	progression
+}
+
+func NewCharRange(start rune, endInclusive rune) *CharRange {
+	return &CharRange{progression{first: int64(start), last: int64(endInclusive), step: 1}}
+}
+
+func NewCharProgression(start rune, endInclusive rune, step int64) (*CharRange, error) {
+	p, err := newProgression(int64(start), int64(endInclusive), step)
+	if err != nil {
+		return nil, err
+	}
+	return &CharRange{p}, nil
+}
+
+func (r *CharRange) Type() Type {
+	if r.step == 1 {
+		return CharRangeType
+	}
+	return CharProgressionType
+}
+
+func (r *CharRange) Inspect() string      { return r.inspect(formatChar) }
+func (r *CharRange) First() Object        { return boxChar(r.first) }
+func (r *CharRange) Last() Object         { return boxChar(r.last) }
+func (r *CharRange) Step() Object         { return boxInt(r.step) }
+func (r *CharRange) IsEmpty() bool        { return r.isEmpty() }
+func (r *CharRange) Reversed() *CharRange { return &CharRange{r.reversed()} }
+func (r *CharRange) Iterator() Iterator   { return r.iterator(boxChar) }
+
+func (r *CharRange) Contains(value Object) bool {
+	v, ok := value.(*Char)
+	return ok && r.contains(int64(v.Value))
+}
+
+func boxInt(v int64) Object  { return &Int{Value: v} }
+func boxLong(v int64) Object { return &Long{Value: v} }
+func boxChar(v int64) Object { return &Char{Value: rune(v)} }
+
+func formatInt(v int64) string  { return fmt.Sprintf("%d", v) }
+func formatChar(v int64) string { return string(rune(v)) }
@@ -0,0 +1,88 @@
+package object
+
+import "testing"
+
+func collect(it Iterator) []string {
+	var values []string
+	for it.HasNext() {
+		values = append(values, it.Next().Inspect())
+	}
+	return values
+}
+
+func TestIntProgression(t *testing.T) {
+	tests := []struct {
+		start, end, step int64
+		last             int64
+		inspect          string
+		elements         int
+	}{
+		{1, 10, 1, 10, "1..10", 10},
+		{1, 10, 4, 9, "1..9 step 4", 3},
+		{10, 0, -2, 0, "10 downTo 0 step 2", 6},
+		{10, 1, -4, 2, "10 downTo 2 step 4", 3},
+		{5, 1, 1, 1, "5..1", 0},
+	}
+
+	for _, test := range tests {
+		r, err := NewIntProgression(test.start, test.end, test.step)
+		if err != nil {
+			t.Fatalf("NewIntProgression(%d, %d, %d) returned %v", test.start, test.end, test.step, err)
+		}
+		if last := r.Last().(*Int).Value; last != test.last {
+			t.Errorf("%s: last is %d, want %d", r.Inspect(), last, test.last)
+		}
+		if r.Inspect() != test.inspect {
+			t.Errorf("Inspect() is %q, want %q", r.Inspect(), test.inspect)
+		}
+		if got := len(collect(r.Iterator())); got != test.elements {
+			t.Errorf("%s: iterated %d elements, want %d", r.Inspect(), got, test.elements)
+		}
+	}
+
+	if _, err := NewIntProgression(1, 10, 0); err == nil {
+		t.Errorf("expected an error for a zero step")
+	}
+}
+
+func TestIntRange_Contains(t *testing.T) {
+	r := NewIntRange(1, 5)
+	stepped, _ := NewIntProgression(0, 10, 3)
+	tests := []struct {
+		r     *IntRange
+		value Object
+		want  bool
+	}{
+		{r, &Int{Value: 1}, true},
+		{r, &Int{Value: 5}, true},
+		{r, &Int{Value: 6}, false},
+		{r, &Long{Value: 3}, false},
+		{r.Reversed(), &Int{Value: 3}, true},
+		{stepped, &Int{Value: 9}, true},
+		{stepped, &Int{Value: 4}, false},
+	}
+
+	for _, test := range tests {
+		if got := test.r.Contains(test.value); got != test.want {
+			t.Errorf("%s contains %s is %t, want %t", test.r.Inspect(), test.value.Inspect(), got, test.want)
+		}
+	}
+}
+
+func TestCharRange_Reversed(t *testing.T) {
+	r := NewCharRange('a', 'e').Reversed()
+	if r.Type() != CharProgressionType {
+		t.Errorf("Type() is %s, want %s", r.Type(), CharProgressionType)
+	}
+
+	got := collect(r.Iterator())
+	want := []string{"e", "d", "c", "b", "a"}
+	if len(got) != len(want) {
+		t.Fatalf("iterated %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("element %d is %s, want %s", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package object
+
+import "fmt"
+
+// Boxed is an instance of a value class where a nullable or generic type is
+// expected, for a runtime that represents instances everywhere else by the
+// bare value of their underlying property. Box and Unbox convert between
+// the two; neither the compiler nor the VM handles classes yet, so nothing
+// emits these conversions.
+type Boxed struct {
+	Class *Class
+	Value Object
+}
+
+// Box wraps value as an instance of the value class.
+func Box(class *Class, value Object) *Boxed {
+	return &Boxed{Class: class, Value: value}
+}
+
+// Unbox returns the underlying value of a boxed instance, and any other
+// object as is.
+func Unbox(obj Object) Object {
+	if boxed, ok := obj.(*Boxed); ok {
+		return boxed.Value
+	}
+	return obj
+}
+
+// Inspect spells the instance as toString does, `UserId(raw=42)`.
+func (b *Boxed) Inspect() string {
+	return fmt.Sprintf("%s(%s=%s)", b.Class.Name, b.Class.Underlying, b.Value.Inspect())
+}
+func (b *Boxed) Type() Type { return Type(b.Class.Name) }
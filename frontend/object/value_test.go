@@ -0,0 +1,22 @@
+package object
+
+import "testing"
+
+func TestBox(t *testing.T) {
+	id := &Class{Name: "UserId", Underlying: "raw"}
+	raw := &Long{Value: 42}
+	boxed := Box(id, raw)
+
+	if got := boxed.Inspect(); got != "UserId(raw=42)" {
+		t.Errorf("Inspect() is %q, want UserId(raw=42)", got)
+	}
+	if !id.IsInstance(boxed) {
+		t.Errorf("%s is not an instance of UserId", boxed.Inspect())
+	}
+	if got := Unbox(boxed); got != raw {
+		t.Errorf("Unbox() is %v, want the underlying value", got)
+	}
+	if got := Unbox(raw); got != raw {
+		t.Errorf("Unbox() of a bare value is %v, want the value itself", got)
+	}
+}
@@ -13,6 +13,8 @@ const (
 	Assignment
 	Logical
 	Relational
+	Infix
+	Range
 	Additive
 	Multiplicative
 	Unary
@@ -22,12 +24,14 @@ const (
 )
 
 type StmtHandler func() (ast.Stmt, error)
+type DeclHandler func(modifiers ast.Modifiers) (ast.Stmt, error)
 type NudHandler func() (ast.Expr, error)
 type LedHandler func(left ast.Expr, precedence BindingPower) (ast.Expr, error)
 type TypeNudHandler func() (ast.Type, error)
 type TypeLedHandler func(left ast.Type, precedence BindingPower) (ast.Type, error)
 
 type StmtLookup map[token.Kind]StmtHandler
+type DeclLookup map[token.Kind]DeclHandler
 type NudLookup map[token.Kind]NudHandler
 type LedLookup map[token.Kind]LedHandler
 
@@ -38,6 +42,7 @@ type BpLookup map[token.Kind]BindingPower
 
 type LookupTable struct {
 	stmtTable    StmtLookup
+	declTable    DeclLookup
 	nudTable     NudLookup
 	ledTable     LedLookup
 	typeNudTable TypeNudLookup
@@ -49,6 +54,7 @@ type LookupTable struct {
 func NewLookupTable() *LookupTable {
 	return &LookupTable{
 		stmtTable:    make(StmtLookup),
+		declTable:    make(DeclLookup),
 		nudTable:     make(NudLookup),
 		ledTable:     make(LedLookup),
 		typeNudTable: make(TypeNudLookup),
@@ -59,11 +65,17 @@ func NewLookupTable() *LookupTable {
 }
 
 func (r *LookupTable) AddStmtHandler(token token.Kind, handler StmtHandler) *LookupTable {
-	r.bpTable[token] = Default
 	r.stmtTable[token] = handler
 	return r
 }
 
+// AddDeclHandler registers the parser of a declaration, which receives the
+// modifiers written in front of it.
+func (r *LookupTable) AddDeclHandler(token token.Kind, handler DeclHandler) *LookupTable {
+	r.declTable[token] = handler
+	return r
+}
+
 func (r *LookupTable) AddLedHandler(token token.Kind, bp BindingPower, handler LedHandler) *LookupTable {
 	r.ledTable[token] = handler
 	r.bpTable[token] = bp
@@ -114,6 +126,11 @@ func (r *LookupTable) GetTypeBpHandler(kind token.Kind) BindingPower {
 	return r.typeBpTable[kind]
 }
 
+func (r *LookupTable) GetDeclHandlerIfExists(kind token.Kind) (DeclHandler, bool) {
+	v, ok := r.declTable[kind]
+	return v, ok
+}
+
 func (r *LookupTable) GetStmtHandlerIfExists(kind token.Kind) (StmtHandler, bool) {
 	v, ok := r.stmtTable[kind]
 	return v, ok
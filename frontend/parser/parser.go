@@ -16,6 +16,11 @@ type Parser struct {
 	lookupTable *LookupTable
 	tokens      []token.Token
 	cursor      int
+	// bodyFollows is set while parsing an expression that a class body may
+	// follow, whose `{` must not be taken for a trailing lambda.
+	bodyFollows bool
+	// errors holds the errors the parser recovered from, in source order.
+	errors []error
 }
 
 func New(scanner Scanner) *Parser {
@@ -23,10 +28,24 @@ func New(scanner Scanner) *Parser {
 	p.lookupTable = NewLookupTable().
 		//Literals
 		AddNudHandler(token.INTLIT, p.parsePrimaryExpr).
+		AddNudHandler(token.DOUBLELIT, p.parsePrimaryExpr).
 		AddNudHandler(token.STRINGLIT, p.parsePrimaryExpr).
 		AddNudHandler(token.BOOLEANLIT, p.parsePrimaryExpr).
+		AddNudHandler(token.NULL, p.parsePrimaryExpr).
 		AddNudHandler(token.IDENTIFIER, p.parsePrimaryExpr).
+		AddNudHandler(token.THIS, p.parsePrimaryExpr).
 		AddNudHandler(token.FUNCTION, p.parseFunctionLiteral).
+		AddNudHandler(token.OBJECT, p.parseObjectExpr).
+		AddNudHandler(token.IF, p.parseIfExpr).
+		AddNudHandler(token.WHEN, p.parseWhenExpr).
+		AddNudHandler(token.THROW, p.parseThrowExpr).
+		AddNudHandler(token.TRY, p.parseTryExpr).
+		AddNudHandler(token.AT, p.parseAnnotatedExpr).
+		AddNudHandler(token.OPEN_BRACE, p.parseLambdaExpr).
+		AddNudHandler(token.LABEL, p.parseLabeledExpr).
+		AddNudHandler(token.RETURN, p.parseReturnExpr).
+		AddNudHandler(token.BREAK, p.parseJumpExpr).
+		AddNudHandler(token.CONTINUE, p.parseJumpExpr).
 
 		//Logical
 		AddLedHandler(token.AND, Logical, p.parseBinaryExpr).
@@ -41,15 +60,33 @@ func New(scanner Scanner) *Parser {
 		AddLedHandler(token.GTE, Relational, p.parseBinaryExpr).
 		AddLedHandler(token.EQ_EQ, Relational, p.parseBinaryExpr).
 		AddLedHandler(token.NOT_EQ, Relational, p.parseBinaryExpr).
+		AddLedHandler(token.IN, Relational, p.parseBinaryExpr).
+		AddLedHandler(token.NOT_IN, Relational, p.parseBinaryExpr).
+		AddLedHandler(token.IS, Relational, p.parseIsExpr).
+		AddLedHandler(token.NOT_IS, Relational, p.parseIsExpr).
+
+		// Named infix calls: `a to b`, `bits and mask`
+		AddLedHandler(token.IDENTIFIER, Infix, p.parseInfixCallExpr).
+
+		//Range
+		AddLedHandler(token.RANGE, Range, p.parseBinaryExpr).
+		AddLedHandler(token.RANGE_UNTIL, Range, p.parseBinaryExpr).
 
 		//Additive
 		AddLedHandler(token.PLUS, Additive, p.parseBinaryExpr).
 		AddLedHandler(token.DASH, Additive, p.parseBinaryExpr).
 		AddLedHandler(token.SLASH, Multiplicative, p.parseBinaryExpr).
 		AddLedHandler(token.STAR, Multiplicative, p.parseBinaryExpr).
+		AddLedHandler(token.PERCENT, Multiplicative, p.parseBinaryExpr).
 
 		// Call
 		AddLedHandler(token.OPEN_PAREN, Call, p.parseCallExpr).
+		AddLedHandler(token.OPEN_BRACE, Call, p.parseTrailingLambda).
+		AddLedHandler(token.LABEL, Call, p.parseTrailingLambda).
+
+		// Member
+		AddLedHandler(token.DOT, Member, p.parseMemberExpr).
+		AddLedHandler(token.QUEST_DOT, Member, p.parseMemberExpr).
 
 		//Unary
 		AddNudHandler(token.DASH, p.parseUnaryExpr).
@@ -58,14 +95,25 @@ func New(scanner Scanner) *Parser {
 		AddNudHandler(token.OPEN_PAREN, p.parseGroupingExpr).
 
 		//Statements
-		AddStmtHandler(token.VAR, p.parseVariableDeclStmt).
-		AddStmtHandler(token.VAL, p.parseVariableDeclStmt).
 		AddStmtHandler(token.IDENTIFIER, p.parseAssignmentStmt).
-		AddStmtHandler(token.CLASS, p.parseClassDeclStmt).
+		AddStmtHandler(token.THIS, p.parseAssignmentStmt).
+		AddStmtHandler(token.FOR, p.parseForStmt).
+		AddStmtHandler(token.WHILE, p.parseWhileStmt).
+		AddStmtHandler(token.LABEL, p.parseLabeledStmt).
+
+		//Declarations
+		AddDeclHandler(token.VAR, p.parseVariableDeclStmt).
+		AddDeclHandler(token.VAL, p.parseVariableDeclStmt).
+		AddDeclHandler(token.CLASS, p.parseClassDeclStmt).
+		AddDeclHandler(token.INTERFACE, p.parseClassDeclStmt).
+		AddDeclHandler(token.TYPEALIAS, p.parseTypeAliasDecl).
+		AddDeclHandler(token.OBJECT, p.parseObjectDeclStmt).
+		AddDeclHandler(token.FUNCTION, p.parseFunctionDeclStmt).
 
 		// Types
 		AddTypeNudHandler(token.IDENTIFIER, p.parseUserType).
 		AddTypeNudHandler(token.OPEN_BRACKET, p.parseArrayType). // TODO Check array syntax
+		AddTypeNudHandler(token.OPEN_PAREN, p.parseFunctionType).
 		AddTypeLedHandler(token.QUESTION, Call, p.parseNullableType)
 
 	return p
@@ -74,12 +122,20 @@ func New(scanner Scanner) *Parser {
 func (p *Parser) Parse() *ast.Program {
 	p.tokens = p.scanner.ScanTokens()
 	p.cursor = 0
+	p.errors = nil
 
 	program := &ast.Program{
 		Statements: []ast.Stmt{},
 	}
 
 	p.skipNewLines()
+	header, imports, err := p.parseFileHeader()
+	if err != nil {
+		panic(err) // TODO handle error synchronize parser
+	}
+	program.Package = header
+	program.Imports = imports
+
 	for p.hasTokens() {
 		stmt, err := p.parseStmt()
 		if err != nil {
@@ -92,12 +148,35 @@ func (p *Parser) Parse() *ast.Program {
 	return program
 }
 
+// Errors returns the errors the last Parse recovered from, leaving the
+// statements around them in the program.
+func (p *Parser) Errors() []error {
+	return p.errors
+}
+
 func (p *Parser) skipNewLines() {
 	for p.currentTokenKind() == token.NEWLINE {
 		p.advance()
 	}
 }
 
+// skipNewLinesBefore skips the new lines ahead when they are followed by a
+// token of one of the given kinds, so that `}` and `catch` may sit on
+// separate lines without the new line ending the statement.
+func (p *Parser) skipNewLinesBefore(kinds ...token.Kind) bool {
+	offset := 0
+	for p.peekKind(offset) == token.NEWLINE {
+		offset++
+	}
+	for _, kind := range kinds {
+		if p.peekKind(offset) == kind {
+			p.cursor += offset
+			return true
+		}
+	}
+	return false
+}
+
 func (p *Parser) currentToken() token.Token {
 	return p.tokens[p.cursor]
 }
@@ -106,6 +185,25 @@ func (p *Parser) currentTokenKind() token.Kind {
 	return p.currentToken().Kind
 }
 
+// atSoftKeyword reports whether the current token is the soft keyword
+// spelled keyword, which is otherwise an ordinary identifier.
+func (p *Parser) atSoftKeyword(keyword string) bool {
+	return p.currentTokenKind() == token.IDENTIFIER && p.currentToken().Spelling == keyword
+}
+
+// peek returns the token offset positions after the current one, or the
+// trailing EOF token when the offset runs past the end.
+func (p *Parser) peek(offset int) token.Token {
+	if p.cursor+offset >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return p.tokens[p.cursor+offset]
+}
+
+func (p *Parser) peekKind(offset int) token.Kind {
+	return p.peek(offset).Kind
+}
+
 func (p *Parser) advance() token.Token {
 	tk := p.tokens[p.cursor]
 	p.cursor++
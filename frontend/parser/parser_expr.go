@@ -3,6 +3,7 @@ package parser
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
 	"gotlin/frontend/ast"
 	"gotlin/frontend/token"
@@ -11,29 +12,64 @@ import (
 func (p *Parser) parsePrimaryExpr() (ast.Expr, error) {
 	switch p.currentTokenKind() {
 	case token.INTLIT:
-		value, err := strconv.ParseInt(p.advance().Spelling, 10, 64)
+		literal := p.advance()
+		value, err := parseIntLiteral(literal.Spelling)
 		return &ast.IntLiteral{
-			Value: value,
+			Value:    value,
+			Position: literal.Position,
+		}, err
+	case token.DOUBLELIT:
+		literal := p.advance()
+		value, err := strconv.ParseFloat(literal.Spelling, 64)
+		return &ast.DoubleLiteral{
+			Value:    value,
+			Position: literal.Position,
 		}, err
 	case token.STRINGLIT:
+		literal := p.advance()
 		return &ast.StringLiteral{
-			Value: p.advance().Spelling,
+			Value:    literal.Spelling,
+			Position: literal.Position,
 		}, nil
 	case token.BOOLEANLIT:
+		literal := p.advance()
 		return &ast.BoolLiteral{
-			Value: p.advance().Spelling == "true",
+			Value:    literal.Spelling == "true",
+			Position: literal.Position,
+		}, nil
+	case token.NULL:
+		return &ast.NullLiteral{
+			Position: p.advance().Position,
 		}, nil
 	case token.IDENTIFIER:
 		return &ast.IdentifierExpr{
 			Value: p.advance(),
 		}, nil
+	case token.THIS:
+		this := &ast.ThisExpr{Token: p.advance()}
+		if p.currentTokenKind() == token.AT_LABEL {
+			this.Label = p.advance()
+		}
+		return this, nil
 	default:
 		return nil, NewError(fmt.Sprintf("Expected primary expression, got %s", p.currentTokenKind()))
 	}
 }
 
+func parseIntLiteral(spelling string) (int64, error) {
+	switch {
+	case strings.HasPrefix(spelling, "0x"), strings.HasPrefix(spelling, "0X"):
+		return strconv.ParseInt(spelling[2:], 16, 64)
+	case strings.HasPrefix(spelling, "0b"), strings.HasPrefix(spelling, "0B"):
+		return strconv.ParseInt(spelling[2:], 2, 64)
+	default:
+		return strconv.ParseInt(spelling, 10, 64)
+	}
+}
+
 func (p *Parser) parseBinaryExpr(left ast.Expr, precedence BindingPower) (ast.Expr, error) {
 	operator := p.advance()
+	p.skipNewLines()
 
 	right, err := p.parseExpr(precedence)
 	if err != nil {
@@ -47,9 +83,43 @@ func (p *Parser) parseBinaryExpr(left ast.Expr, precedence BindingPower) (ast.Ex
 	}, nil
 }
 
+// parseInfixCallExpr parses `left name right` as a call to the infix
+// function name. Infix calls are left associative.
+func (p *Parser) parseInfixCallExpr(left ast.Expr, precedence BindingPower) (ast.Expr, error) {
+	name := p.advance()
+	p.skipNewLines()
+
+	right, err := p.parseExpr(precedence)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ast.InfixCallExpr{
+		Left:  left,
+		Name:  name,
+		Right: right,
+	}, nil
+}
+
+func (p *Parser) parseIsExpr(left ast.Expr, precedence BindingPower) (ast.Expr, error) {
+	operator := p.advance()
+	p.skipNewLines()
+
+	right, err := p.parseType(Default)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ast.IsExpr{
+		Expr: left,
+		Op:   operator,
+		Type: right,
+	}, nil
+}
+
 func (p *Parser) parseUnaryExpr() (ast.Expr, error) {
 	operator := p.advance()
-	right, err := p.parseExpr(Default)
+	right, err := p.parseExpr(Unary)
 	if err != nil {
 		return nil, err
 	}
@@ -92,42 +162,538 @@ func (p *Parser) parseNotNullExpr(left ast.Expr, precedence BindingPower) (ast.E
 
 func (p *Parser) parseCallExpr(left ast.Expr, precedence BindingPower) (ast.Expr, error) {
 	switch left.(type) {
-	case *ast.IdentifierExpr, *ast.CallExpr:
+	case *ast.IdentifierExpr, *ast.CallExpr, *ast.MemberExpr:
 		break
 	default:
 		return nil, NewError(fmt.Sprintf("Expression '%s' cannot be invoked as a function.", left))
 	}
 
+	args, names, err := p.parseArguments()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ast.CallExpr{
+		Callee: left,
+		Args:   args,
+		Names:  names,
+	}, nil
+}
+
+// parseArguments parses a parenthesized, comma separated argument list, and
+// the names of its named arguments, `name = value`. names is nil when no
+// argument is named.
+func (p *Parser) parseArguments() ([]ast.Expr, []token.Token, error) {
+	_, err := p.expected(token.OPEN_PAREN)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	var args []ast.Expr
-	p.advance()
+	var names []token.Token
 	for p.hasTokens() && p.currentTokenKind() != token.CLOSE_PAREN {
-		arg, err := p.parseExpr(Default)
-		if err != nil {
-			return nil, err
+		if p.currentTokenKind() == token.IDENTIFIER && p.peekKind(1) == token.ASSIGN {
+			for len(names) < len(args) {
+				names = append(names, token.Token{})
+			}
+			names = append(names, p.advance())
+			p.advance()
+		} else if names != nil {
+			names = append(names, token.Token{})
+		}
+
+		arg, err2 := p.parseExpr(Default)
+		if err2 != nil {
+			return nil, nil, err2
 		}
 
 		args = append(args, arg)
 		if p.currentTokenKind() != token.CLOSE_PAREN {
 			_, err = p.expected(token.COMMA)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 		}
 	}
 
-	_, err := p.expected(token.CLOSE_PAREN)
+	_, err = p.expected(token.CLOSE_PAREN)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return args, names, nil
+}
+
+// parseLabeledExpr parses a labeled lambda, `lit@{ ... }`.
+func (p *Parser) parseLabeledExpr() (ast.Expr, error) {
+	label := p.advance()
+	if p.currentTokenKind() != token.OPEN_BRACE {
+		return nil, NewError(fmt.Sprintf("label %s@ must precede a loop or a lambda", label.Spelling))
+	}
+
+	expr, err := p.parseLambdaExpr()
 	if err != nil {
 		return nil, err
 	}
+	expr.(*ast.LambdaExpr).Label = label
+	return expr, nil
+}
 
+// parseTrailingLambda parses a lambda passed after the parentheses of a
+// call, or in place of them: `xs.forEach { ... }`.
+func (p *Parser) parseTrailingLambda(left ast.Expr, precedence BindingPower) (ast.Expr, error) {
+	var lambda ast.Expr
+	var err error
+	if p.currentTokenKind() == token.LABEL {
+		lambda, err = p.parseLabeledExpr()
+	} else {
+		lambda, err = p.parseLambdaExpr()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if call, ok := left.(*ast.CallExpr); ok {
+		call.Args = append(call.Args, lambda)
+		return call, nil
+	}
 	return &ast.CallExpr{
 		Callee: left,
-		Args:   args,
+		Args:   []ast.Expr{lambda},
+	}, nil
+}
+
+// parseReturnExpr parses `return`, `return value` and `return@label value`.
+func (p *Parser) parseReturnExpr() (ast.Expr, error) {
+	ret := &ast.ReturnExpr{Return: p.advance()}
+	if p.currentTokenKind() == token.AT_LABEL {
+		ret.Label = p.advance()
+	}
+	if endsExpr(p.currentTokenKind()) {
+		return ret, nil
+	}
+
+	value, err := p.parseExpr(Default)
+	if err != nil {
+		return nil, err
+	}
+	ret.Value = value
+	return ret, nil
+}
+
+// parseJumpExpr parses `break` and `continue`, `break@outer`.
+func (p *Parser) parseJumpExpr() (ast.Expr, error) {
+	jump := &ast.JumpExpr{Jump: p.advance()}
+	if p.currentTokenKind() == token.AT_LABEL {
+		jump.Label = p.advance()
+	}
+	return jump, nil
+}
+
+// endsExpr reports whether a token of kind cannot start an expression,
+// which leaves `return` without a value.
+func endsExpr(kind token.Kind) bool {
+	switch kind {
+	case token.NEWLINE, token.SEMICOLON, token.CLOSE_BRACE, token.CLOSE_PAREN, token.COMMA, token.EOF:
+		return true
+	default:
+		return false
+	}
+}
+
+// parseLambdaExpr parses `{ a, (b, c): Pair -> body }`. The parameters and
+// the arrow are omitted when the lambda declares none.
+func (p *Parser) parseLambdaExpr() (ast.Expr, error) {
+	lambda := &ast.LambdaExpr{Open: p.advance()}
+	if p.lambdaHasParameters() {
+		for p.currentTokenKind() != token.ARROW {
+			param := &ast.LambdaParameter{}
+			var err error
+			if p.currentTokenKind() == token.OPEN_PAREN {
+				param.Pattern, err = p.parseDestructuringPattern()
+			} else {
+				param.Name, err = p.expected(token.IDENTIFIER)
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			if p.currentTokenKind() == token.COLON {
+				p.advance()
+				param.Type, err = p.parseType(Default)
+				if err != nil {
+					return nil, err
+				}
+			}
+			lambda.Parameters = append(lambda.Parameters, param)
+
+			if p.currentTokenKind() != token.ARROW {
+				_, err = p.expected(token.COMMA)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+		p.advance()
+	}
+
+	body, err := p.parseBlockStatements()
+	if err != nil {
+		return nil, err
+	}
+	lambda.Body = body
+
+	_, err = p.expected(token.CLOSE_BRACE)
+	if err != nil {
+		return nil, err
+	}
+	return lambda, nil
+}
+
+// lambdaHasParameters looks ahead for the arrow that ends the parameters of
+// a lambda, which must come before the first statement of its body.
+func (p *Parser) lambdaHasParameters() bool {
+	depth := 0
+	for offset := 0; ; offset++ {
+		switch p.peekKind(offset) {
+		case token.OPEN_PAREN, token.LT:
+			depth++
+		case token.CLOSE_PAREN, token.GT:
+			depth--
+		case token.ARROW:
+			return depth == 0
+		case token.OPEN_BRACE, token.CLOSE_BRACE, token.NEWLINE, token.SEMICOLON, token.ASSIGN, token.EOF:
+			return false
+		}
+	}
+}
+
+// parseAnnotatedExpr parses annotations applied to the prefix expression
+// that follows them.
+func (p *Parser) parseAnnotatedExpr() (ast.Expr, error) {
+	annotations, err := p.parseAnnotations()
+	if err != nil {
+		return nil, err
+	}
+
+	expr, err := p.parseExpr(Unary)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ast.AnnotatedExpr{
+		Annotations: annotations,
+		Expr:        expr,
+	}, nil
+}
+
+// parseObjectExpr parses an anonymous object, `object : Listener { ... }`.
+func (p *Parser) parseObjectExpr() (ast.Expr, error) {
+	object := p.advance()
+	superTypes, err := p.parseOptionalSuperTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := p.parseOptionalClassBody()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ast.ObjectExpr{
+		Object:     object,
+		SuperTypes: superTypes,
+		Members:    members,
+	}, nil
+}
+
+func (p *Parser) parseMemberExpr(left ast.Expr, precedence BindingPower) (ast.Expr, error) {
+	safe := p.advance().Kind == token.QUEST_DOT
+	name, err := p.expected(token.IDENTIFIER)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ast.MemberExpr{
+		Receiver: left,
+		Name:     name,
+		Safe:     safe,
+	}, nil
+}
+
+func (p *Parser) parseIfExpr() (ast.Expr, error) {
+	ifExpr := &ast.IfExpr{If: p.advance()}
+	_, err := p.expected(token.OPEN_PAREN)
+	if err != nil {
+		return nil, err
+	}
+
+	ifExpr.Condition, err = p.parseExpr(Default)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.expected(token.CLOSE_PAREN)
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipNewLines()
+	ifExpr.Then, err = p.parseBranch()
+	if err != nil {
+		return nil, err
+	}
+
+	// The else of a when branch on the next line is not the if's.
+	offset := 0
+	for p.peekKind(offset) == token.NEWLINE {
+		offset++
+	}
+	if p.peekKind(offset) == token.ELSE && p.peekKind(offset+1) != token.ARROW {
+		p.cursor += offset + 1
+		p.skipNewLines()
+		ifExpr.Else, err = p.parseBranch()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ifExpr, nil
+}
+
+// parseBranch parses a block, or the single assignment or expression a
+// branch of an if or when consists of.
+func (p *Parser) parseBranch() (ast.Stmt, error) {
+	if p.currentTokenKind() == token.OPEN_BRACE {
+		body, err := p.parseBlock()
+		if err != nil {
+			return nil, err
+		}
+		return &ast.BlockStmt{Statements: body}, nil
+	}
+	return p.parseAssignmentStmt()
+}
+
+func (p *Parser) parseWhenExpr() (ast.Expr, error) {
+	when := p.advance()
+
+	var subject ast.Expr
+	if p.currentTokenKind() == token.OPEN_PAREN {
+		p.advance()
+		var err error
+		subject, err = p.parseExpr(Default)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = p.expected(token.CLOSE_PAREN)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err := p.expected(token.OPEN_BRACE)
+	if err != nil {
+		return nil, err
+	}
+
+	var branches []*ast.WhenBranch
+	p.skipNewLines()
+	for p.hasTokens() && p.currentTokenKind() != token.CLOSE_BRACE {
+		branch, err2 := p.parseWhenBranch()
+		if err2 != nil {
+			return nil, err2
+		}
+		branches = append(branches, branch)
+
+		err2 = p.expectStmtEnd()
+		if err2 != nil {
+			return nil, err2
+		}
+		p.skipNewLines()
+	}
+
+	_, err = p.expected(token.CLOSE_BRACE)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ast.WhenExpr{
+		When:     when,
+		Subject:  subject,
+		Branches: branches,
+	}, nil
+}
+
+func (p *Parser) parseWhenBranch() (*ast.WhenBranch, error) {
+	branch := &ast.WhenBranch{}
+	if p.currentTokenKind() == token.ELSE {
+		p.advance()
+		branch.Else = true
+	} else {
+		for {
+			condition, err := p.parseWhenCondition()
+			if err != nil {
+				return nil, err
+			}
+			branch.Conditions = append(branch.Conditions, condition)
+
+			if p.currentTokenKind() != token.COMMA {
+				break
+			}
+			p.advance()
+		}
+	}
+
+	_, err := p.expected(token.ARROW)
+	if err != nil {
+		return nil, err
+	}
+	p.skipNewLines()
+
+	branch.Body, err = p.parseBranch()
+	if err != nil {
+		return nil, err
+	}
+	return branch, nil
+}
+
+func (p *Parser) parseWhenCondition() (*ast.WhenCondition, error) {
+	condition := &ast.WhenCondition{}
+	switch p.currentTokenKind() {
+	case token.IS, token.NOT_IS:
+		condition.Op = p.advance()
+		conditionType, err := p.parseType(Default)
+		if err != nil {
+			return nil, err
+		}
+		condition.Type = conditionType
+		return condition, nil
+	case token.IN, token.NOT_IN:
+		condition.Op = p.advance()
+	}
+
+	expr, err := p.parseExpr(Default)
+	if err != nil {
+		return nil, err
+	}
+	condition.Expr = expr
+	return condition, nil
+}
+
+func (p *Parser) parseThrowExpr() (ast.Expr, error) {
+	throw := p.advance()
+	expr, err := p.parseExpr(Default)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ast.ThrowExpr{
+		Throw: throw,
+		Expr:  expr,
+	}, nil
+}
+
+func (p *Parser) parseTryExpr() (ast.Expr, error) {
+	try := &ast.TryExpr{Try: p.advance()}
+
+	body, err := p.parseBlock()
+	if err != nil {
+		return nil, err
+	}
+	try.Body = &ast.BlockStmt{Statements: body}
+
+	for p.skipNewLinesBefore(token.CATCH) {
+		clause, err2 := p.parseCatchClause()
+		if err2 != nil {
+			return nil, err2
+		}
+		try.Catches = append(try.Catches, clause)
+	}
+
+	if p.skipNewLinesBefore(token.FINALLY) {
+		p.advance()
+		finally, err2 := p.parseBlock()
+		if err2 != nil {
+			return nil, err2
+		}
+		try.Finally = &ast.BlockStmt{Statements: finally}
+	}
+
+	if len(try.Catches) == 0 && try.Finally == nil {
+		return nil, NewError("expected 'catch' or 'finally' after the try block")
+	}
+	return try, nil
+}
+
+func (p *Parser) parseCatchClause() (*ast.CatchClause, error) {
+	catch := p.advance()
+	_, err := p.expected(token.OPEN_PAREN)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := p.expected(token.IDENTIFIER)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.expected(token.COLON)
+	if err != nil {
+		return nil, err
+	}
+
+	tp, err := p.parseType(Default)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.expected(token.CLOSE_PAREN)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := p.parseBlock()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ast.CatchClause{
+		Catch: catch,
+		Name:  name,
+		Type:  tp,
+		Body:  &ast.BlockStmt{Statements: body},
 	}, nil
 }
 
 func (p *Parser) parseFunctionLiteral() (ast.Expr, error) {
-	p.advance()
+	fun := p.advance()
+	funcParameters, err := p.parseFunctionParameters()
+	if err != nil {
+		return nil, err
+	}
+
+	funcType, err := p.parseOptionalReturnType()
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := p.parseFunctionBody()
+	if err != nil {
+		return nil, err
+	}
+	if body == nil {
+		return nil, NewError("Anonymous functions must have a body")
+	}
+
+	return &ast.FunctionLiteral{
+		Fun:        fun,
+		Type:       funcType,
+		Parameters: funcParameters,
+		Body:       body,
+	}, nil
+}
+
+func (p *Parser) parseFunctionParameters() ([]*ast.ParameterWithOptionalType, error) {
 	_, err := p.expected(token.OPEN_PAREN)
 	if err != nil {
 		return nil, err
@@ -135,6 +701,12 @@ func (p *Parser) parseFunctionLiteral() (ast.Expr, error) {
 
 	var funcParameters []*ast.ParameterWithOptionalType
 	for p.hasTokens() && p.currentTokenKind() != token.CLOSE_PAREN {
+		annotations, err2 := p.parseAnnotations()
+		if err2 != nil {
+			return nil, err2
+		}
+
+		vararg := p.parseVararg()
 		funcParameter, err2 := p.expected(token.IDENTIFIER)
 		if err2 != nil {
 			return nil, err2
@@ -150,9 +722,22 @@ func (p *Parser) parseFunctionLiteral() (ast.Expr, error) {
 			return nil, err2
 		}
 
+		var defaultValue ast.Expr
+		if p.currentTokenKind() == token.ASSIGN {
+			p.advance()
+			defaultValue, err2 = p.parseExpr(Default)
+			if err2 != nil {
+				return nil, err2
+			}
+		}
+
 		funcParameters = append(funcParameters, &ast.ParameterWithOptionalType{
-			Name: funcParameter.Spelling,
-			Type: funcParameterType,
+			Annotations:  annotations,
+			Name:         funcParameter.Spelling,
+			Position:     funcParameter.Position,
+			Type:         funcParameterType,
+			Vararg:       vararg,
+			DefaultValue: defaultValue,
 		})
 
 		if p.currentTokenKind() != token.CLOSE_PAREN {
@@ -167,49 +752,55 @@ func (p *Parser) parseFunctionLiteral() (ast.Expr, error) {
 		return nil, err
 	}
 
-	var funcType ast.Type
-	if p.currentTokenKind() == token.COLON {
+	return funcParameters, nil
+}
+
+// parseVararg consumes the vararg modifier of a parameter, which is only a
+// keyword in front of the parameter.
+func (p *Parser) parseVararg() bool {
+	if !p.atSoftKeyword(ast.Vararg) {
+		return false
+	}
+	switch p.peekKind(1) {
+	case token.IDENTIFIER, token.VAL, token.VAR:
 		p.advance()
-		funcType, err = p.parseType(Default)
-		if err != nil {
-			return nil, err
-		}
+		return true
 	}
+	return false
+}
 
-	// fun () = expr
-	var bodyExpr ast.Expr
-	if p.currentTokenKind() == token.ASSIGN {
+func (p *Parser) parseOptionalReturnType() (ast.Type, error) {
+	if p.currentTokenKind() != token.COLON {
+		return nil, nil
+	}
+
+	p.advance()
+	return p.parseType(Default)
+}
+
+// parseFunctionBody parses `= expr` or a block. Functions without a body,
+// such as abstract members, yield nil.
+func (p *Parser) parseFunctionBody() (*ast.FunctionBody, error) {
+	switch p.currentTokenKind() {
+	case token.ASSIGN:
+		// fun () = expr
 		p.advance()
-		bodyExpr, err = p.parseExpr(Default)
+		p.skipNewLines()
+		bodyExpr, err := p.parseExpr(Default)
 		if err != nil {
 			return nil, err
 		}
-	}
-
-	// fun () {}
-	var block []ast.Stmt
-	if p.currentTokenKind() == token.OPEN_BRACE {
-		p.advance()
-		for p.hasTokens() && p.currentTokenKind() != token.CLOSE_BRACE {
-			stmt, stmtErr := p.parseStmt()
-			if stmtErr != nil {
-				return nil, stmtErr
-			}
-
-			block = append(block, stmt)
-			if p.currentTokenKind() != token.CLOSE_BRACE {
-				_, err = p.expected(token.COMMA)
-				if err != nil {
-					return nil, err
-				}
-			}
+		return &ast.FunctionBody{Expr: bodyExpr}, nil
+	case token.OPEN_BRACE:
+		// fun () {}
+		block, err := p.parseBlock()
+		if err != nil {
+			return nil, err
 		}
+		return &ast.FunctionBody{Block: block}, nil
+	default:
+		return nil, nil
 	}
-
-	return &ast.FunctionLiteral{
-		Type:       funcType,
-		Parameters: funcParameters,
-	}, nil
 }
 
 func (p *Parser) parseExpr(precedence BindingPower) (ast.Expr, error) {
@@ -224,7 +815,17 @@ func (p *Parser) parseExpr(precedence BindingPower) (ast.Expr, error) {
 		return nil, err
 	}
 
-	for p.lookupTable.GetBpHandler(p.currentTokenKind()) > precedence {
+	for {
+		// A line starting with `.`, `?.` or `?:` continues the expression
+		// on the previous line.
+		p.skipNewLinesBefore(token.DOT, token.QUEST_DOT, token.ELVIS)
+		if p.lookupTable.GetBpHandler(p.currentTokenKind()) <= precedence {
+			break
+		}
+		if p.bodyFollows && p.currentTokenKind() == token.OPEN_BRACE {
+			break
+		}
+
 		currKind = p.currentTokenKind()
 		ledHandler, existsLed := p.lookupTable.GetLedHandlerIfExists(currKind)
 		if !existsLed {
@@ -8,10 +8,35 @@ import (
 )
 
 func (p *Parser) parseStmt() (ast.Stmt, error) {
+	annotations, err := p.parseAnnotations()
+	if err != nil {
+		return nil, err
+	}
+	if annotations != nil {
+		p.skipNewLines()
+	}
+
+	modifiers := p.parseModifiers()
 	kind := p.currentTokenKind()
-	stmtHandler, exists := p.lookupTable.GetStmtHandlerIfExists(kind)
 	var stmt ast.Stmt
-	if exists {
+	if declHandler, exists := p.lookupTable.GetDeclHandlerIfExists(kind); exists {
+		stmt, err = declHandler(modifiers)
+		if err != nil {
+			return nil, err
+		}
+		if annotations != nil {
+			annotate(stmt, annotations)
+		}
+	} else if annotations != nil {
+		expr, err := p.parseExpr(Default)
+		if err != nil {
+			return nil, err
+		}
+
+		stmt = &ast.ExprStmt{
+			Expr: &ast.AnnotatedExpr{Annotations: annotations, Expr: expr},
+		}
+	} else if stmtHandler, exists := p.lookupTable.GetStmtHandlerIfExists(kind); exists {
 		var err error
 		stmt, err = stmtHandler()
 		if err != nil {
@@ -29,7 +54,7 @@ func (p *Parser) parseStmt() (ast.Stmt, error) {
 	}
 
 	// TODO Remove this expectation
-	_, err := p.expected(token.SEMICOLON, token.NEWLINE)
+	err = p.expectStmtEnd()
 	if err != nil {
 		return nil, err
 	}
@@ -37,8 +62,179 @@ func (p *Parser) parseStmt() (ast.Stmt, error) {
 	return stmt, nil
 }
 
-func (p *Parser) parseVariableDeclStmt() (ast.Stmt, error) {
+// parseAnnotations parses the annotations in front of a declaration,
+// parameter or expression, `@Test` or `@Deprecated("use x")`.
+func (p *Parser) parseAnnotations() (ast.Annotations, error) {
+	var annotations ast.Annotations
+	for p.currentTokenKind() == token.AT {
+		annotation := &ast.Annotation{At: p.advance()}
+		name, _, err := p.parseQualifiedName(false)
+		if err != nil {
+			return nil, err
+		}
+		annotation.Name = name
+
+		if p.currentTokenKind() == token.OPEN_PAREN {
+			annotation.Args, annotation.Names, err = p.parseArguments()
+			if err != nil {
+				return nil, err
+			}
+		}
+		annotations = append(annotations, annotation)
+		p.skipNewLinesBefore(token.AT)
+	}
+	return annotations, nil
+}
+
+// annotate attaches annotations to the declaration they precede.
+func annotate(stmt ast.Stmt, annotations ast.Annotations) {
+	switch decl := stmt.(type) {
+	case *ast.VariableDecl:
+		decl.Annotations = annotations
+	case *ast.FunctionDecl:
+		decl.Annotations = annotations
+	case *ast.ClassDeclStmt:
+		decl.Annotations = annotations
+	case *ast.ObjectDeclStmt:
+		decl.Annotations = annotations
+	case *ast.TypeAliasDecl:
+		decl.Annotations = annotations
+	case *ast.DestructuringDecl:
+		decl.Annotations = annotations
+	}
+}
+
+// expectStmtEnd consumes the terminator of a statement. The closing brace of
+// a block also ends its last statement but is left for the block to consume.
+func (p *Parser) expectStmtEnd() error {
+	if p.currentTokenKind() == token.CLOSE_BRACE {
+		return nil
+	}
+
+	_, err := p.expected(token.SEMICOLON, token.NEWLINE)
+	return err
+}
+
+// parseModifiers consumes the modifiers in front of a declaration. Modifiers
+// are soft keywords, so `operator` is only taken as one when a declaration
+// follows it.
+func (p *Parser) parseModifiers() ast.Modifiers {
+	count := 0
+	for p.peekKind(count) == token.IDENTIFIER && token.IsModifier(p.peek(count).Spelling) {
+		count++
+	}
+
+	if count == 0 {
+		return nil
+	}
+	if _, isDecl := p.lookupTable.GetDeclHandlerIfExists(p.peekKind(count)); !isDecl {
+		return nil
+	}
+
+	modifiers := make(ast.Modifiers, 0, count)
+	for i := 0; i < count; i++ {
+		modifiers = append(modifiers, p.advance())
+	}
+	return modifiers
+}
+
+func (p *Parser) parseBlock() ([]ast.Stmt, error) {
+	_, err := p.expected(token.OPEN_BRACE)
+	if err != nil {
+		return nil, err
+	}
+
+	stmts, err := p.parseBlockStatements()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.expected(token.CLOSE_BRACE)
+	if err != nil {
+		return nil, err
+	}
+
+	return stmts, nil
+}
+
+// parseBlockStatements parses statements up to, but not including, the
+// closing brace of a block.
+func (p *Parser) parseBlockStatements() ([]ast.Stmt, error) {
+	var stmts []ast.Stmt
+	p.skipNewLines()
+	for p.hasTokens() && p.currentTokenKind() != token.CLOSE_BRACE {
+		stmt, err := p.parseStmt()
+		if err != nil {
+			return nil, err
+		}
+
+		stmts = append(stmts, stmt)
+		p.skipNewLines()
+	}
+	return stmts, nil
+}
+
+func (p *Parser) parseFunctionDeclStmt(modifiers ast.Modifiers) (ast.Stmt, error) {
+	// `fun (x: Int) = x` is an anonymous function used as an expression
+	if p.peekKind(1) != token.IDENTIFIER {
+		if len(modifiers) != 0 {
+			return nil, NewError(fmt.Sprintf("Modifier '%s' is not applicable to anonymous functions", modifiers[0].Spelling))
+		}
+
+		expr, err := p.parseExpr(Default)
+		if err != nil {
+			return nil, err
+		}
+		return &ast.ExprStmt{Expr: expr}, nil
+	}
+
+	p.advance()
+	receiver, err := p.parseOptionalReceiverType()
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := p.expected(token.IDENTIFIER)
+	if err != nil {
+		return nil, err
+	}
+
+	parameters, err := p.parseFunctionParameters()
+	if err != nil {
+		return nil, err
+	}
+
+	returnType, err := p.parseOptionalReturnType()
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := p.parseFunctionBody()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ast.FunctionDecl{
+		Modifiers:  modifiers,
+		Receiver:   receiver,
+		Name:       name,
+		Parameters: parameters,
+		Type:       returnType,
+		Body:       body,
+	}, nil
+}
+
+func (p *Parser) parseVariableDeclStmt(modifiers ast.Modifiers) (ast.Stmt, error) {
 	readOnly := p.advance().Kind == token.VAL
+	if p.currentTokenKind() == token.OPEN_PAREN {
+		return p.parseDestructuringDecl(modifiers, readOnly)
+	}
+
+	receiver, err := p.parseOptionalReceiverType()
+	if err != nil {
+		return nil, err
+	}
+
 	identifier, err := p.expected(token.IDENTIFIER)
 	if err != nil {
 		return nil, err
@@ -55,33 +251,345 @@ func (p *Parser) parseVariableDeclStmt() (ast.Stmt, error) {
 	}
 
 	var assignedValue ast.Expr
-	if p.currentTokenKind() != token.SEMICOLON && p.currentTokenKind() != token.NEWLINE {
-		_, err = p.expected(token.ASSIGN)
+	if p.currentTokenKind() == token.ASSIGN {
+		p.advance()
+		p.skipNewLines()
+		assignedValue, err = p.parseExpr(Assignment)
 		if err != nil {
 			return nil, err
 		}
+	}
 
-		assignedValue, err = p.parseExpr(Assignment)
+	var delegate ast.Expr
+	if assignedValue == nil && p.atSoftKeyword(ast.By) {
+		delegate, err = p.parseDelegate()
 		if err != nil {
 			return nil, err
 		}
-	} else if explicitType == nil {
-		return nil, NewError("This variable must either have a type annotation or be initialized")
 	}
 
-	_, err = p.expected(token.SEMICOLON, token.NEWLINE)
+	getter, setter, err := p.parseAccessors()
 	if err != nil {
 		return nil, err
 	}
 
+	if assignedValue == nil && delegate == nil && getter == nil {
+		if p.currentTokenKind() != token.SEMICOLON && p.currentTokenKind() != token.NEWLINE {
+			_, err = p.expected(token.ASSIGN)
+			return nil, err
+		}
+		if explicitType == nil {
+			return nil, NewError("This variable must either have a type annotation or be initialized")
+		}
+	}
+
 	return &ast.VariableDecl{
-		Name:     identifier,
-		Type:     explicitType,
-		Value:    assignedValue,
-		ReadOnly: readOnly,
+		Modifiers: modifiers,
+		Receiver:  receiver,
+		Name:      identifier,
+		Type:      explicitType,
+		Value:     assignedValue,
+		ReadOnly:  readOnly,
+		Delegate:  delegate,
+		Getter:    getter,
+		Setter:    setter,
+	}, nil
+}
+
+// parseDestructuringDecl parses `val (name, age) = person`.
+func (p *Parser) parseDestructuringDecl(modifiers ast.Modifiers, readOnly bool) (ast.Stmt, error) {
+	pattern, err := p.parseDestructuringPattern()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.expected(token.ASSIGN)
+	if err != nil {
+		return nil, err
+	}
+	p.skipNewLines()
+
+	value, err := p.parseExpr(Assignment)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ast.DestructuringDecl{
+		Modifiers: modifiers,
+		Pattern:   pattern,
+		Value:     value,
+		ReadOnly:  readOnly,
 	}, nil
 }
 
+// parseDestructuringPattern parses `(name, _, age: Int)`.
+func (p *Parser) parseDestructuringPattern() (*ast.DestructuringPattern, error) {
+	open, err := p.expected(token.OPEN_PAREN)
+	if err != nil {
+		return nil, err
+	}
+
+	pattern := &ast.DestructuringPattern{Open: open}
+	for {
+		name, err2 := p.expected(token.IDENTIFIER)
+		if err2 != nil {
+			return nil, err2
+		}
+		entry := &ast.DestructuringEntry{Name: name}
+
+		if p.currentTokenKind() == token.COLON {
+			p.advance()
+			entry.Type, err2 = p.parseType(Default)
+			if err2 != nil {
+				return nil, err2
+			}
+		}
+		pattern.Entries = append(pattern.Entries, entry)
+
+		if p.currentTokenKind() != token.COMMA {
+			break
+		}
+		p.advance()
+	}
+
+	_, err = p.expected(token.CLOSE_PAREN)
+	if err != nil {
+		return nil, err
+	}
+	return pattern, nil
+}
+
+// parseOptionalReceiverType parses the `Type.` in front of the name of an
+// extension function or property. Receivers are simple or nullable type
+// names, where `?.` ends a nullable receiver.
+func (p *Parser) parseOptionalReceiverType() (ast.Type, error) {
+	if p.currentTokenKind() != token.IDENTIFIER {
+		return nil, nil
+	}
+
+	switch p.peekKind(1) {
+	case token.DOT:
+		name := p.advance()
+		p.advance()
+		return &ast.TypeName{Name: name.Spelling, Position: name.Position}, nil
+	case token.QUEST_DOT:
+		name := p.advance()
+		p.advance()
+		return &ast.NullableType{Type: &ast.TypeName{Name: name.Spelling, Position: name.Position}}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// parseDelegate parses `by expr`, which delegates a property or the
+// implementation of an interface to the value of expr.
+func (p *Parser) parseDelegate() (ast.Expr, error) {
+	p.advance()
+	p.skipNewLines()
+	return p.parseExpr(Assignment)
+}
+
+// parseAccessors parses the `get()` and `set(value)` accessors of a
+// property, in either order. Each may start on the line following the
+// property and may only change the visibility, `private set`.
+func (p *Parser) parseAccessors() (*ast.PropertyAccessor, *ast.PropertyAccessor, error) {
+	var getter, setter *ast.PropertyAccessor
+	for {
+		kind, ok := p.accessorAhead()
+		if !ok || kind == ast.Getter && getter != nil || kind == ast.Setter && setter != nil {
+			return getter, setter, nil
+		}
+
+		p.skipNewLines()
+		accessor, err := p.parseAccessor()
+		if err != nil {
+			return nil, nil, err
+		}
+		if kind == ast.Getter {
+			getter = accessor
+		} else {
+			setter = accessor
+		}
+	}
+}
+
+// accessorAhead reports whether an accessor follows, and which. A bare
+// `get` or `set` is only taken as an accessor after a modifier, so that a
+// call to a function named get on the next line remains a statement.
+func (p *Parser) accessorAhead() (string, bool) {
+	offset := 0
+	if p.currentTokenKind() == token.NEWLINE {
+		offset = 1
+	}
+	modifiers := 0
+	for p.peekKind(offset+modifiers) == token.IDENTIFIER && token.IsModifier(p.peek(offset+modifiers).Spelling) {
+		modifiers++
+	}
+
+	keyword := p.peek(offset + modifiers)
+	if keyword.Kind != token.IDENTIFIER || keyword.Spelling != ast.Getter && keyword.Spelling != ast.Setter {
+		return "", false
+	}
+	if modifiers == 0 && p.peekKind(offset+modifiers+1) != token.OPEN_PAREN {
+		return "", false
+	}
+	return keyword.Spelling, true
+}
+
+func (p *Parser) parseAccessor() (*ast.PropertyAccessor, error) {
+	accessor := &ast.PropertyAccessor{}
+	for p.currentToken().Spelling != ast.Getter && p.currentToken().Spelling != ast.Setter {
+		accessor.Modifiers = append(accessor.Modifiers, p.advance())
+	}
+	accessor.Keyword = p.advance()
+	if p.currentTokenKind() != token.OPEN_PAREN {
+		return accessor, nil
+	}
+
+	p.advance()
+	if accessor.Keyword.Spelling == ast.Setter {
+		name, err := p.expected(token.IDENTIFIER)
+		if err != nil {
+			return nil, err
+		}
+		accessor.Parameter = &ast.ParameterWithOptionalType{Name: name.Spelling, Position: name.Position}
+		if p.currentTokenKind() == token.COLON {
+			p.advance()
+			accessor.Parameter.Type, err = p.parseType(Default)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	_, err := p.expected(token.CLOSE_PAREN)
+	if err != nil {
+		return nil, err
+	}
+
+	accessor.Body, err = p.parseFunctionBody()
+	if err != nil {
+		return nil, err
+	}
+	if accessor.Body == nil {
+		if accessor.Keyword.Spelling == ast.Getter {
+			return nil, NewError("Getter must have a body")
+		}
+		return nil, NewError("Setter must have a body")
+	}
+	return accessor, nil
+}
+
+// parseForStmt parses `for (x in xs) body`, where the loop variable may be a
+// destructuring pattern.
+func (p *Parser) parseForStmt() (ast.Stmt, error) {
+	loop := &ast.ForStmt{For: p.advance()}
+	_, err := p.expected(token.OPEN_PAREN)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.currentTokenKind() == token.OPEN_PAREN {
+		loop.Pattern, err = p.parseDestructuringPattern()
+	} else {
+		loop.Variable, err = p.expected(token.IDENTIFIER)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.expected(token.IN)
+	if err != nil {
+		return nil, err
+	}
+
+	loop.Iterable, err = p.parseExpr(Default)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.expected(token.CLOSE_PAREN)
+	if err != nil {
+		return nil, err
+	}
+
+	loop.Body, err = p.parseLoopBody()
+	if err != nil {
+		return nil, err
+	}
+	return loop, nil
+}
+
+func (p *Parser) parseWhileStmt() (ast.Stmt, error) {
+	loop := &ast.WhileStmt{While: p.advance()}
+	_, err := p.expected(token.OPEN_PAREN)
+	if err != nil {
+		return nil, err
+	}
+
+	loop.Condition, err = p.parseExpr(Default)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.expected(token.CLOSE_PAREN)
+	if err != nil {
+		return nil, err
+	}
+
+	loop.Body, err = p.parseLoopBody()
+	if err != nil {
+		return nil, err
+	}
+	return loop, nil
+}
+
+// parseLabeledStmt parses a labeled loop, `outer@ for (...)`. Any other
+// labeled statement is a labeled expression.
+func (p *Parser) parseLabeledStmt() (ast.Stmt, error) {
+	label := p.currentToken()
+	var stmt ast.Stmt
+	var err error
+	switch p.peekKind(1) {
+	case token.FOR:
+		p.advance()
+		stmt, err = p.parseForStmt()
+		if err == nil {
+			stmt.(*ast.ForStmt).Label = label
+		}
+	case token.WHILE:
+		p.advance()
+		stmt, err = p.parseWhileStmt()
+		if err == nil {
+			stmt.(*ast.WhileStmt).Label = label
+		}
+	default:
+		var expr ast.Expr
+		expr, err = p.parseExpr(Default)
+		stmt = &ast.ExprStmt{Expr: expr}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return stmt, nil
+}
+
+// parseLoopBody parses a block, or the single expression a loop repeats.
+func (p *Parser) parseLoopBody() (ast.Stmt, error) {
+	if p.currentTokenKind() == token.OPEN_BRACE {
+		body, err := p.parseBlock()
+		if err != nil {
+			return nil, err
+		}
+		return &ast.BlockStmt{Statements: body}, nil
+	}
+
+	expr, err := p.parseExpr(Default)
+	if err != nil {
+		return nil, err
+	}
+	return &ast.ExprStmt{Expr: expr}, nil
+}
+
 func (p *Parser) parseAssignmentStmt() (ast.Stmt, error) {
 	assigne, err := p.parseExpr(Default)
 	if err != nil {
@@ -90,8 +598,11 @@ func (p *Parser) parseAssignmentStmt() (ast.Stmt, error) {
 
 	if p.currentTokenKind() == token.ASSIGN {
 		p.advance()
+		p.skipNewLines()
 
-		if _, ok := assigne.(*ast.IdentifierExpr); !ok {
+		switch assigne.(type) {
+		case *ast.IdentifierExpr, *ast.MemberExpr:
+		default:
 			return nil, NewError(fmt.Sprintf("Variable expected, got %v", assigne))
 		}
 
@@ -99,25 +610,38 @@ func (p *Parser) parseAssignmentStmt() (ast.Stmt, error) {
 		if err2 != nil {
 			return nil, err2
 		}
-		_, err2 = p.expected(token.SEMICOLON, token.NEWLINE)
-		if err2 != nil {
-			return nil, err2
+		if err := p.skipChainedAssignment(); err != nil {
+			return nil, err
 		}
 
 		return &ast.AssignStmt{Assigne: assigne, Value: right}, nil
 	}
-	_, err = p.expected(token.SEMICOLON, token.NEWLINE)
-	if err != nil {
-		return nil, err
-	}
 
 	return &ast.ExprStmt{
 		Expr: assigne,
 	}, nil
 }
 
-func (p *Parser) parseClassDeclStmt() (ast.Stmt, error) {
-	_, err := p.expected(token.CLASS)
+// skipChainedAssignment rejects the rest of `a = b = c`, whose `b = c` is
+// an assignment used as a value, and moves past it so that the statement
+// still ends where parseStmt expects.
+func (p *Parser) skipChainedAssignment() error {
+	if p.currentTokenKind() != token.ASSIGN {
+		return nil
+	}
+	p.errors = append(p.errors, NewError("Assignments are not expressions, and only expressions are allowed in this context"))
+	for p.currentTokenKind() == token.ASSIGN {
+		p.advance()
+		p.skipNewLines()
+		if _, err := p.parseExpr(Default); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Parser) parseClassDeclStmt(modifiers ast.Modifiers) (ast.Stmt, error) {
+	keyword, err := p.expected(token.CLASS, token.INTERFACE)
 	if err != nil {
 		return nil, err
 	}
@@ -133,6 +657,21 @@ func (p *Parser) parseClassDeclStmt() (ast.Stmt, error) {
 		p.advance()
 		var parameters []ast.ClassParam
 		for p.hasTokens() && p.currentTokenKind() != token.CLOSE_PAREN {
+			annotations, err2 := p.parseAnnotations()
+			if err2 != nil {
+				return nil, err2
+			}
+
+			paramModifiers := p.parseModifiers()
+			vararg := p.parseVararg()
+
+			// `val` and `var` parameters are also properties of the class
+			property := p.currentTokenKind() == token.VAL || p.currentTokenKind() == token.VAR
+			readOnly := true
+			if property {
+				readOnly = p.advance().Kind == token.VAL
+			}
+
 			parameter, err2 := p.expected(token.IDENTIFIER)
 			if err2 != nil {
 				return nil, err2
@@ -158,10 +697,15 @@ func (p *Parser) parseClassDeclStmt() (ast.Stmt, error) {
 			}
 
 			parameters = append(parameters, ast.ClassParam{
+				Annotations:  annotations,
+				Modifiers:    paramModifiers,
 				Name:         parameter.Spelling,
+				Position:     parameter.Position,
 				Type:         parameterType,
-				ReadOnly:     true,
+				ReadOnly:     readOnly,
+				Property:     property,
 				DefaultValue: defaultValue,
+				Vararg:       vararg,
 			})
 
 			if p.currentTokenKind() != token.CLOSE_PAREN {
@@ -183,8 +727,288 @@ func (p *Parser) parseClassDeclStmt() (ast.Stmt, error) {
 		}
 	}
 
+	superTypes, err := p.parseOptionalSuperTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*ast.EnumEntry
+	var members []ast.Stmt
+	if modifiers.Has("enum") && p.currentTokenKind() == token.OPEN_BRACE {
+		entries, members, err = p.parseEnumClassBody()
+	} else {
+		members, err = p.parseOptionalClassBody()
+	}
+	if err != nil {
+		return nil, err
+	}
+
 	return &ast.ClassDeclStmt{
+		Modifiers:          modifiers,
 		Name:               className,
+		Interface:          keyword.Kind == token.INTERFACE,
 		PrimaryConstructor: primaryConstructor,
+		SuperTypes:         superTypes,
+		Entries:            entries,
+		Members:            members,
 	}, nil
 }
+
+// parseTypeAliasDecl parses `typealias Name<T> = Type`.
+func (p *Parser) parseTypeAliasDecl(modifiers ast.Modifiers) (ast.Stmt, error) {
+	p.advance()
+	name, err := p.expected(token.IDENTIFIER)
+	if err != nil {
+		return nil, err
+	}
+
+	var typeParams []token.Token
+	if p.currentTokenKind() == token.LT {
+		p.advance()
+		for {
+			param, err2 := p.expected(token.IDENTIFIER)
+			if err2 != nil {
+				return nil, err2
+			}
+			typeParams = append(typeParams, param)
+
+			if p.currentTokenKind() != token.COMMA {
+				break
+			}
+			p.advance()
+		}
+		_, err = p.expected(token.GT)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = p.expected(token.ASSIGN)
+	if err != nil {
+		return nil, err
+	}
+
+	tp, err := p.parseType(Default)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ast.TypeAliasDecl{
+		Modifiers:  modifiers,
+		Name:       name,
+		TypeParams: typeParams,
+		Type:       tp,
+	}, nil
+}
+
+// parseEnumClassBody parses `{ A, B(args) { ... }; members }`. The entries
+// come first and a semicolon separates them from the other members.
+func (p *Parser) parseEnumClassBody() ([]*ast.EnumEntry, []ast.Stmt, error) {
+	_, err := p.expected(token.OPEN_BRACE)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var entries []*ast.EnumEntry
+	p.skipNewLines()
+	for p.currentTokenKind() == token.IDENTIFIER || p.currentTokenKind() == token.AT {
+		annotations, err2 := p.parseAnnotations()
+		if err2 != nil {
+			return nil, nil, err2
+		}
+		p.skipNewLines()
+
+		name, err2 := p.expected(token.IDENTIFIER)
+		if err2 != nil {
+			return nil, nil, err2
+		}
+		entry := &ast.EnumEntry{Annotations: annotations, Name: name}
+		if p.currentTokenKind() == token.OPEN_PAREN {
+			entry.Args, entry.Names, err = p.parseArguments()
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		if p.currentTokenKind() == token.OPEN_BRACE {
+			entry.Members, err = p.parseBlock()
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		entries = append(entries, entry)
+
+		p.skipNewLines()
+		if p.currentTokenKind() != token.COMMA {
+			break
+		}
+		p.advance()
+		p.skipNewLines()
+	}
+
+	var members []ast.Stmt
+	if p.currentTokenKind() == token.SEMICOLON {
+		p.advance()
+		members, err = p.parseBlockStatements()
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	_, err = p.expected(token.CLOSE_BRACE)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return entries, members, nil
+}
+
+// parseObjectDeclStmt parses `object Name : SuperTypes { ... }`. The name is
+// only optional for companion objects, which the checker verifies.
+func (p *Parser) parseObjectDeclStmt(modifiers ast.Modifiers) (ast.Stmt, error) {
+	object := p.advance()
+
+	var name token.Token
+	if p.currentTokenKind() == token.IDENTIFIER {
+		name = p.advance()
+	}
+
+	superTypes, err := p.parseOptionalSuperTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := p.parseOptionalClassBody()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ast.ObjectDeclStmt{
+		Modifiers:  modifiers,
+		Object:     object,
+		Name:       name,
+		SuperTypes: superTypes,
+		Members:    members,
+	}, nil
+}
+
+// parseOptionalSuperTypes parses `: Base(args), Interface` after a class or
+// object header.
+func (p *Parser) parseOptionalSuperTypes() ([]*ast.SuperType, error) {
+	if p.currentTokenKind() != token.COLON {
+		return nil, nil
+	}
+	p.advance()
+
+	var superTypes []*ast.SuperType
+	for {
+		superType, err := p.parseType(Default)
+		if err != nil {
+			return nil, err
+		}
+
+		entry := &ast.SuperType{Type: superType}
+		if p.currentTokenKind() == token.OPEN_PAREN {
+			entry.Call = true
+			entry.Args, entry.Names, err = p.parseArguments()
+			if err != nil {
+				return nil, err
+			}
+		}
+		if p.atSoftKeyword(ast.By) {
+			p.bodyFollows = true
+			entry.Delegate, err = p.parseDelegate()
+			p.bodyFollows = false
+			if err != nil {
+				return nil, err
+			}
+		}
+		superTypes = append(superTypes, entry)
+
+		if p.currentTokenKind() != token.COMMA {
+			return superTypes, nil
+		}
+		p.advance()
+	}
+}
+
+func (p *Parser) parseOptionalClassBody() ([]ast.Stmt, error) {
+	if p.currentTokenKind() != token.OPEN_BRACE {
+		return nil, nil
+	}
+	return p.parseBlock()
+}
+
+// parseFileHeader parses the optional package header and the imports that
+// open a file.
+func (p *Parser) parseFileHeader() (*ast.PackageHeader, []*ast.ImportDirective, error) {
+	var header *ast.PackageHeader
+	if p.currentTokenKind() == token.PACKAGE {
+		pkg := p.advance()
+		name, _, err := p.parseQualifiedName(false)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err = p.expectHeaderEnd(); err != nil {
+			return nil, nil, err
+		}
+		header = &ast.PackageHeader{Package: pkg, Name: name}
+		p.skipNewLines()
+	}
+
+	var imports []*ast.ImportDirective
+	for p.currentTokenKind() == token.IMPORT {
+		directive := &ast.ImportDirective{Import: p.advance()}
+		path, all, err := p.parseQualifiedName(true)
+		if err != nil {
+			return nil, nil, err
+		}
+		directive.Path, directive.All = path, all
+
+		if !all && p.currentTokenKind() == token.AS {
+			p.advance()
+			directive.Alias, err = p.expected(token.IDENTIFIER)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		if err = p.expectHeaderEnd(); err != nil {
+			return nil, nil, err
+		}
+		imports = append(imports, directive)
+		p.skipNewLines()
+	}
+	return header, imports, nil
+}
+
+// expectHeaderEnd ends a header line, which may also be the last line of a
+// file without declarations.
+func (p *Parser) expectHeaderEnd() error {
+	if !p.hasTokens() {
+		return nil
+	}
+	return p.expectStmtEnd()
+}
+
+// parseQualifiedName parses `com.acme.util`, followed by `.*` when
+// wildcard is allowed.
+func (p *Parser) parseQualifiedName(wildcard bool) (string, bool, error) {
+	id, err := p.expected(token.IDENTIFIER)
+	if err != nil {
+		return "", false, err
+	}
+
+	name := id.Spelling
+	for p.currentTokenKind() == token.DOT {
+		p.advance()
+		if wildcard && p.currentTokenKind() == token.STAR {
+			p.advance()
+			return name, true, nil
+		}
+		id, err = p.expected(token.IDENTIFIER)
+		if err != nil {
+			return "", false, err
+		}
+		name += "." + id.Spelling
+	}
+	return name, false, nil
+}
@@ -1,10 +1,13 @@
 package parser
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 
+	"gotlin/frontend/ast"
 	"gotlin/frontend/scanner"
+	"gotlin/frontend/token"
 )
 
 func TestParser_ParseProgram(t *testing.T) {
@@ -27,3 +30,845 @@ func TestParser_ParseProgram(t *testing.T) {
 		}
 	}
 }
+
+func TestParser_ChainedAssignment(t *testing.T) {
+	p := New(scanner.NewScanner(strings.NewReader("var a = 1; var b = 2; a = b = 3")))
+	program := p.Parse()
+	assign, ok := program.Statements[2].(*ast.AssignStmt)
+	if !ok {
+		t.Fatalf("statement is %T, want *ast.AssignStmt", program.Statements[2])
+	}
+	if value, ok := assign.Value.(*ast.IdentifierExpr); !ok || value.Value.Spelling != "b" {
+		t.Errorf("assigned value is %v, want b", assign.Value)
+	}
+	errs := p.Errors()
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "Assignments are not expressions") {
+		t.Errorf("errors are %v, want a chained assignment error", errs)
+	}
+}
+
+func TestParser_RangeExpr(t *testing.T) {
+	tests := []struct {
+		input string
+		op    token.Kind
+	}{
+		{"1..10", token.RANGE},
+		{"0..<n", token.RANGE_UNTIL},
+		{"x in 1..5", token.IN},
+		{"x !in 1 + 1..5", token.NOT_IN},
+		{"1..n + 1", token.RANGE},
+	}
+
+	for _, test := range tests {
+		s := scanner.NewScanner(strings.NewReader(test.input))
+		program := New(s).Parse()
+		stmt, ok := program.Statements[0].(*ast.ExprStmt)
+		if !ok {
+			t.Fatalf("%q: statement is %T, want *ast.ExprStmt", test.input, program.Statements[0])
+		}
+		expr, ok := stmt.Expr.(*ast.BinaryExpr)
+		if !ok {
+			t.Fatalf("%q: expression is %T, want *ast.BinaryExpr", test.input, stmt.Expr)
+		}
+		if expr.Op.Kind != test.op {
+			t.Errorf("%q: operator is %s, want %s", test.input, expr.Op.Kind, test.op)
+		}
+	}
+}
+
+func TestParser_OperatorFunctionDecl(t *testing.T) {
+	input := `class Money(val amount: Int) {
+		operator fun plus(other: Money): Money = other
+		operator fun invoke() {
+			amount
+		}
+	}
+	val operator = -a + b`
+
+	s := scanner.NewScanner(strings.NewReader(input))
+	program := New(s).Parse()
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements is %d, want 2", len(program.Statements))
+	}
+
+	class := program.Statements[0].(*ast.ClassDeclStmt)
+	if !class.PrimaryConstructor.Parameters[0].Property {
+		t.Errorf("constructor parameter 'amount' should be a property")
+	}
+	if len(class.Members) != 2 {
+		t.Fatalf("class.Members is %d, want 2", len(class.Members))
+	}
+	for _, member := range class.Members {
+		fun, ok := member.(*ast.FunctionDecl)
+		if !ok {
+			t.Fatalf("member is %T, want *ast.FunctionDecl", member)
+		}
+		if !fun.Modifiers.Has("operator") {
+			t.Errorf("function %s should have the operator modifier", fun.Name)
+		}
+	}
+
+	decl := program.Statements[1].(*ast.VariableDecl)
+	if decl.Name.Spelling != "operator" {
+		t.Errorf("variable name is %s, want operator", decl.Name)
+	}
+	if _, ok := decl.Value.(*ast.BinaryExpr); !ok {
+		t.Errorf("'-a + b' parsed as %T, want *ast.BinaryExpr", decl.Value)
+	}
+}
+
+func TestParser_InfixCallExpr(t *testing.T) {
+	tests := []struct {
+		input string
+		name  string
+		right string
+	}{
+		// `step` applies to the whole `10 downTo 0` progression
+		{"10 downTo 0 step 2", "step", "*ast.IntLiteral"},
+		// ranges bind tighter than infix calls
+		{"1 to 2..3", "to", "*ast.BinaryExpr"},
+		// additive operators bind tighter than infix calls
+		{"1 shl 2 + 3", "shl", "*ast.BinaryExpr"},
+	}
+
+	for _, test := range tests {
+		s := scanner.NewScanner(strings.NewReader(test.input))
+		program := New(s).Parse()
+		stmt := program.Statements[0].(*ast.ExprStmt)
+		expr, ok := stmt.Expr.(*ast.InfixCallExpr)
+		if !ok {
+			t.Fatalf("%q: expression is %T, want *ast.InfixCallExpr", test.input, stmt.Expr)
+		}
+		if expr.Name.Spelling != test.name {
+			t.Errorf("%q: infix call is %s, want %s", test.input, expr.Name, test.name)
+		}
+		if right := fmt.Sprintf("%T", expr.Right); right != test.right {
+			t.Errorf("%q: right operand is %s, want %s", test.input, right, test.right)
+		}
+	}
+
+	s := scanner.NewScanner(strings.NewReader("x in 0 until n"))
+	stmt := New(s).Parse().Statements[0].(*ast.ExprStmt)
+	if expr, ok := stmt.Expr.(*ast.BinaryExpr); !ok || expr.Op.Kind != token.IN {
+		t.Errorf("'x in 0 until n' parsed as %T, want an 'in' expression", stmt.Expr)
+	}
+}
+
+func TestParser_ExtensionDecl(t *testing.T) {
+	input := `fun String.shout() = this.uppercase() + "!"
+	fun Int?.orZero(): Int = this ?: 0
+	val Int.isEven get() = this % 2 == 0
+	val String.size: Int
+		get() = length
+	name?.shout()`
+
+	s := scanner.NewScanner(strings.NewReader(input))
+	program := New(s).Parse()
+	if len(program.Statements) != 5 {
+		t.Fatalf("program.Statements is %d, want 5", len(program.Statements))
+	}
+
+	receivers := []string{"*ast.TypeName", "*ast.NullableType", "*ast.TypeName", "*ast.TypeName"}
+	for i, receiver := range receivers {
+		var got ast.Type
+		switch decl := program.Statements[i].(type) {
+		case *ast.FunctionDecl:
+			got = decl.Receiver
+		case *ast.VariableDecl:
+			got = decl.Receiver
+			if decl.Getter == nil {
+				t.Errorf("statement %d: property %s has no getter", i, decl.Name)
+			}
+		}
+		if fmt.Sprintf("%T", got) != receiver {
+			t.Errorf("statement %d: receiver is %T, want %s", i, got, receiver)
+		}
+	}
+
+	call := program.Statements[4].(*ast.ExprStmt).Expr.(*ast.CallExpr)
+	member, ok := call.Callee.(*ast.MemberExpr)
+	if !ok || !member.Safe || member.Name.Spelling != "shout" {
+		t.Errorf("'name?.shout()' callee is %#v, want a safe member access", call.Callee)
+	}
+}
+
+func TestParser_ObjectDecl(t *testing.T) {
+	input := `interface Listener {
+		fun onEvent(name: String)
+	}
+	object Registry : Base(1), Listener {
+		val size = 0
+	}
+	class User(val name: String) {
+		companion object {
+			fun create(): User = User("root")
+		}
+	}
+	val listener = object : Listener {
+		fun onEvent(name: String) {}
+	}`
+
+	s := scanner.NewScanner(strings.NewReader(input))
+	program := New(s).Parse()
+	if len(program.Statements) != 4 {
+		t.Fatalf("program.Statements is %d, want 4", len(program.Statements))
+	}
+
+	if iface := program.Statements[0].(*ast.ClassDeclStmt); !iface.Interface {
+		t.Errorf("Listener should be an interface")
+	}
+
+	registry := program.Statements[1].(*ast.ObjectDeclStmt)
+	if registry.ObjectName() != "Registry" || len(registry.SuperTypes) != 2 || len(registry.Members) != 1 {
+		t.Errorf("unexpected object declaration %#v", registry)
+	}
+	if !registry.SuperTypes[0].Call || registry.SuperTypes[1].Call {
+		t.Errorf("only the superclass Base should be invoked")
+	}
+
+	user := program.Statements[2].(*ast.ClassDeclStmt)
+	companion := user.Members[0].(*ast.ObjectDeclStmt)
+	if !companion.Modifiers.Has("companion") || companion.ObjectName() != ast.CompanionName {
+		t.Errorf("unexpected companion object %#v", companion)
+	}
+
+	listener := program.Statements[3].(*ast.VariableDecl)
+	if object, ok := listener.Value.(*ast.ObjectExpr); !ok || len(object.Members) != 1 {
+		t.Errorf("listener is initialized with %#v, want an anonymous object", listener.Value)
+	}
+}
+
+func TestParser_EnumClassDecl(t *testing.T) {
+	input := `enum class Color(val rgb: Int) {
+		RED(0xFF0000),
+		GREEN(0x00FF00) {
+			fun describe() = "grass"
+		},
+		BLUE(0b11111111);
+
+		fun hex() = rgb
+	}
+	val name = when (color) {
+		Color.RED, Color.GREEN -> "warm"
+		in others -> { "listed" }
+		else -> "cold"
+	}`
+
+	s := scanner.NewScanner(strings.NewReader(input))
+	program := New(s).Parse()
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements is %d, want 2", len(program.Statements))
+	}
+
+	color := program.Statements[0].(*ast.ClassDeclStmt)
+	if len(color.Entries) != 3 || len(color.Members) != 1 {
+		t.Fatalf("enum has %d entries and %d members, want 3 and 1", len(color.Entries), len(color.Members))
+	}
+	if rgb := color.Entries[0].Args[0].(*ast.IntLiteral).Value; rgb != 0xFF0000 {
+		t.Errorf("RED rgb is %x, want ff0000", rgb)
+	}
+	if rgb := color.Entries[2].Args[0].(*ast.IntLiteral).Value; rgb != 0xFF {
+		t.Errorf("BLUE rgb is %x, want ff", rgb)
+	}
+	if len(color.Entries[1].Members) != 1 {
+		t.Errorf("GREEN should declare its own body")
+	}
+
+	when := program.Statements[1].(*ast.VariableDecl).Value.(*ast.WhenExpr)
+	if len(when.Branches) != 3 || len(when.Branches[0].Conditions) != 2 || !when.Branches[2].Else {
+		t.Errorf("unexpected when branches %#v", when.Branches)
+	}
+	if when.Branches[1].Conditions[0].Op.Kind != token.IN {
+		t.Errorf("second branch should be an 'in' condition")
+	}
+	if _, ok := when.Branches[1].Body.(*ast.BlockStmt); !ok {
+		t.Errorf("second branch body is %T, want *ast.BlockStmt", when.Branches[1].Body)
+	}
+}
+
+func TestParser_IsExpr(t *testing.T) {
+	input := `val ok = r is Result.Ok && r !is Result.Err
+	val n = when (r) {
+		is Result.Ok -> 1
+		!is Result -> 2
+		else -> 3
+	}`
+
+	s := scanner.NewScanner(strings.NewReader(input))
+	program := New(s).Parse()
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements is %d, want 2", len(program.Statements))
+	}
+
+	and := program.Statements[0].(*ast.VariableDecl).Value.(*ast.BinaryExpr)
+	is := and.Left.(*ast.IsExpr)
+	if is.Op.Kind != token.IS || is.Type.(*ast.TypeName).Name != "Result.Ok" {
+		t.Errorf("left operand is %#v, want r is Result.Ok", is)
+	}
+	if notIs := and.Right.(*ast.IsExpr); notIs.Op.Kind != token.NOT_IS {
+		t.Errorf("right operand is %#v, want r !is Result.Err", notIs)
+	}
+
+	when := program.Statements[1].(*ast.VariableDecl).Value.(*ast.WhenExpr)
+	for i, want := range []token.Kind{token.IS, token.NOT_IS} {
+		condition := when.Branches[i].Conditions[0]
+		if condition.Op.Kind != want || condition.Type == nil {
+			t.Errorf("branch %d condition is %#v, want %s with a type", i, condition, want)
+		}
+	}
+}
+
+func TestParser_TryExpr(t *testing.T) {
+	input := `val n = try {
+		parse(s)
+	}
+	catch (e: NumberFormatException) { 0 }
+	catch (e: Exception) { throw IllegalStateException("bad") }
+	finally {
+		log("done")
+	}
+	val m = try { 1 } finally { }`
+
+	s := scanner.NewScanner(strings.NewReader(input))
+	program := New(s).Parse()
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements is %d, want 2", len(program.Statements))
+	}
+
+	try := program.Statements[0].(*ast.VariableDecl).Value.(*ast.TryExpr)
+	if len(try.Catches) != 2 || try.Finally == nil || len(try.Finally.Statements) != 1 {
+		t.Fatalf("try has %d catches and finally %v, want 2 and a finally block", len(try.Catches), try.Finally)
+	}
+	if name := try.Catches[0].Type.(*ast.TypeName).Name; name != "NumberFormatException" {
+		t.Errorf("first catch type is %s, want NumberFormatException", name)
+	}
+	body := try.Catches[1].Body.Statements[0].(*ast.ExprStmt)
+	if _, ok := body.Expr.(*ast.ThrowExpr); !ok {
+		t.Errorf("second catch body is %T, want *ast.ThrowExpr", body.Expr)
+	}
+
+	if try := program.Statements[1].(*ast.VariableDecl).Value.(*ast.TryExpr); try.Catches != nil || try.Finally == nil {
+		t.Errorf("try without catch should keep its finally block")
+	}
+}
+
+func TestParser_IfExpr(t *testing.T) {
+	input := `val max = if (a > b) a else b
+	fun f() {
+		if (c) x = 1 else x = 2
+		if (s == null) return
+		if (ready) {
+			start()
+		}
+		else stop()
+		val n = when {
+			c -> if (d) 1
+			else -> 2
+		}
+		when (n) {
+			1 -> x = 1
+			else -> { x = 2 }
+		}
+	}`
+
+	s := scanner.NewScanner(strings.NewReader(input))
+	program := New(s).Parse()
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements is %d, want 2", len(program.Statements))
+	}
+
+	max := program.Statements[0].(*ast.VariableDecl).Value.(*ast.IfExpr)
+	if _, ok := max.Condition.(*ast.BinaryExpr); !ok {
+		t.Errorf("condition is %T, want *ast.BinaryExpr", max.Condition)
+	}
+	if _, ok := max.Else.(*ast.ExprStmt); !ok {
+		t.Errorf("else branch is %T, want *ast.ExprStmt", max.Else)
+	}
+
+	body := program.Statements[1].(*ast.FunctionDecl).Body.Block
+	if len(body) != 5 {
+		t.Fatalf("body is %d statements, want 5", len(body))
+	}
+	assign := body[0].(*ast.ExprStmt).Expr.(*ast.IfExpr)
+	if _, ok := assign.Then.(*ast.AssignStmt); !ok {
+		t.Errorf("then branch is %T, want *ast.AssignStmt", assign.Then)
+	}
+	if _, ok := assign.Else.(*ast.AssignStmt); !ok {
+		t.Errorf("else branch is %T, want *ast.AssignStmt", assign.Else)
+	}
+	if guard := body[1].(*ast.ExprStmt).Expr.(*ast.IfExpr); guard.Else != nil {
+		t.Errorf("if without else has else branch %v", guard.Else)
+	}
+	if block := body[2].(*ast.ExprStmt).Expr.(*ast.IfExpr); block.Else == nil {
+		t.Errorf("else on the next line should belong to the if")
+	}
+	when := body[3].(*ast.VariableDecl).Value.(*ast.WhenExpr)
+	if len(when.Branches) != 2 || when.Branches[0].Body.(*ast.ExprStmt).Expr.(*ast.IfExpr).Else != nil {
+		t.Errorf("else branch of the when was taken by the if")
+	}
+	branch := body[4].(*ast.ExprStmt).Expr.(*ast.WhenExpr).Branches[0]
+	if _, ok := branch.Body.(*ast.AssignStmt); !ok {
+		t.Errorf("when branch is %T, want *ast.AssignStmt", branch.Body)
+	}
+}
+
+func TestParser_TryWithoutHandler(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("try without catch or finally should not parse")
+		}
+	}()
+	New(scanner.NewScanner(strings.NewReader("val n = try { 1 }"))).Parse()
+}
+
+func TestParser_FileHeader(t *testing.T) {
+	input := `package com.acme.app
+
+import com.acme.util.*
+import com.acme.text.Strings as S
+import com.acme.text.Joiner
+val x = 1`
+
+	s := scanner.NewScanner(strings.NewReader(input))
+	program := New(s).Parse()
+	if program.Package == nil || program.Package.Name != "com.acme.app" {
+		t.Fatalf("package is %#v, want com.acme.app", program.Package)
+	}
+
+	tests := []struct {
+		path     string
+		all      bool
+		imported string
+	}{
+		{"com.acme.util", true, "util"},
+		{"com.acme.text.Strings", false, "S"},
+		{"com.acme.text.Joiner", false, "Joiner"},
+	}
+	if len(program.Imports) != len(tests) {
+		t.Fatalf("program.Imports is %d, want %d", len(program.Imports), len(tests))
+	}
+	for i, test := range tests {
+		directive := program.Imports[i]
+		if directive.Path != test.path || directive.All != test.all || (!test.all && directive.ImportedName() != test.imported) {
+			t.Errorf("import %d is %#v, want %s", i, directive, test.path)
+		}
+	}
+	if len(program.Statements) != 1 {
+		t.Errorf("program.Statements is %d, want 1", len(program.Statements))
+	}
+}
+
+func TestParser_Annotations(t *testing.T) {
+	input := `@Deprecated("use sum")
+@kotlin.jvm.JvmName("plus2")
+fun add(@Named("left") a: Int, b: Int) = a + b
+val n = @Suppress("UNCHECKED_CAST") value
+@Volatile var counter = 0`
+
+	s := scanner.NewScanner(strings.NewReader(input))
+	program := New(s).Parse()
+	if len(program.Statements) != 3 {
+		t.Fatalf("program.Statements is %d, want 3", len(program.Statements))
+	}
+
+	add := program.Statements[0].(*ast.FunctionDecl)
+	if len(add.Annotations) != 2 {
+		t.Fatalf("add has %d annotations, want 2", len(add.Annotations))
+	}
+	deprecated, ok := add.Annotations.Find("Deprecated")
+	if !ok || len(deprecated.Args) != 1 || deprecated.Args[0].(*ast.StringLiteral).Value != "use sum" {
+		t.Errorf("Deprecated annotation is %#v", deprecated)
+	}
+	if jvmName, ok := add.Annotations.Find("JvmName"); !ok || jvmName.Name != "kotlin.jvm.JvmName" {
+		t.Errorf("JvmName should be found by its simple name, got %#v", jvmName)
+	}
+	if !add.Parameters[0].Annotations.Has("Named") || add.Parameters[1].Annotations != nil {
+		t.Errorf("only the first parameter is annotated")
+	}
+
+	value := program.Statements[1].(*ast.VariableDecl).Value.(*ast.AnnotatedExpr)
+	if !value.Annotations.Has("Suppress") {
+		t.Errorf("value annotations are %#v", value.Annotations)
+	}
+	if counter := program.Statements[2].(*ast.VariableDecl); !counter.Annotations.Has("Volatile") {
+		t.Errorf("counter should be annotated with Volatile")
+	}
+}
+
+func TestParser_TypeAliasDecl(t *testing.T) {
+	input := `typealias Handler = (Request, Int) -> Response?
+typealias StringMap<V> = Map<String, List<V>>
+typealias Callback = ((Int) -> Unit)?`
+
+	s := scanner.NewScanner(strings.NewReader(input))
+	program := New(s).Parse()
+	if len(program.Statements) != 3 {
+		t.Fatalf("program.Statements is %d, want 3", len(program.Statements))
+	}
+
+	handler := program.Statements[0].(*ast.TypeAliasDecl)
+	fn, ok := handler.Type.(*ast.FunctionType)
+	if !ok || len(fn.Params) != 2 {
+		t.Fatalf("Handler is %#v, want a function type of two parameters", handler.Type)
+	}
+	if _, ok := fn.Return.(*ast.NullableType); !ok {
+		t.Errorf("Handler returns %#v, want Response?", fn.Return)
+	}
+
+	stringMap := program.Statements[1].(*ast.TypeAliasDecl)
+	if len(stringMap.TypeParams) != 1 || stringMap.TypeParams[0].Spelling != "V" {
+		t.Errorf("StringMap type parameters are %v, want [V]", stringMap.TypeParams)
+	}
+	mapType := stringMap.Type.(*ast.TypeName)
+	if mapType.Name != "Map" || len(mapType.Args) != 2 || len(mapType.Args[1].(*ast.TypeName).Args) != 1 {
+		t.Errorf("StringMap is %#v, want Map<String, List<V>>", mapType)
+	}
+
+	callback := program.Statements[2].(*ast.TypeAliasDecl).Type.(*ast.NullableType)
+	if _, ok := callback.Type.(*ast.FunctionType); !ok {
+		t.Errorf("Callback is %#v, want a nullable function type", callback)
+	}
+}
+
+func TestParser_DestructuringDecl(t *testing.T) {
+	input := `val (name, _, age: Int) = person
+for ((k, v) in entries) log(k)
+for (i in 0..10) {
+	log(i)
+}
+val sum = { (a, b), c: Int -> a + b + c }
+val answer = { 42 }`
+
+	s := scanner.NewScanner(strings.NewReader(input))
+	program := New(s).Parse()
+	if len(program.Statements) != 5 {
+		t.Fatalf("program.Statements is %d, want 5", len(program.Statements))
+	}
+
+	decl := program.Statements[0].(*ast.DestructuringDecl)
+	if len(decl.Pattern.Entries) != 3 || !decl.ReadOnly || decl.Pattern.Entries[2].Type == nil {
+		t.Fatalf("destructuring declaration is %#v", decl.Pattern)
+	}
+
+	entries := program.Statements[1].(*ast.ForStmt)
+	if entries.Pattern == nil || len(entries.Pattern.Entries) != 2 {
+		t.Errorf("loop variable is %#v, want (k, v)", entries.Pattern)
+	}
+	if _, ok := entries.Body.(*ast.ExprStmt); !ok {
+		t.Errorf("loop body is %T, want *ast.ExprStmt", entries.Body)
+	}
+	if loop := program.Statements[2].(*ast.ForStmt); loop.Variable.Spelling != "i" || loop.Pattern != nil {
+		t.Errorf("loop variable is %v, want i", loop.Variable)
+	}
+
+	sum := program.Statements[3].(*ast.VariableDecl).Value.(*ast.LambdaExpr)
+	if len(sum.Parameters) != 2 || sum.Parameters[0].Pattern == nil || sum.Parameters[1].Name.Spelling != "c" || len(sum.Body) != 1 {
+		t.Errorf("lambda is %#v", sum)
+	}
+	if answer := program.Statements[4].(*ast.VariableDecl).Value.(*ast.LambdaExpr); answer.Parameters != nil || len(answer.Body) != 1 {
+		t.Errorf("lambda without parameters is %#v", answer)
+	}
+}
+
+func TestParser_LabeledJumps(t *testing.T) {
+	input := `outer@ for (x in xs) {
+	while (x) { continue@outer }
+	break
+}
+xs.forEach lit@{ return@lit x }
+xs.map { it }
+val y = x ?: return`
+
+	s := scanner.NewScanner(strings.NewReader(input))
+	program := New(s).Parse()
+	if len(program.Statements) != 4 {
+		t.Fatalf("program.Statements is %d, want 4", len(program.Statements))
+	}
+
+	outer := program.Statements[0].(*ast.ForStmt)
+	if outer.Label.Spelling != "outer" {
+		t.Errorf("loop label is %q, want outer", outer.Label.Spelling)
+	}
+	body := outer.Body.(*ast.BlockStmt).Statements
+	inner := body[0].(*ast.WhileStmt).Body.(*ast.BlockStmt).Statements[0].(*ast.ExprStmt).Expr.(*ast.JumpExpr)
+	if inner.Jump.Kind != token.CONTINUE || inner.Label.Spelling != "outer" {
+		t.Errorf("inner jump is %#v, want continue@outer", inner)
+	}
+	if jump := body[1].(*ast.ExprStmt).Expr.(*ast.JumpExpr); jump.Jump.Kind != token.BREAK || jump.Label.Spelling != "" {
+		t.Errorf("outer jump is %#v, want an unlabeled break", jump)
+	}
+
+	forEach := program.Statements[1].(*ast.ExprStmt).Expr.(*ast.CallExpr)
+	lambda := forEach.Args[0].(*ast.LambdaExpr)
+	ret := lambda.Body[0].(*ast.ExprStmt).Expr.(*ast.ReturnExpr)
+	if lambda.Label.Spelling != "lit" || ret.Label.Spelling != "lit" || ret.Value == nil {
+		t.Errorf("labeled lambda is %#v returning %#v", lambda, ret)
+	}
+	if call := program.Statements[2].(*ast.ExprStmt).Expr.(*ast.CallExpr); len(call.Args) != 1 {
+		t.Errorf("trailing lambda call is %#v", call)
+	}
+
+	elvis := program.Statements[3].(*ast.VariableDecl).Value.(*ast.BinaryExpr)
+	if ret, ok := elvis.Right.(*ast.ReturnExpr); !ok || ret.Value != nil {
+		t.Errorf("elvis right operand is %#v, want a bare return", elvis.Right)
+	}
+}
+
+func TestParser_NewLines(t *testing.T) {
+	input := `val sum = a +
+	b
+val name = user
+	?.profile
+	.name
+	?: "unknown"
+log(
+	sum,
+	name
+)
+val n = when (x) {
+	1 ->
+		"one"
+	else -> "many"
+}
+val x = 1
+-x`
+
+	s := scanner.NewScanner(strings.NewReader(input))
+	program := New(s).Parse()
+	if len(program.Statements) != 6 {
+		t.Fatalf("program.Statements is %d, want 6", len(program.Statements))
+	}
+
+	sum := program.Statements[0].(*ast.VariableDecl).Value.(*ast.BinaryExpr)
+	if sum.Op.Kind != token.PLUS {
+		t.Errorf("sum is %#v, want a + b", sum)
+	}
+
+	elvis := program.Statements[1].(*ast.VariableDecl).Value.(*ast.BinaryExpr)
+	member := elvis.Left.(*ast.MemberExpr)
+	if elvis.Op.Kind != token.ELVIS || member.Name.Spelling != "name" || !member.Receiver.(*ast.MemberExpr).Safe {
+		t.Errorf("chain is %#v, want user?.profile.name ?: \"unknown\"", elvis)
+	}
+
+	if call := program.Statements[2].(*ast.ExprStmt).Expr.(*ast.CallExpr); len(call.Args) != 2 {
+		t.Errorf("call has %d arguments, want 2", len(call.Args))
+	}
+
+	when := program.Statements[3].(*ast.VariableDecl).Value.(*ast.WhenExpr)
+	if len(when.Branches) != 2 {
+		t.Errorf("when has %d branches, want 2", len(when.Branches))
+	}
+
+	// A new line before a prefix operator still ends the statement.
+	if _, ok := program.Statements[5].(*ast.ExprStmt).Expr.(*ast.UnaryExpr); !ok {
+		t.Errorf("last statement is %#v, want -x", program.Statements[5])
+	}
+}
+
+func TestParser_VisibilityModifiers(t *testing.T) {
+	input := `internal class Account(private val id: Int, val name: String) {
+	protected fun audit() = id
+}
+private val private = 1`
+
+	s := scanner.NewScanner(strings.NewReader(input))
+	program := New(s).Parse()
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements is %d, want 2", len(program.Statements))
+	}
+
+	class := program.Statements[0].(*ast.ClassDeclStmt)
+	if visibility, _ := class.Modifiers.Visibility(); visibility.Spelling != ast.Internal {
+		t.Errorf("class visibility is %q, want internal", visibility.Spelling)
+	}
+	params := class.PrimaryConstructor.Parameters
+	if !params[0].Modifiers.IsPrivate() || len(params[1].Modifiers) != 0 {
+		t.Errorf("parameter modifiers are %v and %v, want private and none", params[0].Modifiers, params[1].Modifiers)
+	}
+	audit := class.Members[0].(*ast.FunctionDecl)
+	if visibility, _ := audit.Modifiers.Visibility(); visibility.Spelling != ast.Protected {
+		t.Errorf("member visibility is %q, want protected", visibility.Spelling)
+	}
+
+	// Modifiers are soft keywords and remain valid names.
+	if decl := program.Statements[1].(*ast.VariableDecl); !decl.Modifiers.IsPrivate() || decl.Name.Spelling != "private" {
+		t.Errorf("declaration is %#v, want private val private", decl)
+	}
+}
+
+func TestParser_PropertyAccessors(t *testing.T) {
+	input := `class Counter {
+	var count: Int = 0
+		private set
+	var name: String = ""
+		get() = field
+		set(value) { field = value }
+	lateinit var client: Client
+	val size get() = count
+}
+const val MAX = 10
+this.count = 1`
+
+	s := scanner.NewScanner(strings.NewReader(input))
+	program := New(s).Parse()
+	if len(program.Statements) != 3 {
+		t.Fatalf("program.Statements is %d, want 3", len(program.Statements))
+	}
+
+	members := program.Statements[0].(*ast.ClassDeclStmt).Members
+	if len(members) != 4 {
+		t.Fatalf("class has %d members, want 4", len(members))
+	}
+
+	count := members[0].(*ast.VariableDecl)
+	if count.Setter == nil || !count.Setter.Modifiers.IsPrivate() || count.Setter.Body != nil {
+		t.Errorf("count setter is %#v, want a private setter without a body", count.Setter)
+	}
+
+	name := members[1].(*ast.VariableDecl)
+	if name.Getter == nil || name.Setter == nil || name.Setter.Parameter.Name != "value" || len(name.Setter.Body.Block) != 1 {
+		t.Errorf("name accessors are %#v and %#v", name.Getter, name.Setter)
+	}
+
+	if client := members[2].(*ast.VariableDecl); !client.Modifiers.Has("lateinit") || client.Value != nil {
+		t.Errorf("client is %#v, want an uninitialized lateinit property", client)
+	}
+	if size := members[3].(*ast.VariableDecl); size.Getter == nil || size.Setter != nil {
+		t.Errorf("size is %#v, want a getter only", size)
+	}
+	if max := program.Statements[1].(*ast.VariableDecl); !max.Modifiers.Has("const") {
+		t.Errorf("MAX is %#v, want a const val", max)
+	}
+	if assign := program.Statements[2].(*ast.AssignStmt); fmt.Sprintf("%T", assign.Assigne) != "*ast.MemberExpr" {
+		t.Errorf("assignment target is %T, want a member", assign.Assigne)
+	}
+}
+
+func TestParser_Delegation(t *testing.T) {
+	input := `val config by lazy { load() }
+var name: String by
+	Delegates.observable("") { property, old, new -> log(new) }
+class LoggingList(inner: List<String>) : List<String> by inner {
+	fun log() = 1
+}`
+
+	s := scanner.NewScanner(strings.NewReader(input))
+	program := New(s).Parse()
+	if len(program.Statements) != 3 {
+		t.Fatalf("program.Statements is %d, want 3", len(program.Statements))
+	}
+
+	config := program.Statements[0].(*ast.VariableDecl)
+	if call, ok := config.Delegate.(*ast.CallExpr); !ok || config.Value != nil || len(call.Args) != 1 {
+		t.Errorf("config delegate is %#v, want a call of lazy with a lambda", config.Delegate)
+	}
+	name := program.Statements[1].(*ast.VariableDecl)
+	if _, ok := name.Delegate.(*ast.CallExpr); !ok || name.Type == nil {
+		t.Errorf("name delegate is %#v, want a call of Delegates.observable", name.Delegate)
+	}
+
+	class := program.Statements[2].(*ast.ClassDeclStmt)
+	if len(class.SuperTypes) != 1 {
+		t.Fatalf("class has %d supertypes, want 1", len(class.SuperTypes))
+	}
+	if inner, ok := class.SuperTypes[0].Delegate.(*ast.IdentifierExpr); !ok || inner.Value.Spelling != "inner" {
+		t.Errorf("supertype delegate is %#v, want inner", class.SuperTypes[0].Delegate)
+	}
+	if len(class.Members) != 1 {
+		t.Errorf("class has %d members, want 1", len(class.Members))
+	}
+}
+
+func TestParser_NestedClasses(t *testing.T) {
+	input := `class Outer {
+	class Nested; inner class Inner { fun f() = this@Outer.x }
+}
+fun count(from: Int) {
+	class Counter { fun next() = from + 1 }
+}`
+
+	s := scanner.NewScanner(strings.NewReader(input))
+	program := New(s).Parse()
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements is %d, want 2", len(program.Statements))
+	}
+
+	members := program.Statements[0].(*ast.ClassDeclStmt).Members
+	if len(members) != 2 {
+		t.Fatalf("Outer has %d members, want 2", len(members))
+	}
+	inner := members[1].(*ast.ClassDeclStmt)
+	if !inner.Modifiers.Has("inner") {
+		t.Errorf("Inner modifiers are %v, want inner", inner.Modifiers)
+	}
+	body := inner.Members[0].(*ast.FunctionDecl).Body.Expr.(*ast.MemberExpr)
+	if this, ok := body.Receiver.(*ast.ThisExpr); !ok || this.Label.Spelling != "Outer" {
+		t.Errorf("f returns %#v, want a member of this@Outer", body)
+	}
+
+	local := program.Statements[1].(*ast.FunctionDecl).Body.Block
+	if class, ok := local[0].(*ast.ClassDeclStmt); !ok || class.Name.Spelling != "Counter" {
+		t.Errorf("count declares %#v, want the local class Counter", local[0])
+	}
+}
+
+func TestParser_Arguments(t *testing.T) {
+	input := `fun log(msg: String, vararg args: Any?, level: Int = 0) {}
+class Tags(vararg val tags: String)
+log("x", level = 1) { it }
+log(msg = "x")`
+
+	s := scanner.NewScanner(strings.NewReader(input))
+	program := New(s).Parse()
+	if len(program.Statements) != 4 {
+		t.Fatalf("program.Statements is %d, want 4", len(program.Statements))
+	}
+
+	params := program.Statements[0].(*ast.FunctionDecl).Parameters
+	if params[0].Vararg || !params[1].Vararg || params[1].Name != "args" {
+		t.Errorf("log parameters are %#v, want args to be a vararg", params)
+	}
+	if params[1].DefaultValue != nil || params[2].DefaultValue == nil {
+		t.Errorf("log parameters are %#v, want level to have a default", params)
+	}
+	if param := program.Statements[1].(*ast.ClassDeclStmt).PrimaryConstructor.Parameters[0]; !param.Vararg || !param.Property {
+		t.Errorf("Tags parameter is %#v, want a vararg property", param)
+	}
+
+	call := program.Statements[2].(*ast.ExprStmt).Expr.(*ast.CallExpr)
+	if len(call.Args) != 3 || len(call.Names) != 2 || call.Names[0].Spelling != "" || call.Names[1].Spelling != "level" {
+		t.Errorf("first call has %d arguments named %v, want 3 with the second named level", len(call.Args), call.Names)
+	}
+	call = program.Statements[3].(*ast.ExprStmt).Expr.(*ast.CallExpr)
+	if len(call.Names) != 1 || call.Names[0].Spelling != "msg" {
+		t.Errorf("second call has arguments named %v, want msg", call.Names)
+	}
+}
+
+func TestParser_InheritanceModifiers(t *testing.T) {
+	input := `open class Shape {
+	open fun area(): Int = 0
+}
+abstract class Polygon : Shape() {
+	abstract val sides: Int
+	override fun area(): Int = sides
+}`
+
+	s := scanner.NewScanner(strings.NewReader(input))
+	program := New(s).Parse()
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements is %d, want 2", len(program.Statements))
+	}
+
+	shape := program.Statements[0].(*ast.ClassDeclStmt)
+	if !shape.Modifiers.Has("open") || !shape.Members[0].(*ast.FunctionDecl).Modifiers.Has("open") {
+		t.Errorf("Shape and its area are not open")
+	}
+	polygon := program.Statements[1].(*ast.ClassDeclStmt)
+	if !polygon.Modifiers.Has("abstract") || !polygon.Members[0].(*ast.VariableDecl).Modifiers.Has("abstract") {
+		t.Errorf("Polygon and its sides are not abstract")
+	}
+	if !polygon.Members[1].(*ast.FunctionDecl).Modifiers.Has("override") {
+		t.Errorf("Polygon.area does not override")
+	}
+}
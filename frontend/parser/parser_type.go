@@ -7,13 +7,100 @@ import (
 	"gotlin/frontend/token"
 )
 
+// parseUserType parses a simple or qualified type name, such as `Result` or
+// `Result.Ok` for a class nested in Result.
 func (p *Parser) parseUserType() (ast.Type, error) {
 	id, err := p.expected(token.IDENTIFIER)
 	if err != nil {
 		return nil, err
 	}
+
+	name := id.Spelling
+	for p.currentTokenKind() == token.DOT && p.peekKind(1) == token.IDENTIFIER {
+		p.advance()
+		name += "." + p.advance().Spelling
+	}
+
+	var args []ast.Type
+	if p.currentTokenKind() == token.LT {
+		args, err = p.parseTypeArguments()
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &ast.TypeName{
-		Name: id.Spelling,
+		Name:     name,
+		Args:     args,
+		Position: id.Position,
+	}, nil
+}
+
+// parseTypeArguments parses `<String, V>`.
+func (p *Parser) parseTypeArguments() ([]ast.Type, error) {
+	p.advance()
+	var args []ast.Type
+	for {
+		arg, err := p.parseType(Default)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+
+		if p.currentTokenKind() != token.COMMA {
+			break
+		}
+		p.advance()
+	}
+
+	_, err := p.expected(token.GT)
+	if err != nil {
+		return nil, err
+	}
+	return args, nil
+}
+
+// parseFunctionType parses `(Request) -> Response`. Without the arrow the
+// parentheses only group a type, as in `((Int) -> Int)?`.
+func (p *Parser) parseFunctionType() (ast.Type, error) {
+	p.advance()
+	var params []ast.Type
+	for p.hasTokens() && p.currentTokenKind() != token.CLOSE_PAREN {
+		param, err := p.parseType(Default)
+		if err != nil {
+			return nil, err
+		}
+		params = append(params, param)
+
+		if p.currentTokenKind() != token.CLOSE_PAREN {
+			_, err = p.expected(token.COMMA)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	_, err := p.expected(token.CLOSE_PAREN)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.currentTokenKind() != token.ARROW {
+		if len(params) != 1 {
+			return nil, NewError(fmt.Sprintf("expected %s after the parameter types of a function type", token.ARROW))
+		}
+		return params[0], nil
+	}
+	p.advance()
+
+	result, err := p.parseType(Default)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ast.FunctionType{
+		Params: params,
+		Return: result,
 	}, nil
 }
 
@@ -0,0 +1,151 @@
+// Package processor lets an embedding application run its own annotation
+// processors over a loaded module before it is executed.
+package processor
+
+import (
+	"gotlin/frontend/ast"
+	"gotlin/frontend/diag"
+	"gotlin/frontend/module"
+)
+
+// Kinds of annotated declarations.
+const (
+	KindClass     = "class"
+	KindObject    = "object"
+	KindFunction  = "function"
+	KindProperty  = "property"
+	KindParameter = "parameter"
+	KindEnumEntry = "enum entry"
+	KindTypeAlias = "typealias"
+)
+
+// Declaration is an annotated declaration handed to a processor.
+type Declaration struct {
+	Kind    string
+	Package string
+	File    string
+	// Name is qualified with the enclosing declarations, `Suite.check` for
+	// a method or `Suite.check.input` for one of its parameters.
+	Name string
+	// Node is the declaration itself: an *ast.ClassDeclStmt,
+	// *ast.ObjectDeclStmt, *ast.FunctionDecl, *ast.VariableDecl,
+	// *ast.TypeAliasDecl, *ast.ClassParam, *ast.ParameterWithOptionalType or
+	// *ast.EnumEntry.
+	Node        any
+	Annotations ast.Annotations
+}
+
+// Processor handles one annotation on one declaration. Returning an error
+// reports it against the annotation without stopping the other processors.
+type Processor func(decl *Declaration, annotation *ast.Annotation) error
+
+// Registry maps annotation names to the processors registered for them.
+type Registry struct {
+	processors map[string][]Processor
+}
+
+func NewRegistry() *Registry {
+	return &Registry{processors: make(map[string][]Processor)}
+}
+
+// Register adds a processor for the annotation called name, matched
+// against both the qualified and the simple name written in source.
+// Processors for the same annotation run in registration order.
+func (r *Registry) Register(name string, processor Processor) *Registry {
+	r.processors[name] = append(r.processors[name], processor)
+	return r
+}
+
+// Run hands every annotated declaration of m to the processors registered
+// for its annotations, packages in load order and files in source order.
+func (r *Registry) Run(m *module.Module) []error {
+	var errs []error
+	for _, pkg := range m.Packages {
+		for _, file := range pkg.Files {
+			w := &walker{registry: r, pkg: pkg.Name, file: file.Path}
+			w.stmts(file.Program.Statements, "")
+			errs = append(errs, w.errors...)
+		}
+	}
+	return errs
+}
+
+// walker visits the declarations of one file.
+type walker struct {
+	registry *Registry
+	pkg      string
+	file     string
+	errors   []error
+}
+
+func (w *walker) stmts(stmts []ast.Stmt, outer string) {
+	for _, stmt := range stmts {
+		switch decl := stmt.(type) {
+		case *ast.ClassDeclStmt:
+			name := qualify(outer, decl.Name.Spelling)
+			w.process(KindClass, name, decl, decl.Annotations)
+			if decl.PrimaryConstructor != nil {
+				for i := range decl.PrimaryConstructor.Parameters {
+					param := &decl.PrimaryConstructor.Parameters[i]
+					w.process(KindParameter, qualify(name, param.Name), param, param.Annotations)
+				}
+			}
+			for _, entry := range decl.Entries {
+				w.process(KindEnumEntry, qualify(name, entry.Name.Spelling), entry, entry.Annotations)
+				w.stmts(entry.Members, qualify(name, entry.Name.Spelling))
+			}
+			w.stmts(decl.Members, name)
+		case *ast.ObjectDeclStmt:
+			name := qualify(outer, decl.ObjectName())
+			w.process(KindObject, name, decl, decl.Annotations)
+			w.stmts(decl.Members, name)
+		case *ast.FunctionDecl:
+			name := qualify(outer, decl.Name.Spelling)
+			w.process(KindFunction, name, decl, decl.Annotations)
+			for _, param := range decl.Parameters {
+				w.process(KindParameter, qualify(name, param.Name), param, param.Annotations)
+			}
+		case *ast.VariableDecl:
+			w.process(KindProperty, qualify(outer, decl.Name.Spelling), decl, decl.Annotations)
+		case *ast.TypeAliasDecl:
+			w.process(KindTypeAlias, qualify(outer, decl.Name.Spelling), decl, decl.Annotations)
+		}
+	}
+}
+
+func (w *walker) process(kind string, name string, node any, annotations ast.Annotations) {
+	if len(annotations) == 0 {
+		return
+	}
+
+	decl := &Declaration{
+		Kind:        kind,
+		Package:     w.pkg,
+		File:        w.file,
+		Name:        name,
+		Node:        node,
+		Annotations: annotations,
+	}
+	for _, annotation := range annotations {
+		processors := w.registry.processors[annotation.Name]
+		if annotation.SimpleName() != annotation.Name {
+			processors = append(processors, w.registry.processors[annotation.SimpleName()]...)
+		}
+		for _, processor := range processors {
+			if err := processor(decl, annotation); err != nil {
+				w.errors = append(w.errors, &diag.Error{
+					File:    w.file,
+					Pos:     annotation.At.Position,
+					Message: err.Error(),
+				})
+			}
+		}
+	}
+}
+
+func qualify(outer string, name string) string {
+	if outer == "" {
+		return name
+	}
+	return outer + "." + name
+}
@@ -0,0 +1,64 @@
+package processor
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"gotlin/frontend/ast"
+	"gotlin/frontend/module"
+)
+
+func TestRegistry_Run(t *testing.T) {
+	root := fstest.MapFS{
+		"main.gt": {Data: []byte(`import test.Test
+@Test
+fun addition() = 1
+
+class Suite(@Named("size") val n: Int) {
+	@Test fun check(@Named("input") x: Int) = x
+
+	@Deprecated("use check")
+	fun legacy() = 0
+}
+
+enum class Mode { @Deprecated("gone") OLD, NEW }
+`)},
+		"test/test.gt": {Data: []byte("package test\nclass Test\n")},
+	}
+	m, errs := module.NewLoader(root).Load("main.gt")
+	if len(errs) != 0 {
+		t.Fatalf("Load() reported %v", errs)
+	}
+
+	var seen []string
+	record := func(decl *Declaration, annotation *ast.Annotation) error {
+		seen = append(seen, decl.Kind+" "+decl.Name+" @"+annotation.Name)
+		return nil
+	}
+	errs = NewRegistry().
+		Register("Test", record).
+		Register("Named", record).
+		Register("Deprecated", func(decl *Declaration, annotation *ast.Annotation) error {
+			if decl.Kind == KindFunction {
+				return errors.New(decl.Name + " is deprecated")
+			}
+			return record(decl, annotation)
+		}).
+		Run(m)
+
+	want := []string{
+		"function addition @Test",
+		"parameter Suite.n @Named",
+		"function Suite.check @Test",
+		"parameter Suite.check.x @Named",
+		"enum entry Mode.OLD @Deprecated",
+	}
+	if strings.Join(seen, "\n") != strings.Join(want, "\n") {
+		t.Errorf("processors saw\n%s\nwant\n%s", strings.Join(seen, "\n"), strings.Join(want, "\n"))
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "main.gt") || !strings.Contains(errs[0].Error(), "Suite.legacy is deprecated") {
+		t.Errorf("Run() reported %v, want the deprecated function", errs)
+	}
+}
@@ -0,0 +1,22 @@
+package resolver
+
+import (
+	"gotlin/frontend/ast"
+	"gotlin/frontend/token"
+	"gotlin/frontend/types"
+)
+
+// lookupBuiltin returns the standard library declaration called name,
+// which the type checker describes. Symbols are shared by every use.
+func (r *Resolver) lookupBuiltin(name string) (*entry, bool) {
+	if e, ok := r.builtins[name]; ok {
+		return e, true
+	}
+	kind, ok := types.LookupBuiltin(name)
+	if !ok {
+		return nil, false
+	}
+	e := &entry{symbol: &ast.Symbol{Name: token.Token{Kind: token.IDENTIFIER, Spelling: name}, Kind: kind, Storage: ast.StorageBuiltin}}
+	r.builtins[name] = e
+	return e, true
+}
@@ -0,0 +1,276 @@
+package resolver
+
+import (
+	"strings"
+
+	"gotlin/frontend/ast"
+	"gotlin/frontend/token"
+	"gotlin/frontend/types"
+)
+
+// Modifiers that change which names a class body sees.
+const (
+	modifierInner     = "inner"
+	modifierCompanion = "companion"
+	modifierData      = "data"
+	modifierEnum      = "enum"
+	modifierConst     = "const"
+)
+
+// classScopes are the scopes of the body of a class or object.
+type classScopes struct {
+	static   *scope
+	instance *scope
+}
+
+// resolveClass resolves a class or interface declared in s.
+func (r *Resolver) resolveClass(decl *ast.ClassDeclStmt, s *scope) {
+	scopes := r.scopesOf(decl, s)
+	if scopes == nil {
+		return
+	}
+
+	init := newScope(scopes.instance, scopeConstructor, newFrame(scopes.instance.frame))
+	if decl.PrimaryConstructor != nil {
+		for i := range decl.PrimaryConstructor.Parameters {
+			param := &decl.PrimaryConstructor.Parameters[i]
+			if param.DefaultValue != nil {
+				r.resolveExpr(param.DefaultValue, init)
+			}
+			name := token.Token{Kind: token.IDENTIFIER, Spelling: param.Name, Position: param.Position}
+			r.declare(init, name, ast.SymbolParameter, ast.StorageLocal, true).symbol.Decl = param
+		}
+	}
+	r.resolveSuperTypes(decl.SuperTypes, init)
+
+	for _, entry := range decl.Entries {
+		for _, arg := range entry.Args {
+			r.resolveExpr(arg, scopes.static)
+		}
+		if len(entry.Members) > 0 {
+			body := r.bodyScopes(entry.Members, scopes.static, []*scope{scopes.instance})
+			r.resolveBody(entry.Members, body)
+		}
+	}
+	r.resolveMembers(decl.Members, scopes.static, scopes.instance, init)
+}
+
+// resolveObject resolves an object declaration made in s.
+func (r *Resolver) resolveObject(decl *ast.ObjectDeclStmt, s *scope) {
+	if scopes := r.scopesOf(decl, s); scopes != nil {
+		r.resolveBody(decl.Members, scopes)
+	}
+}
+
+// resolveObjectExpr resolves an anonymous object, whose body sees
+// everything around it.
+func (r *Resolver) resolveObjectExpr(expr *ast.ObjectExpr, s *scope) {
+	r.resolveSuperTypes(expr.SuperTypes, s)
+	if scopes := r.scopesOf(expr, s); scopes != nil {
+		r.resolveBody(expr.Members, scopes)
+	}
+}
+
+// resolveBody resolves the members of a body without constructor
+// parameters, whose initializers run in a frame of their own.
+func (r *Resolver) resolveBody(members []ast.Stmt, scopes *classScopes) {
+	init := newScope(scopes.instance, scopeConstructor, newFrame(scopes.instance.frame))
+	r.resolveMembers(members, scopes.static, scopes.instance, init)
+}
+
+func (r *Resolver) resolveSuperTypes(superTypes []*ast.SuperType, s *scope) {
+	for _, superType := range superTypes {
+		for _, arg := range superType.Args {
+			r.resolveExpr(arg, s)
+		}
+		if superType.Delegate != nil {
+			r.resolveExpr(superType.Delegate, s)
+		}
+	}
+}
+
+// scopesOf returns the scopes of the body of a class, object declaration or
+// anonymous object declared in s, building them on first use. A class that
+// is not inner only sees the static scope of the class around it. It
+// returns nil for a class whose supertypes lead back to it.
+func (r *Resolver) scopesOf(decl any, s *scope) *classScopes {
+	if scopes, ok := r.classes[decl]; ok {
+		return scopes
+	}
+	r.classes[decl] = nil
+	// The scopes of a class are built when first needed, which may be
+	// while resolving another file of the package.
+	if file, ok := r.files[decl]; ok {
+		previous := r.file
+		r.file = file
+		defer func() { r.file = previous }()
+	}
+
+	var scopes *classScopes
+	switch d := decl.(type) {
+	case *ast.ClassDeclStmt:
+		if !d.Modifiers.Has(modifierInner) {
+			s = s.static()
+		}
+		scopes = r.bodyScopes(d.Members, s, r.superScopes(d.SuperTypes, s))
+		r.declareClassMembers(d, scopes)
+	case *ast.ObjectDeclStmt:
+		s = s.static()
+		scopes = r.bodyScopes(d.Members, s, r.superScopes(d.SuperTypes, s))
+	case *ast.ObjectExpr:
+		scopes = r.bodyScopes(d.Members, s, r.superScopes(d.SuperTypes, s))
+	}
+	r.classes[decl] = scopes
+	return scopes
+}
+
+// bodyScopes declares the members of a body nested in s that inherits the
+// members of supers. A nil super is a supertype the resolver does not
+// know, whose members could be any.
+func (r *Resolver) bodyScopes(members []ast.Stmt, s *scope, supers []*scope) *classScopes {
+	static := newScope(s, scopeStatic, s.frame)
+	instance := newScope(static, scopeInstance, s.frame)
+	for _, super := range supers {
+		if super == nil {
+			instance.open = true
+		} else {
+			instance.supers = append(instance.supers, super)
+		}
+	}
+	scopes := &classScopes{static: static, instance: instance}
+
+	r.declareMembers(members, static, instance, ast.StorageMember, true)
+	for _, member := range members {
+		if object, ok := member.(*ast.ObjectDeclStmt); ok && object.Modifiers.Has(modifierCompanion) {
+			if companion := r.scopesOf(object, instance); companion != nil {
+				static.supers = append(static.supers, companion.instance)
+			}
+		}
+	}
+	return scopes
+}
+
+// declareClassMembers declares the properties of the primary constructor
+// of a class, its enum entries and the members the language gives enums.
+func (r *Resolver) declareClassMembers(decl *ast.ClassDeclStmt, scopes *classScopes) {
+	if decl.PrimaryConstructor != nil {
+		for i := range decl.PrimaryConstructor.Parameters {
+			param := &decl.PrimaryConstructor.Parameters[i]
+			if param.Property {
+				// A parameter declared twice is reported as a parameter.
+				report := true
+				if previous, ok := scopes.instance.names[param.Name]; ok {
+					_, repeated := previous.symbol.Decl.(*ast.ClassParam)
+					report = !repeated
+				}
+				name := token.Token{Kind: token.IDENTIFIER, Spelling: param.Name, Position: param.Position}
+				r.declare(scopes.instance, name, variableKind(param.ReadOnly), ast.StorageMember, report).symbol.Decl = param
+			}
+		}
+	}
+	if decl.Modifiers.Has(modifierData) {
+		r.synthesize(scopes.instance, decl.Name, ast.SymbolFunction, "copy")
+	}
+	if len(decl.Entries) == 0 && !decl.Modifiers.Has(modifierEnum) {
+		return
+	}
+	for _, entry := range decl.Entries {
+		r.declare(scopes.static, entry.Name, ast.SymbolObject, ast.StorageGlobal, true).symbol.Decl = entry
+	}
+	r.synthesize(scopes.instance, decl.Name, ast.SymbolValue, "name", "ordinal")
+	r.synthesize(scopes.static, decl.Name, ast.SymbolFunction, "values", "valueOf")
+	r.synthesize(scopes.static, decl.Name, ast.SymbolValue, "entries")
+}
+
+// synthesize declares members the language generates for the class called
+// name, unless the class declares them itself.
+func (r *Resolver) synthesize(s *scope, class token.Token, kind string, names ...string) {
+	for _, name := range names {
+		if _, ok := s.names[name]; !ok {
+			member := class
+			member.Spelling = name
+			r.declare(s, member, kind, ast.StorageBuiltin, false)
+		}
+	}
+}
+
+// superScopes returns the instance scopes of supertypes, nil for those the
+// resolver does not know.
+func (r *Resolver) superScopes(superTypes []*ast.SuperType, s *scope) []*scope {
+	var supers []*scope
+	for _, superType := range superTypes {
+		name, ok := superType.Type.(*ast.TypeName)
+		if ok && name.Name == "Any" {
+			continue
+		}
+		var scopes *classScopes
+		if ok {
+			scopes = r.lookupClass(name.Name, s)
+		}
+		if scopes == nil {
+			supers = append(supers, nil)
+		} else {
+			supers = append(supers, scopes.instance)
+		}
+	}
+	return supers
+}
+
+// receiverScope is the scope of the body of an extension of receiver
+// declared in s, which sees the members of the receiver. The members of a
+// built-in receiver are those the type checker describes, and those of a
+// receiver the resolver does not know could be any.
+func (r *Resolver) receiverScope(receiver ast.Type, s *scope) *scope {
+	if nullable, ok := receiver.(*ast.NullableType); ok {
+		receiver = nullable.Type
+	}
+	extension := newScope(s, scopeBlock, s.frame)
+	name, ok := receiver.(*ast.TypeName)
+	var scopes *classScopes
+	var declared *entry
+	if ok {
+		scopes = r.lookupClass(name.Name, s)
+		declared, _ = s.lookup(name.Name)
+	}
+	switch {
+	case scopes != nil:
+		extension.supers = []*scope{scopes.instance}
+	case ok && declared == nil && types.IsBuiltinClass(name.Name):
+		extension.builtin = name.Name
+	default:
+		extension.open = true
+	}
+	return extension
+}
+
+// lookupClass finds the scopes of the class or object a possibly qualified
+// type name refers to from s, following type aliases.
+func (r *Resolver) lookupClass(name string, s *scope) *classScopes {
+	return r.followClass(name, s, make(map[*ast.TypeAliasDecl]bool))
+}
+
+// followClass looks a type name up as lookupClass does. followed holds the
+// aliases already expanded, which cannot refer back to themselves.
+func (r *Resolver) followClass(name string, s *scope, followed map[*ast.TypeAliasDecl]bool) *classScopes {
+	segments := strings.Split(name, ".")
+	e, _ := s.lookup(segments[0])
+	for i := 0; e != nil; i++ {
+		var scopes *classScopes
+		switch decl := e.decl.(type) {
+		case *ast.ClassDeclStmt, *ast.ObjectDeclStmt:
+			scopes = r.scopesOf(decl, e.scope)
+		case *ast.TypeAliasDecl:
+			target, ok := decl.Type.(*ast.TypeName)
+			if !ok || followed[decl] || len(segments) > 1 {
+				return nil
+			}
+			followed[decl] = true
+			return r.followClass(target.Name, e.scope, followed)
+		}
+		if scopes == nil || i+1 == len(segments) {
+			return scopes
+		}
+		e, _ = scopes.static.member(segments[i+1])
+	}
+	return nil
+}
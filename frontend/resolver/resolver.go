@@ -0,0 +1,561 @@
+// Package resolver binds the names a program uses to their declarations. It
+// builds the lexical scopes of every file, records on each IdentifierExpr
+// the declaration it refers to, with the frame depth and slot the compiler
+// needs, and reports the names that cannot be used where they are.
+package resolver
+
+import (
+	"strings"
+
+	"gotlin/frontend/ast"
+	"gotlin/frontend/diag"
+	"gotlin/frontend/token"
+	"gotlin/frontend/types"
+)
+
+// implicitParameter is the parameter of a lambda that declares none.
+const implicitParameter = "it"
+
+type Resolver struct {
+	errors []error
+	// file is the path of the file being resolved, which its diagnostics
+	// name, and files holds the file of each class and object declared.
+	file     string
+	files    map[any]string
+	builtins map[string]*entry
+	// packages holds the first segment of the name of every known package,
+	// which qualified references start with.
+	packages map[string]bool
+	// classes caches the scopes of the classes and objects by declaration.
+	// A nil entry marks a class whose scopes are being built.
+	classes map[any]*classScopes
+	globals int
+	// uninitialized holds the properties of the file or class body being
+	// resolved whose initializer has not run yet, and initializing the
+	// frame those initializers run in.
+	uninitialized map[*ast.Symbol]bool
+	initializing  *frame
+}
+
+func New() *Resolver {
+	return &Resolver{}
+}
+
+func (r *Resolver) Resolve(program *ast.Program) []error {
+	return r.ResolvePackage([]*ast.Program{program}, nil)
+}
+
+// ResolvePackage resolves the files of a package together, so that each
+// sees the top-level declarations of the others. exports lists the
+// top-level names of the packages the files may import from, by package
+// name. Top-level names declared twice are left to the module loader.
+func (r *Resolver) ResolvePackage(programs []*ast.Program, exports map[string][]string) []error {
+	r.errors = nil
+	r.builtins = make(map[string]*entry)
+	r.packages = make(map[string]bool)
+	r.classes = make(map[any]*classScopes)
+	r.files = make(map[any]string)
+	r.globals = 0
+	for name := range exports {
+		r.packages[rootName(name)] = true
+	}
+
+	// Every file sees the declarations of the package, and its own imports
+	// behind them.
+	topLevel := make(map[string]*entry)
+	files := make([]*scope, len(programs))
+	for i, program := range programs {
+		r.file = program.File
+		if program.Package != nil {
+			r.packages[rootName(program.Package.Name)] = true
+		}
+		imports := newScope(nil, scopeStatic, nil)
+		r.declareImports(program.Imports, imports, exports)
+		files[i] = newScope(imports, scopeStatic, newFrame(nil))
+		files[i].names = topLevel
+		r.declareMembers(program.Statements, files[i], files[i], ast.StorageGlobal, false)
+	}
+	for i, program := range programs {
+		r.file = program.File
+		r.resolveMembers(program.Statements, files[i], files[i], files[i])
+	}
+	return r.errors
+}
+
+func (r *Resolver) report(pos token.Pos, format string, args ...any) {
+	err := diag.Errorf(pos, format, args...)
+	err.File = r.file
+	r.errors = append(r.errors, err)
+}
+
+func rootName(name string) string {
+	root, _, _ := strings.Cut(name, ".")
+	return root
+}
+
+func (r *Resolver) declareImports(imports []*ast.ImportDirective, s *scope, exports map[string][]string) {
+	for _, directive := range imports {
+		name := directive.Import
+		if types.IsStandardPackage(directive.Package()) {
+			// The standard library is seen without imports, and an alias
+			// is another name for one of its declarations.
+			if directive.Alias.Spelling != "" {
+				if e, ok := r.lookupBuiltin(directive.Path[len(directive.Package())+1:]); ok {
+					s.names[directive.Alias.Spelling] = e
+				}
+			}
+			continue
+		}
+		if !directive.All {
+			name.Spelling = directive.ImportedName()
+			e, _ := s.declare(name, ast.SymbolImport, ast.StorageGlobal)
+			e.symbol.Decl = directive
+			continue
+		}
+		for _, exported := range exports[directive.Path] {
+			name.Spelling = exported
+			e, _ := s.declare(name, ast.SymbolImport, ast.StorageGlobal)
+			e.symbol.Decl = directive
+		}
+	}
+}
+
+// declare adds a declaration to s. Globals are numbered across the package.
+func (r *Resolver) declare(s *scope, name token.Token, kind string, storage ast.Storage, report bool) *entry {
+	e, redeclared := s.declare(name, kind, storage)
+	e.scope = s
+	if storage == ast.StorageGlobal {
+		e.symbol.Slot = r.globals
+		r.globals++
+	}
+	if redeclared && report {
+		r.report(name.Position, "Redeclaration: %s", name.Spelling)
+	}
+	return e
+}
+
+// declareMembers declares the names the declarations of a file or of a
+// class body introduce: nested classes, objects and type aliases in static,
+// and properties and functions in instance.
+func (r *Resolver) declareMembers(stmts []ast.Stmt, static *scope, instance *scope, storage ast.Storage, report bool) {
+	for _, stmt := range stmts {
+		switch decl := stmt.(type) {
+		case *ast.VariableDecl:
+			if decl.Receiver == nil {
+				r.declare(instance, decl.Name, variableKind(decl.ReadOnly), storage, report).symbol.Decl = decl
+			}
+		case *ast.FunctionDecl:
+			if decl.Receiver == nil && decl.Name.Spelling != "" {
+				r.declare(instance, decl.Name, ast.SymbolFunction, storage, report).symbol.Decl = decl
+			}
+		case *ast.DestructuringDecl:
+			for _, entry := range decl.Pattern.Entries {
+				if entry.Name.Spelling != ast.Underscore {
+					r.declare(instance, entry.Name, variableKind(decl.ReadOnly), storage, report).symbol.Decl = entry
+				}
+			}
+		case *ast.ClassDeclStmt:
+			r.files[decl] = r.file
+			r.declare(static, decl.Name, ast.SymbolClass, ast.StorageGlobal, report).setDecl(decl)
+		case *ast.ObjectDeclStmt:
+			r.files[decl] = r.file
+			r.declare(static, objectName(decl), ast.SymbolObject, ast.StorageGlobal, report).setDecl(decl)
+		case *ast.TypeAliasDecl:
+			r.declare(static, decl.Name, ast.SymbolTypeAlias, ast.StorageGlobal, report).setDecl(decl)
+		}
+	}
+}
+
+func variableKind(readOnly bool) string {
+	if readOnly {
+		return ast.SymbolValue
+	}
+	return ast.SymbolVariable
+}
+
+func objectName(decl *ast.ObjectDeclStmt) token.Token {
+	name := decl.Name
+	name.Spelling = decl.ObjectName()
+	if decl.Name.Spelling == "" {
+		name.Position = decl.Object.Position
+	}
+	return name
+}
+
+// resolveMembers resolves the declarations of a file or class body, whose
+// names are already declared in static and instance. Initializers run in
+// init, in declaration order, so they cannot read the properties declared
+// after them.
+func (r *Resolver) resolveMembers(stmts []ast.Stmt, static *scope, instance *scope, init *scope) {
+	uninitialized, initializing := r.uninitialized, r.initializing
+	defer func() { r.uninitialized, r.initializing = uninitialized, initializing }()
+	r.uninitialized = make(map[*ast.Symbol]bool)
+	r.initializing = init.frame
+	for _, stmt := range stmts {
+		decl, ok := stmt.(*ast.VariableDecl)
+		if ok && decl.Receiver == nil && (decl.Value != nil || decl.Delegate != nil) && !decl.Modifiers.Has(modifierConst) {
+			if e := instance.names[decl.Name.Spelling]; e != nil && e.symbol.Name == decl.Name {
+				r.uninitialized[e.symbol] = true
+			}
+		}
+	}
+
+	for _, stmt := range stmts {
+		switch decl := stmt.(type) {
+		case *ast.VariableDecl:
+			r.resolveProperty(decl, instance, init)
+		case *ast.FunctionDecl:
+			r.resolveFunction(decl, instance)
+		case *ast.DestructuringDecl:
+			r.resolveExpr(decl.Value, init)
+		case *ast.ClassDeclStmt:
+			r.resolveClass(decl, instance)
+		case *ast.ObjectDeclStmt:
+			r.resolveObject(decl, instance)
+		case *ast.TypeAliasDecl:
+		default:
+			r.resolveStmt(stmt, init)
+		}
+	}
+}
+
+// resolveProperty resolves a property of a file or class body, whose
+// initializer runs in init and whose accessors are members of instance.
+func (r *Resolver) resolveProperty(decl *ast.VariableDecl, instance *scope, init *scope) {
+	if decl.Value != nil {
+		r.resolveExpr(decl.Value, init)
+	}
+	if decl.Delegate != nil {
+		r.resolveExpr(decl.Delegate, init)
+	}
+	if e := instance.names[decl.Name.Spelling]; e != nil {
+		delete(r.uninitialized, e.symbol)
+	}
+
+	owner := instance
+	if decl.Receiver != nil {
+		owner = r.receiverScope(decl.Receiver, instance)
+	}
+	r.resolveAccessor(decl, decl.Getter, owner)
+	r.resolveAccessor(decl, decl.Setter, owner)
+}
+
+// resolveAccessor resolves a getter or setter of property, whose body sees
+// the backing field and, for a setter, the value being set.
+func (r *Resolver) resolveAccessor(property *ast.VariableDecl, accessor *ast.PropertyAccessor, s *scope) {
+	if accessor == nil || accessor.Body == nil {
+		return
+	}
+	params := s.function()
+	field := accessor.Keyword
+	field.Spelling = ast.BackingField
+	r.declare(params, field, ast.SymbolVariable, ast.StorageMember, false).symbol.Decl = property
+	if accessor.Parameter != nil {
+		name := token.Token{Kind: token.IDENTIFIER, Spelling: accessor.Parameter.Name, Position: accessor.Parameter.Position}
+		r.declare(params, name, ast.SymbolParameter, ast.StorageLocal, false).symbol.Decl = accessor.Parameter
+	}
+	r.resolveFunctionBody(accessor.Body, params)
+}
+
+// resolveFunction resolves a function declared in s. The body of an
+// extension also sees the members of its receiver.
+func (r *Resolver) resolveFunction(decl *ast.FunctionDecl, s *scope) {
+	if decl.Receiver != nil {
+		s = r.receiverScope(decl.Receiver, s)
+	}
+	params := s.function()
+	r.declareParameters(decl.Parameters, params)
+	r.resolveFunctionBody(decl.Body, params)
+}
+
+// declareParameters declares parameters in s, the default value of each
+// seeing those before it.
+func (r *Resolver) declareParameters(parameters []*ast.ParameterWithOptionalType, s *scope) {
+	for _, param := range parameters {
+		if param.DefaultValue != nil {
+			r.resolveExpr(param.DefaultValue, s)
+		}
+		name := token.Token{Kind: token.IDENTIFIER, Spelling: param.Name, Position: param.Position}
+		r.declare(s, name, ast.SymbolParameter, ast.StorageLocal, true).symbol.Decl = param
+	}
+}
+
+func (r *Resolver) resolveFunctionBody(body *ast.FunctionBody, s *scope) {
+	if body == nil {
+		return
+	}
+	if body.Expr != nil {
+		r.resolveExpr(body.Expr, s)
+	}
+	if body.Block != nil {
+		r.resolveBlock(body.Block, s)
+	}
+}
+
+// resolveBlock resolves the statements of a block nested in s. Each local
+// is only visible after its declaration.
+func (r *Resolver) resolveBlock(stmts []ast.Stmt, s *scope) {
+	block := s.block()
+	block.later = localNames(stmts)
+	for _, stmt := range stmts {
+		r.resolveStmt(stmt, block)
+	}
+	block.end()
+}
+
+// localNames returns the names declared among stmts, with the position of
+// their first declaration.
+func localNames(stmts []ast.Stmt) map[string]token.Pos {
+	names := make(map[string]token.Pos)
+	add := func(name token.Token) {
+		if _, ok := names[name.Spelling]; !ok && name.Spelling != ast.Underscore {
+			names[name.Spelling] = name.Position
+		}
+	}
+	for _, stmt := range stmts {
+		switch decl := stmt.(type) {
+		case *ast.VariableDecl:
+			add(decl.Name)
+		case *ast.FunctionDecl:
+			add(decl.Name)
+		case *ast.ClassDeclStmt:
+			add(decl.Name)
+		case *ast.ObjectDeclStmt:
+			add(objectName(decl))
+		case *ast.DestructuringDecl:
+			for _, entry := range decl.Pattern.Entries {
+				add(entry.Name)
+			}
+		}
+	}
+	return names
+}
+
+// resolveStmt resolves a statement of a block, which declares locals in s.
+func (r *Resolver) resolveStmt(stmt ast.Stmt, s *scope) {
+	switch st := stmt.(type) {
+	case *ast.ExprStmt:
+		r.resolveExpr(st.Expr, s)
+	case *ast.BlockStmt:
+		r.resolveBlock(st.Statements, s)
+	case *ast.VariableDecl:
+		if st.Value != nil {
+			r.resolveExpr(st.Value, s)
+		}
+		if st.Delegate != nil {
+			r.resolveExpr(st.Delegate, s)
+		}
+		e := r.declare(s, st.Name, variableKind(st.ReadOnly), ast.StorageLocal, true)
+		e.symbol.Decl = st
+		e.deferred = st.ReadOnly && st.Value == nil && st.Delegate == nil
+	case *ast.DestructuringDecl:
+		r.resolveExpr(st.Value, s)
+		r.declarePattern(st.Pattern, variableKind(st.ReadOnly), s)
+	case *ast.FunctionDecl:
+		r.declare(s, st.Name, ast.SymbolFunction, ast.StorageLocal, true).symbol.Decl = st
+		r.resolveFunction(st, s)
+	case *ast.ClassDeclStmt:
+		r.declare(s, st.Name, ast.SymbolClass, ast.StorageLocal, true).setDecl(st)
+		r.resolveClass(st, s)
+	case *ast.ObjectDeclStmt:
+		r.declare(s, objectName(st), ast.SymbolObject, ast.StorageLocal, true).setDecl(st)
+		r.resolveObject(st, s)
+	case *ast.ForStmt:
+		r.resolveExpr(st.Iterable, s)
+		loop := s.block()
+		if st.Pattern != nil {
+			r.declarePattern(st.Pattern, ast.SymbolValue, loop)
+		} else {
+			r.declare(loop, st.Variable, ast.SymbolValue, ast.StorageLocal, false).symbol.Decl = st
+		}
+		r.resolveStmt(st.Body, loop)
+		loop.end()
+	case *ast.WhileStmt:
+		r.resolveExpr(st.Condition, s)
+		r.resolveStmt(st.Body, s)
+	case *ast.AssignStmt:
+		r.resolveExpr(st.Value, s)
+		r.resolveAssignment(st.Assigne, s)
+	}
+}
+
+// declarePattern declares the entries of a destructuring pattern. The
+// checker reports the names a pattern binds twice.
+func (r *Resolver) declarePattern(pattern *ast.DestructuringPattern, kind string, s *scope) {
+	for _, entry := range pattern.Entries {
+		if entry.Name.Spelling != ast.Underscore {
+			r.declare(s, entry.Name, kind, ast.StorageLocal, false).symbol.Decl = entry
+		}
+	}
+}
+
+// resolveAssignment resolves the target of an assignment, which must be a
+// variable: a val can only be assigned when it is declared without an
+// initializer.
+func (r *Resolver) resolveAssignment(target ast.Expr, s *scope) {
+	id, ok := target.(*ast.IdentifierExpr)
+	if !ok {
+		r.resolveExpr(target, s)
+		return
+	}
+	e := r.bind(id, s, false)
+	if e == nil {
+		return
+	}
+	switch e.symbol.Kind {
+	case ast.SymbolValue, ast.SymbolParameter:
+		if !e.deferred {
+			r.report(id.Value.Position, "Val cannot be reassigned")
+		}
+	case ast.SymbolFunction, ast.SymbolClass, ast.SymbolObject, ast.SymbolTypeAlias:
+		r.report(id.Value.Position, "Variable expected")
+	}
+}
+
+func (r *Resolver) resolveExpr(expr ast.Expr, s *scope) {
+	switch e := expr.(type) {
+	case *ast.IdentifierExpr:
+		r.read(e, s, false)
+	case *ast.MemberExpr:
+		if root, ok := e.Receiver.(*ast.IdentifierExpr); ok {
+			r.read(root, s, true)
+		} else {
+			r.resolveExpr(e.Receiver, s)
+		}
+	case *ast.BinaryExpr:
+		r.resolveExpr(e.Left, s)
+		r.resolveExpr(e.Right, s)
+	case *ast.InfixCallExpr:
+		r.resolveExpr(e.Left, s)
+		r.resolveExpr(e.Right, s)
+	case *ast.UnaryExpr:
+		r.resolveExpr(e.Right, s)
+	case *ast.GroupingExpr:
+		r.resolveExpr(e.Expr, s)
+	case *ast.NonNullableExpr:
+		r.resolveExpr(e.Expr, s)
+	case *ast.IsExpr:
+		r.resolveExpr(e.Expr, s)
+	case *ast.AnnotatedExpr:
+		r.resolveExpr(e.Expr, s)
+	case *ast.CallExpr:
+		r.resolveExpr(e.Callee, s)
+		withReceiver := types.ReceiverLambda(calleeName(e.Callee))
+		for _, arg := range e.Args {
+			if lambda, ok := arg.(*ast.LambdaExpr); ok {
+				r.resolveLambda(lambda, s, withReceiver)
+			} else {
+				r.resolveExpr(arg, s)
+			}
+		}
+	case *ast.LambdaExpr:
+		r.resolveLambda(e, s, false)
+	case *ast.FunctionLiteral:
+		params := s.function()
+		r.declareParameters(e.Parameters, params)
+		r.resolveFunctionBody(e.Body, params)
+	case *ast.ReturnExpr:
+		if e.Value != nil {
+			r.resolveExpr(e.Value, s)
+		}
+	case *ast.ThrowExpr:
+		r.resolveExpr(e.Expr, s)
+	case *ast.IfExpr:
+		r.resolveExpr(e.Condition, s)
+		r.resolveStmt(e.Then, s)
+		if e.Else != nil {
+			r.resolveStmt(e.Else, s)
+		}
+	case *ast.WhenExpr:
+		if e.Subject != nil {
+			r.resolveExpr(e.Subject, s)
+		}
+		for _, branch := range e.Branches {
+			for _, condition := range branch.Conditions {
+				if condition.Expr != nil {
+					r.resolveExpr(condition.Expr, s)
+				}
+			}
+			r.resolveStmt(branch.Body, s)
+		}
+	case *ast.TryExpr:
+		r.resolveBlock(e.Body.Statements, s)
+		for _, catch := range e.Catches {
+			clause := s.block()
+			r.declare(clause, catch.Name, ast.SymbolValue, ast.StorageLocal, false).symbol.Decl = catch
+			r.resolveBlock(catch.Body.Statements, clause)
+			clause.end()
+		}
+		if e.Finally != nil {
+			r.resolveBlock(e.Finally.Statements, s)
+		}
+	case *ast.ObjectExpr:
+		r.resolveObjectExpr(e, s)
+	}
+}
+
+// calleeName is the name of the function a call invokes, as written.
+func calleeName(callee ast.Expr) string {
+	switch c := callee.(type) {
+	case *ast.IdentifierExpr:
+		return c.Value.Spelling
+	case *ast.MemberExpr:
+		return c.Name.Spelling
+	}
+	return ""
+}
+
+// resolveLambda resolves a lambda nested in s. A lambda run with a receiver
+// may use its members, which the resolver cannot see.
+func (r *Resolver) resolveLambda(lambda *ast.LambdaExpr, s *scope, withReceiver bool) {
+	params := s.function()
+	params.open = withReceiver
+	if len(lambda.Parameters) == 0 {
+		it := lambda.Open
+		it.Kind, it.Spelling = token.IDENTIFIER, implicitParameter
+		r.declare(params, it, ast.SymbolParameter, ast.StorageLocal, false).symbol.Decl = lambda
+	}
+	for _, param := range lambda.Parameters {
+		if param.Pattern != nil {
+			r.declarePattern(param.Pattern, ast.SymbolValue, params)
+		} else if param.Name.Spelling != ast.Underscore {
+			r.declare(params, param.Name, ast.SymbolParameter, ast.StorageLocal, false).symbol.Decl = param
+		}
+	}
+	r.resolveBlock(lambda.Body, params)
+}
+
+// read resolves a name whose value is read. A property cannot be read by
+// the initializers that run before its own.
+func (r *Resolver) read(id *ast.IdentifierExpr, s *scope, qualifier bool) {
+	e := r.bind(id, s, qualifier)
+	if e != nil && r.uninitialized[e.symbol] && s.frame == r.initializing {
+		r.report(id.Value.Position, "Variable '%s' must be initialized", id.Value.Spelling)
+	}
+}
+
+// bind records the declaration a name used in s refers to. A qualifier is
+// the first segment of a qualified name, which may also name a package.
+func (r *Resolver) bind(id *ast.IdentifierExpr, s *scope, qualifier bool) *entry {
+	name := id.Value.Spelling
+	e, open := s.lookup(name)
+	if e == nil {
+		e, _ = r.lookupBuiltin(name)
+	}
+	if e != nil {
+		id.Binding = &ast.Binding{Symbol: e.symbol}
+		if e.symbol.Storage == ast.StorageLocal {
+			id.Binding.Depth = s.frame.level - e.frame.level
+		}
+		return e
+	}
+
+	switch pos, later := s.declaredLater(name); {
+	case open || qualifier && r.packages[name]:
+	case later:
+		r.report(id.Value.Position, "Cannot use '%s' before it is declared (declared at %s)", name, pos)
+	default:
+		r.report(id.Value.Position, "Unresolved reference: %s", name)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package resolver
+
+import (
+	"strings"
+	"testing"
+
+	"gotlin/frontend/ast"
+	"gotlin/frontend/parser"
+	"gotlin/frontend/scanner"
+)
+
+func parse(input string) *ast.Program {
+	return parser.New(scanner.NewScanner(strings.NewReader(input))).Parse()
+}
+
+func resolve(input string) []error {
+	return New().Resolve(parse(input))
+}
+
+func TestResolver_Diagnostics(t *testing.T) {
+	tests := []struct {
+		input  string
+		errors []string
+	}{
+		{`val limit = 10
+		class Counter(start: Int, val step: Int) : Comparable<Counter> {
+			var count = start
+			companion object { const val MAX = 100 }
+			fun next(): Int {
+				count = count + step
+				return minOf(count, MAX, limit)
+			}
+			fun compareTo(other: Counter) = count - other.count
+		}
+		fun String.shout() = uppercase() + "!"
+		fun sum(values: List<Int>): Int {
+			var total = 0
+			for (value in values) { total = total + value }
+			values.forEach { total = total + it }
+			val (first, _) = Pair(1, 2)
+			return total + first
+		}
+		fun later() = earlier()
+		fun earlier() = with(Counter(1, 1)) { next() }`, nil},
+		{"fun f() = missing", []string{"Unresolved reference: missing"}},
+		{"val y = 1\nval z = q\n", []string{"[2, 9] Unresolved reference: q"}},
+		{"fun f() { log(x)\nval x = 1 }", []string{"Cannot use 'x' before it is declared (declared at [2, 5])"}},
+		{"fun f() { val x = 1\nval x = 2 }", []string{"Redeclaration: x"}},
+		{"fun f(a: Int, a: Int) = a", []string{"Redeclaration: a"}},
+		{"fun f(a: Int, b: Int = a) = b", nil},
+		{"fun f(a: Int = b, b: Int = 0) = a", []string{"Unresolved reference: b"}},
+		{"class A(val x: Int) { val x = 2 }", []string{"Redeclaration: x"}},
+		{"class D(val v: Int, val v: Int)", []string{"[1, 25] Redeclaration: v"}},
+		{"fun f() { val x = 1\nx = 2 }", []string{"Val cannot be reassigned"}},
+		{"fun f(x: Int) { x = 2 }", []string{"Val cannot be reassigned"}},
+		{"val x = 1\nfun f() { x = 2 }", []string{"Val cannot be reassigned"}},
+		{"fun f() { val x: Int\nx = 2 }", nil},
+		{"fun g() = 1\nfun f() { g = 2 }", []string{"Variable expected"}},
+		{"val a = b\nval b = 1", []string{"Variable 'b' must be initialized"}},
+		{"val a = { b }\nval b = 1", nil},
+		{"class A { val a = b\nval b = 1 }", []string{"Variable 'b' must be initialized"}},
+		{"class A(x: Int) { fun f() = x }", []string{"Unresolved reference: x"}},
+		{"class Outer { val x = 1\nclass Nested { fun f() = x } }", []string{"Unresolved reference: x"}},
+		{"class Outer { val x = 1\ninner class Inner { fun f() = x } }", nil},
+		{"class Base { val x = 1 }\nclass Derived : Base() { fun f() = x }", nil},
+		{"class Derived : Exception() { fun f() = message }", nil},
+		{"fun Int.double(): Int = coerceAtLeast(0) + toInt()\nfun String?.blank() = isNullOrEmpty() || let { it.isEmpty() }", nil},
+		{"fun Int.bad(): Int = nope", []string{"Unresolved reference: nope"}},
+		{"class Box\nfun Box.bad() = nope", []string{"Unresolved reference: nope"}},
+		{"enum class Color { RED, GREEN; fun f() = name + ordinal + RED }", nil},
+		{"enum class Color { RED, GREEN, RED }", []string{"[1, 32] Redeclaration: RED"}},
+		{"fun f() = com.acme.util.pad()", []string{"Unresolved reference: com"}},
+	}
+
+	for _, test := range tests {
+		errs := resolve(test.input)
+		if len(errs) != len(test.errors) {
+			t.Errorf("%q: got errors %v, want %v", test.input, errs, test.errors)
+			continue
+		}
+		for i, err := range errs {
+			if !strings.Contains(err.Error(), test.errors[i]) {
+				t.Errorf("%q: error %q does not mention %q", test.input, err, test.errors[i])
+			}
+		}
+	}
+}
+
+func TestResolver_Bindings(t *testing.T) {
+	program := parse(`val limit = 10
+fun count(from: Int) {
+	var total = from
+	{ total + limit }
+}`)
+	if errs := New().Resolve(program); len(errs) != 0 {
+		t.Fatalf("Resolve() reported %v", errs)
+	}
+
+	body := program.Statements[1].(*ast.FunctionDecl).Body.Block
+	from := body[0].(*ast.VariableDecl).Value.(*ast.IdentifierExpr).Binding
+	if from.Symbol.Kind != ast.SymbolParameter || from.Symbol.Slot != 0 || from.Depth != 0 {
+		t.Errorf("from is bound to %+v at depth %d, want parameter slot 0 at depth 0", from.Symbol, from.Depth)
+	}
+
+	sum := body[1].(*ast.ExprStmt).Expr.(*ast.LambdaExpr).Body[0].(*ast.ExprStmt).Expr.(*ast.BinaryExpr)
+	total := sum.Left.(*ast.IdentifierExpr).Binding
+	if total.Symbol.Kind != ast.SymbolVariable || total.Symbol.Slot != 1 || total.Depth != 1 {
+		t.Errorf("total is bound to %+v at depth %d, want variable slot 1 at depth 1", total.Symbol, total.Depth)
+	}
+	limit := sum.Right.(*ast.IdentifierExpr).Binding
+	if limit.Symbol.Storage != ast.StorageGlobal || limit.Symbol.Name.Spelling != "limit" {
+		t.Errorf("limit is bound to %+v, want the global limit", limit.Symbol)
+	}
+}
+
+func TestResolver_ResolvePackage(t *testing.T) {
+	main := parse("import util.*\nimport text.Strings as S\nfun main() = pad(S.EMPTY) + helper()")
+	helpers := parse("fun helper() = 1")
+	exports := map[string][]string{"util": {"pad"}, "text": {"Strings"}}
+
+	if errs := New().ResolvePackage([]*ast.Program{main, helpers}, exports); len(errs) != 0 {
+		t.Errorf("ResolvePackage() reported %v", errs)
+	}
+
+	helpers = parse("fun helper() = missing")
+	helpers.File = "helpers.gt"
+	errs := New().ResolvePackage([]*ast.Program{parse("val x = helper()"), helpers}, nil)
+	if len(errs) != 1 || errs[0].Error() != "helpers.gt: [1, 16] Unresolved reference: missing" {
+		t.Errorf("ResolvePackage() reported %v, want the unresolved reference in helpers.gt", errs)
+	}
+}
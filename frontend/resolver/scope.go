@@ -0,0 +1,170 @@
+package resolver
+
+import (
+	"gotlin/frontend/ast"
+	"gotlin/frontend/token"
+	"gotlin/frontend/types"
+)
+
+// Kinds of scopes. A class body has three: its static scope holds the
+// nested declarations and the members of its companion, its instance scope
+// the members reached through `this`, and its constructor scope the
+// parameters of the primary constructor, which only initializers see.
+const (
+	scopeBlock = iota
+	scopeStatic
+	scopeInstance
+	scopeConstructor
+)
+
+// frame is the activation record of a function, lambda or constructor,
+// whose locals get consecutive slots.
+type frame struct {
+	parent *frame
+	level  int
+	next   int
+}
+
+func newFrame(parent *frame) *frame {
+	f := &frame{parent: parent}
+	if parent != nil {
+		f.level = parent.level + 1
+	}
+	return f
+}
+
+// entry is a name declared in a scope.
+type entry struct {
+	symbol *ast.Symbol
+	// frame is the frame of a local.
+	frame *frame
+	// decl is the declaration of a class, object or type alias, which
+	// supertypes are looked up from, and scope the scope declaring it.
+	decl  ast.Stmt
+	scope *scope
+	// deferred reports a local val declared without an initializer, which
+	// is assigned later.
+	deferred bool
+}
+
+// setDecl records the declaration of a class, object or type alias.
+func (e *entry) setDecl(decl ast.Stmt) {
+	e.decl = decl
+	e.symbol.Decl = decl
+}
+
+type scope struct {
+	parent *scope
+	kind   int
+	frame  *frame
+	names  map[string]*entry
+	// later holds the names a block declares further down, with the
+	// position of their declaration. They cannot be used yet.
+	later map[string]token.Pos
+	// open reports that names may also be members of a receiver whose
+	// class is unknown, so names that cannot be bound are not reported.
+	open bool
+	// builtin is the built-in class of the receiver of an extension, whose
+	// members names that cannot be bound may be.
+	builtin string
+	// supers are the instance scopes of the supertypes of a class, and the
+	// one of the companion object of a class static scope, searched after
+	// names.
+	supers []*scope
+	// base is the first slot of the frame the block uses, given back when
+	// the block ends.
+	base int
+}
+
+func newScope(parent *scope, kind int, f *frame) *scope {
+	s := &scope{parent: parent, kind: kind, frame: f, names: make(map[string]*entry)}
+	if f != nil {
+		s.base = f.next
+	}
+	return s
+}
+
+// block is a block nested in s, in the same frame.
+func (s *scope) block() *scope {
+	return newScope(s, scopeBlock, s.frame)
+}
+
+// function is the scope of the parameters of a function or lambda nested
+// in s, which gets a frame of its own.
+func (s *scope) function() *scope {
+	return newScope(s, scopeBlock, newFrame(s.frame))
+}
+
+// end gives the slots of the block back to its frame.
+func (s *scope) end() {
+	if s.frame != nil {
+		s.frame.next = s.base
+	}
+}
+
+// static returns the nearest scope outside the instance and constructor
+// scopes of the class s belongs to, which is all a nested class or object
+// sees of it.
+func (s *scope) static() *scope {
+	for s != nil && (s.kind == scopeInstance || s.kind == scopeConstructor) {
+		s = s.parent
+	}
+	return s
+}
+
+// declare adds a name to s and reports whether s already declares it.
+// Functions may be overloaded, and the first of them stays bound. Locals get
+// the next slot of the frame.
+func (s *scope) declare(name token.Token, kind string, storage ast.Storage) (*entry, bool) {
+	symbol := &ast.Symbol{Name: name, Kind: kind, Storage: storage}
+	e := &entry{symbol: symbol}
+	if storage == ast.StorageLocal {
+		e.frame = s.frame
+		symbol.Slot = s.frame.next
+		s.frame.next++
+	}
+	delete(s.later, name.Spelling)
+	previous, ok := s.names[name.Spelling]
+	if !ok {
+		s.names[name.Spelling] = e
+		return e, false
+	}
+	return e, previous.symbol.Kind != ast.SymbolFunction || kind != ast.SymbolFunction
+}
+
+// lookup finds the entry name is bound to from s, and reports whether an
+// open scope lies on the way.
+func (s *scope) lookup(name string) (*entry, bool) {
+	open := false
+	for current := s; current != nil; current = current.parent {
+		if e, ok := current.member(name); ok {
+			return e, open
+		}
+		open = open || current.open || current.builtin != "" && types.HasBuiltinMember(current.builtin, name)
+	}
+	return nil, open
+}
+
+// member finds name among the names of s and those it inherits.
+func (s *scope) member(name string) (*entry, bool) {
+	if e, ok := s.names[name]; ok {
+		return e, true
+	}
+	for _, super := range s.supers {
+		if e, ok := super.member(name); ok {
+			return e, true
+		}
+	}
+	return nil, false
+}
+
+// declaredLater returns where a block enclosing s declares name after the
+// point being resolved.
+func (s *scope) declaredLater(name string) (token.Pos, bool) {
+	for current := s; current != nil; current = current.parent {
+		if pos, ok := current.later[name]; ok {
+			return pos, true
+		}
+	}
+	return token.Pos{}, false
+}
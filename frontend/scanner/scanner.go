@@ -17,20 +17,25 @@ const (
 type Scanner struct {
 	current byte
 	peek    byte
-	line    uint
-	col     uint
-	tokens  []token.Token
-	reader  *bufio.Reader
+	// line and col locate current, and start the first byte of the token
+	// being scanned.
+	line   uint
+	col    uint
+	start  token.Pos
+	tokens []token.Token
+	reader *bufio.Reader
+	// groups holds the brackets that are still open. New lines inside
+	// `()` and `[]` do not end a statement, but those inside `{}` do.
+	groups []byte
 }
 
 func NewScanner(reader io.Reader) *Scanner {
 	s := &Scanner{
 		reader: bufio.NewReader(reader),
-		line:   1,
-		col:    1,
 	}
 	s.advance()
 	s.advance()
+	s.line, s.col = 1, 1
 	return s
 }
 
@@ -40,6 +45,7 @@ func (s *Scanner) ScanTokens() []token.Token {
 		s.advance()
 	}
 
+	s.start = token.Pos{Line: s.line, Col: s.col}
 	if !s.lastMatch(token.NEWLINE) {
 		s.addToken(token.NEWLINE)
 	}
@@ -49,17 +55,30 @@ func (s *Scanner) ScanTokens() []token.Token {
 }
 
 func (s *Scanner) scan() {
+	s.start = token.Pos{Line: s.line, Col: s.col}
 	switch s.current {
 	case '(':
+		s.open()
 		s.addToken(token.OPEN_PAREN)
 		break
 	case ')':
+		s.close()
 		s.addToken(token.CLOSE_PAREN)
 		break
+	case '[':
+		s.open()
+		s.addToken(token.OPEN_BRACKET)
+		break
+	case ']':
+		s.close()
+		s.addToken(token.CLOSE_BRACKET)
+		break
 	case '{':
+		s.open()
 		s.addToken(token.OPEN_BRACE)
 		break
 	case '}':
+		s.close()
 		s.addToken(token.CLOSE_BRACE)
 		break
 	case ';':
@@ -72,11 +91,21 @@ func (s *Scanner) scan() {
 		s.addToken(token.COMMA)
 		break
 	case '.':
-		s.addToken(token.DOT)
+		if s.match('.') {
+			if s.match('<') {
+				s.addToken(token.RANGE_UNTIL)
+			} else {
+				s.addToken(token.RANGE)
+			}
+		} else {
+			s.addToken(token.DOT)
+		}
 		break
 	case '-':
 		if s.match('=') {
 			s.addToken(token.MINUS_ASSIGN)
+		} else if s.match('>') {
+			s.addToken(token.ARROW)
 		} else {
 			s.addToken(token.DASH)
 		}
@@ -99,6 +128,12 @@ func (s *Scanner) scan() {
 	case '*':
 		s.addToken(token.STAR)
 		break
+	case '%':
+		s.addToken(token.PERCENT)
+		break
+	case '@':
+		s.addToken(token.AT)
+		break
 	case '"':
 		s.addTokenString()
 		break
@@ -107,6 +142,13 @@ func (s *Scanner) scan() {
 			s.addToken(token.NOT_EQ)
 		} else if s.match('!') {
 			s.addToken(token.BANG_BANG)
+		} else if s.peek == 'i' && (s.lookahead(1) == 'n' || s.lookahead(1) == 's') && !isAlpha(s.lookahead(2)) && !isDigit(s.lookahead(2)) {
+			s.advance()
+			if s.advance() == 'n' {
+				s.addToken(token.NOT_IN)
+			} else {
+				s.addToken(token.NOT_IS)
+			}
 		} else {
 			s.addToken(token.NOT)
 		}
@@ -161,7 +203,7 @@ func (s *Scanner) scan() {
 		// whitespace
 		break
 	case '\n':
-		if !s.lastMatch(token.NEWLINE) {
+		if !s.insideParens() && !s.lastMatch(token.NEWLINE) {
 			s.addToken(token.NEWLINE)
 		}
 		break
@@ -181,6 +223,25 @@ func (s *Scanner) scan() {
 	}
 }
 
+func (s *Scanner) open() {
+	s.groups = append(s.groups, s.current)
+}
+
+func (s *Scanner) close() {
+	if len(s.groups) > 0 {
+		s.groups = s.groups[:len(s.groups)-1]
+	}
+}
+
+// insideParens reports whether the innermost open bracket is a `(` or `[`.
+func (s *Scanner) insideParens() bool {
+	if len(s.groups) == 0 {
+		return false
+	}
+	innermost := s.groups[len(s.groups)-1]
+	return innermost == '(' || innermost == '['
+}
+
 func (s *Scanner) lastMatch(kind token.Kind) bool {
 	return len(s.tokens) > 0 && s.tokens[len(s.tokens)-1].Kind == kind
 }
@@ -206,7 +267,7 @@ func (s *Scanner) read() byte {
 
 func (s *Scanner) advance() byte {
 	b := s.read()
-	if b == Eol {
+	if s.current == Eol {
 		s.line++
 		s.col = 1
 	} else {
@@ -217,17 +278,17 @@ func (s *Scanner) advance() byte {
 	return s.current
 }
 
-//TODO REMOVE
-//func (s *Scanner) peekNext() byte {
-//	b, err := s.reader.Peek(1)
-//	if err != nil {
-//		if err == io.EOF {
-//			return Eof
-//		}
-//		panic(err)
-//	}
-//	return b[0]
-//}
+// lookahead returns the byte n positions after peek without consuming it.
+func (s *Scanner) lookahead(n int) byte {
+	b, err := s.reader.Peek(n)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return Eof
+		}
+		panic(err)
+	}
+	return b[n-1]
+}
 
 func (s *Scanner) isAtEnd() bool {
 	return s.current == Eof
@@ -238,11 +299,11 @@ func (s *Scanner) addToken(kind token.Kind) {
 		s.tokens = append(s.tokens, token.Token{
 			Kind:     kind,
 			Spelling: string(s.current),
-			Position: token.Pos{Line: s.line, Col: s.col},
+			Position: s.start,
 		})
 		return
 	}
-	s.tokens = append(s.tokens, token.NewToken(kind, s.line, s.col))
+	s.tokens = append(s.tokens, token.NewToken(kind, s.start.Line, s.start.Col))
 }
 
 func (s *Scanner) addTokenString() {
@@ -250,10 +311,6 @@ func (s *Scanner) addTokenString() {
 	s.advance()
 
 	for s.current != '"' && !s.isAtEnd() {
-		if s.current == '\n' {
-			s.line++
-			s.col = 1
-		}
 		sb.WriteByte(s.current)
 		s.advance()
 	}
@@ -262,16 +319,23 @@ func (s *Scanner) addTokenString() {
 		panic("Unterminated string") // TODO REFACTOR
 	}
 
-	s.advance()
-	s.tokens = append(s.tokens, token.NewTokenLiteral(token.STRINGLIT, sb.String(), s.line, s.col))
+	// The closing quote is left as current, ScanTokens advances past it.
+	s.tokens = append(s.tokens, token.NewTokenLiteral(token.STRINGLIT, sb.String(), s.start.Line, s.start.Col))
 }
 
 func (s *Scanner) addTokenNumber() {
 	var sb strings.Builder
 	sb.WriteByte(s.current)
+	if s.current == '0' && (s.peek == 'x' || s.peek == 'X' || s.peek == 'b' || s.peek == 'B') {
+		s.addTokenPrefixedNumber(&sb)
+		return
+	}
+
 	hasDot := false
 	for {
-		if s.peek == '.' {
+		// `1..10` is a range, not a double: only a dot followed by a digit
+		// belongs to the number.
+		if s.peek == '.' && isDigit(s.lookahead(1)) {
 			hasDot = true
 		} else if !isDigit(s.peek) {
 			break
@@ -281,12 +345,30 @@ func (s *Scanner) addTokenNumber() {
 	}
 
 	if hasDot {
-		s.tokens = append(s.tokens, token.NewTokenLiteral(token.DOUBLELIT, sb.String(), s.line, s.col))
+		s.tokens = append(s.tokens, token.NewTokenLiteral(token.DOUBLELIT, sb.String(), s.start.Line, s.start.Col))
 	} else {
-		s.tokens = append(s.tokens, token.NewTokenLiteral(token.INTLIT, sb.String(), s.line, s.col))
+		s.tokens = append(s.tokens, token.NewTokenLiteral(token.INTLIT, sb.String(), s.start.Line, s.start.Col))
 	}
 }
 
+// addTokenPrefixedNumber scans the digits of a hexadecimal (0x) or binary
+// (0b) integer literal.
+func (s *Scanner) addTokenPrefixedNumber(sb *strings.Builder) {
+	isValidDigit := isHexDigit
+	if s.peek == 'b' || s.peek == 'B' {
+		isValidDigit = isBinaryDigit
+	}
+
+	sb.WriteByte(s.peek)
+	s.advance()
+	for isValidDigit(s.peek) {
+		sb.WriteByte(s.peek)
+		s.advance()
+	}
+
+	s.tokens = append(s.tokens, token.NewTokenLiteral(token.INTLIT, sb.String(), s.start.Line, s.start.Col))
+}
+
 func (s *Scanner) addTokenIdentifier() {
 	var sb strings.Builder
 	sb.WriteByte(s.current)
@@ -299,13 +381,46 @@ func (s *Scanner) addTokenIdentifier() {
 		s.advance()
 	}
 
-	s.tokens = append(s.tokens, token.NewTokenLiteral(token.IDENTIFIER, sb.String(), s.line, s.col))
+	tk := token.NewTokenLiteral(token.IDENTIFIER, sb.String(), s.start.Line, s.start.Col)
+	if s.peek != '@' {
+		s.tokens = append(s.tokens, tk)
+		return
+	}
+
+	// A label follows a name or keyword without any space: `outer@ for`
+	// declares one and `break@outer` refers to it.
+	if !token.TakesLabel(tk.Kind) {
+		if tk.Kind == token.IDENTIFIER {
+			tk.Kind = token.LABEL
+			s.advance()
+		}
+		s.tokens = append(s.tokens, tk)
+		return
+	}
+
+	s.tokens = append(s.tokens, tk)
+	if !isAlpha(s.lookahead(1)) {
+		return
+	}
+	s.advance()
+	s.advance()
+	s.start = token.Pos{Line: s.line, Col: s.col}
+	s.addTokenIdentifier()
+	s.tokens[len(s.tokens)-1].Kind = token.AT_LABEL
 }
 
 func isDigit(b byte) bool {
 	return '0' <= b && b <= '9'
 }
 
+func isHexDigit(b byte) bool {
+	return isDigit(b) || 'a' <= b && b <= 'f' || 'A' <= b && b <= 'F'
+}
+
+func isBinaryDigit(b byte) bool {
+	return b == '0' || b == '1'
+}
+
 func isAlpha(b byte) bool {
 	return 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z' || b == '_'
 }
@@ -8,28 +8,27 @@ import (
 )
 
 func TestScanner_SingleScan(t *testing.T) {
-	input :=
-		`var a: String = "testing"
-		val b: Int = 42
-	`
+	input := "var a: String = \"testing\"\nval b: Int = 42\n"
 	tests := []struct {
 		expectedType token.Kind
 		expectedLit  string
+		expectedPos  token.Pos
 	}{
-		{token.VAR, "var"},
-		{token.IDENTIFIER, "a"},
-		{token.COLON, ":"},
-		{token.STRING, "String"},
-		{token.ASSIGN, "="},
-		{token.STRINGLIT, "testing"},
-		{token.VAL, "val"},
-		{token.IDENTIFIER, "b"},
-		{token.COLON, ":"},
-		{token.INT, "Int"},
-		{token.ASSIGN, "="},
-		{token.INTLIT, "42"},
-		{token.NEWLINE, "<NL>"},
-		{token.EOF, "EOF"},
+		{token.VAR, "var", token.Pos{Line: 1, Col: 1}},
+		{token.IDENTIFIER, "a", token.Pos{Line: 1, Col: 5}},
+		{token.COLON, ":", token.Pos{Line: 1, Col: 6}},
+		{token.IDENTIFIER, "String", token.Pos{Line: 1, Col: 8}},
+		{token.ASSIGN, "=", token.Pos{Line: 1, Col: 15}},
+		{token.STRINGLIT, "testing", token.Pos{Line: 1, Col: 17}},
+		{token.NEWLINE, "<NL>", token.Pos{Line: 1, Col: 26}},
+		{token.VAL, "val", token.Pos{Line: 2, Col: 1}},
+		{token.IDENTIFIER, "b", token.Pos{Line: 2, Col: 5}},
+		{token.COLON, ":", token.Pos{Line: 2, Col: 6}},
+		{token.IDENTIFIER, "Int", token.Pos{Line: 2, Col: 8}},
+		{token.ASSIGN, "=", token.Pos{Line: 2, Col: 12}},
+		{token.INTLIT, "42", token.Pos{Line: 2, Col: 14}},
+		{token.NEWLINE, "<NL>", token.Pos{Line: 2, Col: 16}},
+		{token.EOF, "EOF", token.Pos{Line: 3, Col: 1}},
 	}
 	reader := strings.NewReader(input)
 	scanner := NewScanner(reader)
@@ -45,5 +44,9 @@ func TestScanner_SingleScan(t *testing.T) {
 			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q",
 				i, tt.expectedLit, tok.Spelling)
 		}
+		if tok.Position != tt.expectedPos {
+			t.Fatalf("tests[%d] - position wrong. expected=%v, got=%v",
+				i, tt.expectedPos, tok.Position)
+		}
 	}
 }
@@ -0,0 +1,43 @@
+package token
+
+// Operators on non-primitive operands resolve to member functions declared
+// with the `operator` modifier, e.g. `a + b` calls `a.plus(b)`. Only the
+// front end knows these names: it checks operator calls, but no backend
+// compiles them yet.
+var (
+	binaryOperatorFunctions = map[Kind]string{
+		PLUS:        "plus",
+		DASH:        "minus",
+		STAR:        "times",
+		SLASH:       "div",
+		PERCENT:     "rem",
+		RANGE:       "rangeTo",
+		RANGE_UNTIL: "rangeUntil",
+		IN:          "contains",
+		NOT_IN:      "contains",
+		LT:          "compareTo",
+		LTE:         "compareTo",
+		GT:          "compareTo",
+		GTE:         "compareTo",
+		EQ_EQ:       "equals",
+		NOT_EQ:      "equals",
+	}
+
+	unaryOperatorFunctions = map[Kind]string{
+		PLUS: "unaryPlus",
+		DASH: "unaryMinus",
+		NOT:  "not",
+	}
+)
+
+const InvokeOperatorFunction = "invoke"
+
+func BinaryOperatorFunction(kind Kind) (string, bool) {
+	name, ok := binaryOperatorFunctions[kind]
+	return name, ok
+}
+
+func UnaryOperatorFunction(kind Kind) (string, bool) {
+	name, ok := unaryOperatorFunctions[kind]
+	return name, ok
+}
@@ -12,24 +12,47 @@ const (
 	CHARLIT    Kind = "<char>"
 	STRINGLIT  Kind = "<string>"
 	BOOLEANLIT Kind = "<boolean>"
-
-	IF       Kind = "if"
-	ELSE     Kind = "else"
-	FUNCTION Kind = "fun"
-	CLASS    Kind = "class"
-	WHILE    Kind = "while"
-	VAR      Kind = "var"
-	VAL      Kind = "val"
-	PRINT    Kind = "print"
-	RETURN   Kind = "return"
-	INT      Kind = "Int"
-	TRUE     Kind = "true"
-	FALSE    Kind = "false"
+	// LABEL declares a label, `outer@`, and AT_LABEL refers to one right
+	// after a keyword, `break@outer`. Both are spelled without the `@`.
+	LABEL    Kind = "<label>"
+	AT_LABEL Kind = "<@label>"
+
+	IF        Kind = "if"
+	ELSE      Kind = "else"
+	WHEN      Kind = "when"
+	FUNCTION  Kind = "fun"
+	CLASS     Kind = "class"
+	TYPEALIAS Kind = "typealias"
+	OBJECT    Kind = "object"
+	INTERFACE Kind = "interface"
+	WHILE     Kind = "while"
+	FOR       Kind = "for"
+	VAR       Kind = "var"
+	VAL       Kind = "val"
+	PRINT     Kind = "print"
+	RETURN    Kind = "return"
+	BREAK     Kind = "break"
+	CONTINUE  Kind = "continue"
+	IN        Kind = "in"
+	IS        Kind = "is"
+	THIS      Kind = "this"
+	THROW     Kind = "throw"
+	TRY       Kind = "try"
+	CATCH     Kind = "catch"
+	FINALLY   Kind = "finally"
+	PACKAGE   Kind = "package"
+	IMPORT    Kind = "import"
+	AS        Kind = "as"
+	INT       Kind = "Int"
+	TRUE      Kind = "true"
+	FALSE     Kind = "false"
+	NULL      Kind = "null"
 
 	PLUS      Kind = "+"
 	DASH      Kind = "-"
 	STAR      Kind = "*"
 	SLASH     Kind = "/"
+	PERCENT   Kind = "%"
 	EQ_EQ     Kind = "=="
 	NOT_EQ    Kind = "!="
 	LT        Kind = "<"
@@ -57,6 +80,12 @@ const (
 	OPEN_BRACKET  Kind = "["
 	CLOSE_BRACKET Kind = "]"
 	ELVIS         Kind = "?:"
+	ARROW         Kind = "->"
+	AT            Kind = "@"
+	RANGE         Kind = ".."
+	RANGE_UNTIL   Kind = "..<"
+	NOT_IN        Kind = "!in"
+	NOT_IS        Kind = "!is"
 
 	EOF   Kind = "EOF"
 	ERROR Kind = "ERROR"
@@ -64,19 +93,61 @@ const (
 
 var (
 	reservedKeywords = map[string]Kind{
-		string(IF):       IF,
-		string(ELSE):     ELSE,
-		string(FUNCTION): FUNCTION,
-		string(WHILE):    WHILE,
-		string(VAR):      VAR,
-		string(VAL):      VAL,
-		string(PRINT):    PRINT,
-		string(RETURN):   RETURN,
-		string(CLASS):    CLASS,
+		string(IF):        IF,
+		string(ELSE):      ELSE,
+		string(WHEN):      WHEN,
+		string(FUNCTION):  FUNCTION,
+		string(WHILE):     WHILE,
+		string(FOR):       FOR,
+		string(VAR):       VAR,
+		string(VAL):       VAL,
+		string(PRINT):     PRINT,
+		string(RETURN):    RETURN,
+		string(BREAK):     BREAK,
+		string(CONTINUE):  CONTINUE,
+		string(CLASS):     CLASS,
+		string(TYPEALIAS): TYPEALIAS,
+		string(OBJECT):    OBJECT,
+		string(INTERFACE): INTERFACE,
+		string(IN):        IN,
+		string(IS):        IS,
+		string(THIS):      THIS,
+		string(THROW):     THROW,
+		string(TRY):       TRY,
+		string(CATCH):     CATCH,
+		string(FINALLY):   FINALLY,
+		string(PACKAGE):   PACKAGE,
+		string(IMPORT):    IMPORT,
+		string(AS):        AS,
+		string(NULL):      NULL,
 
 		string(TRUE):  BOOLEANLIT,
 		string(FALSE): BOOLEANLIT,
 	}
+
+	// modifierKeywords are soft keywords: they only act as modifiers in front
+	// of a declaration and remain valid identifiers everywhere else.
+	modifierKeywords = map[string]bool{
+		"operator": true,
+		"infix":    true,
+
+		"companion": true,
+		"enum":      true,
+		"sealed":    true,
+		"data":      true,
+		"inner":     true,
+		"value":     true,
+		"lateinit":  true,
+		"const":     true,
+		"open":      true,
+		"abstract":  true,
+		"override":  true,
+
+		"public":    true,
+		"private":   true,
+		"protected": true,
+		"internal":  true,
+	}
 )
 
 type Pos struct {
@@ -111,6 +182,21 @@ func NewTokenLiteral(kind Kind, spell string, line uint, col uint) Token {
 	}
 }
 
+// TakesLabel reports whether a token of kind may be followed by a label
+// reference, as in `return@forEach` or `this@Outer`.
+func TakesLabel(kind Kind) bool {
+	switch kind {
+	case RETURN, BREAK, CONTINUE, THIS:
+		return true
+	default:
+		return false
+	}
+}
+
+func IsModifier(spell string) bool {
+	return modifierKeywords[spell]
+}
+
 func (t Token) String() string {
 	return t.Spelling
 }
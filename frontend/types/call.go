@@ -0,0 +1,565 @@
+package types
+
+import (
+	"fmt"
+
+	"gotlin/frontend/ast"
+	"gotlin/frontend/token"
+)
+
+// candidate is a function a call may invoke. bindings holds the type
+// arguments of the class of the receiver a method is called on.
+type candidate struct {
+	sig      *Signature
+	bindings map[string]Type
+}
+
+// receiverLambdas are the functions whose lambda runs with a receiver, so
+// that `this` inside it is not the one around the call.
+var receiverLambdas = map[string]bool{
+	"apply":       true,
+	"run":         true,
+	"with":        true,
+	"buildString": true,
+	"buildList":   true,
+	"buildMap":    true,
+}
+
+// members finds the members called name of a value of type t: those of the
+// nearest class among its class and supertypes that declares the name, with
+// the type arguments that class is given. A class name used as a value
+// gives access to its static members.
+func (c *Checker) members(t Type, name string) ([]*Member, map[string]Type) {
+	switch tp := nonNull(t).(type) {
+	case *classifier:
+		return c.staticMembers(tp.class, name), nil
+	case *Named:
+		return inherited(tp, name)
+	case *Function:
+		if name == token.InvokeOperatorFunction {
+			return []*Member{{Name: name, Signature: invokeSignature(tp)}}, nil
+		}
+	}
+	return nil, nil
+}
+
+// staticMembers finds the members called name reached through the name of
+// class: its nested classes, objects and enum entries, then the members of
+// its companion object.
+func (c *Checker) staticMembers(class *Class, name string) []*Member {
+	if members := class.Static[name]; len(members) > 0 {
+		return members
+	}
+	if class.companion != nil {
+		members, _ := c.members(&Named{Class: class.companion}, name)
+		return members
+	}
+	return nil
+}
+
+// memberType returns the type of a property.
+func (c *Checker) memberType(m *Member, bindings map[string]Type) Type {
+	if m.Type != nil {
+		return substitute(m.Type, bindings)
+	}
+	return c.declType(m.Decl)
+}
+
+// methods returns the functions called name that can be called on a value
+// of type t: its member functions, or else the extension functions and
+// scope functions that apply to it.
+func (c *Checker) methods(t Type, name string) []candidate {
+	var candidates []candidate
+	members, bindings := c.members(t, name)
+	for _, m := range members {
+		switch decl := m.Decl.(type) {
+		case *ast.FunctionDecl:
+			candidates = append(candidates, candidate{sig: c.signatureOf(decl), bindings: bindings})
+		case nil:
+			if m.Signature != nil {
+				candidates = append(candidates, candidate{sig: m.Signature, bindings: bindings})
+			}
+			if cl, ok := m.Type.(*classifier); ok && cl.class.Constructor != nil {
+				candidates = append(candidates, candidate{sig: cl.class.Constructor})
+			}
+		}
+	}
+	if len(candidates) > 0 {
+		return candidates
+	}
+	for _, ext := range c.extensionsOf(t, name) {
+		if decl, ok := ext.(*ast.FunctionDecl); ok {
+			candidates = append(candidates, candidate{sig: c.signatureOf(decl)})
+		}
+	}
+	if len(candidates) == 0 {
+		if sig := scopeFunction(t, name); sig != nil {
+			candidates = append(candidates, candidate{sig: sig})
+		}
+	}
+	return candidates
+}
+
+// extensionsOf returns the top-level extensions called name whose receiver
+// a value of type t can be.
+func (c *Checker) extensionsOf(t Type, name string) []ast.Stmt {
+	var found []ast.Stmt
+	for _, ext := range append(c.ctx.file.pkg.extensions[name], c.imported(name)...) {
+		var receiver ast.Type
+		switch decl := ext.(type) {
+		case *ast.FunctionDecl:
+			receiver = decl.Receiver
+		case *ast.VariableDecl:
+			receiver = decl.Receiver
+		}
+		if receiver != nil && Assignable(t, c.receiverType(ext, receiver)) {
+			found = append(found, ext)
+		}
+	}
+	return found
+}
+
+// receiverType resolves the receiver type of a top-level extension in the
+// file that declares it.
+func (c *Checker) receiverType(ext ast.Stmt, receiver ast.Type) Type {
+	defer c.enter(c.contexts[ext])()
+	return c.typeOf(receiver)
+}
+
+// scopeFunction returns the signature of the scope function called name
+// for a receiver of type t, such as `let`, or nil if there is none.
+func scopeFunction(t Type, name string) *Signature {
+	switch name {
+	case "let":
+		return generic(signature(name, typeR, param("block", fn([]Type{t}, typeR))), "R")
+	case "also":
+		return signature(name, t, param("block", fn([]Type{t}, Unit)))
+	case "apply":
+		return signature(name, t, param("block", fn(nil, Unit)))
+	case "run":
+		return generic(signature(name, typeR, param("block", fn(nil, typeR))), "R")
+	case "takeIf", "takeUnless":
+		return signature(name, nullable(t), param("predicate", fn([]Type{t}, Boolean)))
+	}
+	return nil
+}
+
+// inherited finds the members called name of the nearest class among the
+// class of t and its supertypes that declares the name, with the type
+// arguments that class is given.
+func inherited(t *Named, name string) ([]*Member, map[string]Type) {
+	queue := []*Named{t}
+	seen := make(map[*Class]bool)
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if seen[current.Class] {
+			continue
+		}
+		seen[current.Class] = true
+		bindings := bindingsOf(current)
+		if members := current.Class.Members[name]; len(members) > 0 {
+			return members, bindings
+		}
+		for _, super := range current.Class.Supers {
+			if named, ok := substitute(super, bindings).(*Named); ok {
+				queue = append(queue, named)
+			}
+		}
+	}
+	return nil, nil
+}
+
+func invokeSignature(t *Function) *Signature {
+	sig := signature(token.InvokeOperatorFunction, t.Result)
+	for i, p := range t.Params {
+		sig.Params = append(sig.Params, param(fmt.Sprintf("p%d", i+1), p))
+	}
+	return sig
+}
+
+// call types a call, `callee(args)`.
+func (c *Checker) call(e *ast.CallExpr, expected Type) Type {
+	pos := ast.Position(e)
+	switch callee := e.Callee.(type) {
+	case *ast.IdentifierExpr:
+		if candidates, ok := c.functionsCalled(callee); ok {
+			return c.invoke(candidates, e.Args, e.Names, expected, pos)
+		}
+	case *ast.MemberExpr:
+		receiver := c.checkExpr(callee.Receiver, nil)
+		if callee.Safe {
+			receiver = nonNull(receiver)
+		} else {
+			c.nullSafe(callee.Receiver, receiver, callee.Name.Spelling, callee.Name.Position)
+		}
+		t := c.invokeMember(receiver, callee.Name, e.Args, e.Names, expected)
+		if callee.Safe {
+			return nullable(t)
+		}
+		return t
+	}
+	t := c.checkExpr(e.Callee, nil)
+	if isNullable(t) && !isUnknown(t) {
+		c.report(pos, "Reference has a nullable type '%s', use explicit '?.invoke()' to make a function-like call instead", t)
+		t = nonNull(t)
+	}
+	return c.invokeValue(t, e.Args, e.Names, expected, pos)
+}
+
+// functionsCalled returns the functions or constructor a call through a
+// name may invoke, and false when the name holds a value instead.
+func (c *Checker) functionsCalled(id *ast.IdentifierExpr) ([]candidate, bool) {
+	if id.Binding == nil {
+		return nil, false
+	}
+	name := id.Value.Spelling
+	switch decl := id.Binding.Symbol.Decl.(type) {
+	case nil:
+		// An imported alias names a built-in by another name.
+		builtin := id.Binding.Symbol.Name.Spelling
+		var candidates []candidate
+		for _, sig := range builtinFunctions[builtin] {
+			candidates = append(candidates, candidate{sig: sig})
+		}
+		if class, ok := builtinClasses[builtin]; ok && class.Constructor != nil {
+			candidates = append(candidates, candidate{sig: class.Constructor})
+		}
+		return candidates, len(candidates) > 0
+	case *ast.FunctionDecl:
+		c.checkImplicitAccess(id, false)
+		return c.overloads(decl), true
+	case *ast.ClassDeclStmt:
+		if class := c.classes[decl]; class.Constructor != nil {
+			return []candidate{{sig: class.Constructor}}, true
+		}
+		return nil, true
+	case *ast.ImportDirective:
+		var candidates []candidate
+		for _, stmt := range c.importedBy(decl, name) {
+			switch imported := stmt.(type) {
+			case *ast.FunctionDecl:
+				candidates = append(candidates, candidate{sig: c.signatureOf(imported)})
+			case *ast.ClassDeclStmt:
+				if class := c.classes[imported]; class.Constructor != nil {
+					candidates = append(candidates, candidate{sig: class.Constructor})
+				}
+			default:
+				return nil, false
+			}
+		}
+		return candidates, len(candidates) > 0
+	}
+	return nil, false
+}
+
+// overloads returns the functions declared next to decl with its name.
+func (c *Checker) overloads(decl *ast.FunctionDecl) []candidate {
+	var decls []*ast.FunctionDecl
+	if owner, ok := c.owners[decl]; ok {
+		for _, m := range owner.Members[decl.Name.Spelling] {
+			if fn, ok := m.Decl.(*ast.FunctionDecl); ok {
+				decls = append(decls, fn)
+			}
+		}
+	} else if ctx, ok := c.contexts[decl]; ok {
+		functions := ctx.file.pkg.functions[decl.Name.Spelling]
+		for _, fn := range functions {
+			if fn == decl {
+				decls = functions
+			}
+		}
+	}
+	if decls == nil {
+		decls = []*ast.FunctionDecl{decl}
+	}
+	candidates := make([]candidate, len(decls))
+	for i, fn := range decls {
+		candidates[i] = candidate{sig: c.signatureOf(fn)}
+	}
+	return candidates
+}
+
+// invokeMember types a call to the member called name of a value of type
+// receiver.
+func (c *Checker) invokeMember(receiver Type, name token.Token, args []ast.Expr, names []token.Token, expected Type) Type {
+	if outer, ok := receiver.(*classifier); ok {
+		for _, m := range outer.class.Members[name.Spelling] {
+			if inner, ok := m.Type.(*classifier); ok {
+				c.report(name.Position, "Constructor of inner class %s can be called only with receiver of containing class", name.Spelling)
+				c.checkArgs(args)
+				return &Named{Class: inner.class}
+			}
+		}
+	}
+	c.checkAccess(receiver, name, false)
+	if candidates := c.methods(receiver, name.Spelling); len(candidates) > 0 {
+		return c.invoke(candidates, args, names, expected, name.Position)
+	}
+	property, ok := c.property(receiver, name.Spelling)
+	if !isUnknown(property) {
+		return c.invokeValue(property, args, names, expected, name.Position)
+	}
+	if !ok {
+		c.report(name.Position, "Unresolved reference: %s", name.Spelling)
+	}
+	c.checkArgs(args)
+	return Unknown
+}
+
+// invokeValue types a call to a value of type t, which must be a function
+// or have an invoke operator.
+func (c *Checker) invokeValue(t Type, args []ast.Expr, names []token.Token, expected Type, pos token.Pos) Type {
+	return c.invoke(c.methods(t, token.InvokeOperatorFunction), args, names, expected, pos)
+}
+
+// checkArgs checks the arguments of a call to a function the checker does
+// not know.
+func (c *Checker) checkArgs(args []ast.Expr) {
+	for _, arg := range args {
+		c.checkExpr(arg, nil)
+	}
+}
+
+// invoke types a call to one of candidates with args, at pos. The names
+// of named arguments are in names, as in an ast.CallExpr.
+func (c *Checker) invoke(candidates []candidate, args []ast.Expr, names []token.Token, expected Type, pos token.Pos) Type {
+	if len(candidates) == 0 {
+		c.checkArgs(args)
+		return Unknown
+	}
+	chosen := candidates[0]
+	if len(candidates) > 1 {
+		var ok bool
+		if chosen, ok = c.resolveOverload(candidates, args, names, pos); !ok {
+			c.checkArgs(args)
+			return Unknown
+		}
+	}
+	return c.checkCall(chosen, args, names, expected, pos)
+}
+
+// mapArgs returns the index of the parameter each argument is passed to,
+// -1 for arguments in excess or named after no parameter still free, and
+// reports whether every parameter without a default gets a value. Named
+// arguments go to the parameter of their name, and may only be followed
+// by named ones. A lambda passed last goes to the last parameter when it
+// takes a function, as a trailing lambda does.
+func mapArgs(sig *Signature, args []ast.Expr, names []token.Token) ([]int, bool) {
+	mapping := make([]int, len(args))
+	positional := args
+	last := len(sig.Params) - 1
+	if n := len(args); n > 0 && last >= 0 && isLambda(args[n-1]) && argName(names, n-1).Spelling == "" && n-1 <= last {
+		if _, ok := nonNull(sig.Params[last].Type).(*Function); ok {
+			positional = args[:n-1]
+			mapping[n-1] = last
+		}
+	}
+
+	ok := true
+	provided := make([]bool, len(sig.Params))
+	p := 0
+	named := false
+	for i := range positional {
+		if name := argName(names, i); name.Spelling != "" {
+			named = true
+			mapping[i] = paramIndex(sig, name.Spelling)
+			if mapping[i] < 0 || provided[mapping[i]] {
+				mapping[i] = -1
+				ok = false
+				continue
+			}
+			provided[mapping[i]] = true
+			continue
+		}
+		if named || p >= len(sig.Params) || len(positional) < len(args) && p == last {
+			mapping[i] = -1
+			ok = false
+			continue
+		}
+		mapping[i] = p
+		provided[p] = true
+		if !sig.Params[p].Vararg {
+			p++
+		}
+	}
+	if len(positional) < len(args) {
+		provided[last] = true
+	}
+	for i, param := range sig.Params {
+		if !provided[i] && !param.Default && !param.Vararg {
+			ok = false
+		}
+	}
+	return mapping, ok
+}
+
+// argName returns the name the i-th argument of a call is passed by, an
+// empty one for a positional argument.
+func argName(names []token.Token, i int) token.Token {
+	if i < len(names) {
+		return names[i]
+	}
+	return token.Token{}
+}
+
+// paramIndex returns the index of the parameter of sig called name, -1 if
+// it has none.
+func paramIndex(sig *Signature, name string) int {
+	for i, p := range sig.Params {
+		if p.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func isLambda(expr ast.Expr) bool {
+	switch expr.(type) {
+	case *ast.LambdaExpr, *ast.FunctionLiteral:
+		return true
+	}
+	return false
+}
+
+// checkCall checks the arguments of a call to cand at pos and returns the
+// type of its result. The type arguments of a generic function are
+// inferred from the arguments, then from the type expected of the result;
+// lambdas are checked last, once the types of their parameters are known.
+func (c *Checker) checkCall(cand candidate, args []ast.Expr, names []token.Token, expected Type, pos token.Pos) Type {
+	sig := cand.sig
+	mapping, _ := mapArgs(sig, args, names)
+	params := make([]Type, len(sig.Params))
+	for i, p := range sig.Params {
+		params[i] = substitute(p.Type, cand.bindings)
+	}
+	typeParams := make(map[string]bool, len(sig.TypeParams))
+	for _, name := range sig.TypeParams {
+		typeParams[name] = true
+	}
+	inferred := make(map[string]Type)
+
+	ctx := c.ctx
+	if receiverLambdas[sig.Name] {
+		ctx = c.ctx.receiver("", Unknown)
+	}
+	types := make([]Type, len(args))
+	reported := false
+	for i, arg := range args {
+		switch {
+		case mapping[i] < 0:
+			if !reported {
+				c.unmapped(sig, args, names, i)
+				reported = true
+			}
+			c.checkExpr(arg, nil)
+		case !isLambda(arg):
+			types[i] = c.checkExpr(arg, known(substitute(params[mapping[i]], inferred)))
+			unify(params[mapping[i]], types[i], inferred, typeParams)
+		}
+	}
+	if result := substitute(sig.Result, cand.bindings); expected != nil && containsTypeParam(result) {
+		fromResult := make(map[string]Type)
+		unify(result, expected, fromResult, typeParams)
+		for name, t := range fromResult {
+			if _, ok := inferred[name]; !ok {
+				inferred[name] = t
+			}
+		}
+	}
+	for i, arg := range args {
+		if mapping[i] >= 0 && isLambda(arg) {
+			restore := c.enter(ctx)
+			types[i] = c.checkExpr(arg, substitute(params[mapping[i]], inferred))
+			restore()
+			unify(params[mapping[i]], types[i], inferred, typeParams)
+		}
+	}
+
+	for name := range typeParams {
+		if _, ok := inferred[name]; !ok {
+			inferred[name] = Unknown
+		}
+	}
+	for i, arg := range args {
+		if mapping[i] >= 0 && !isLambda(arg) {
+			c.conform(arg, types[i], substitute(params[mapping[i]], inferred))
+		}
+	}
+	if _, ok := mapArgs(sig, args, names); !ok && !reported {
+		provided := make(map[int]bool)
+		for _, p := range mapping {
+			provided[p] = true
+		}
+		for i, p := range sig.Params {
+			if !provided[i] && !p.Default && !p.Vararg {
+				c.report(pos, "No value passed for parameter '%s'", p.Name)
+				break
+			}
+		}
+	}
+	return substitute(substitute(sig.Result, cand.bindings), inferred)
+}
+
+// unmapped reports why the i-th argument of a call to sig is passed to no
+// parameter.
+func (c *Checker) unmapped(sig *Signature, args []ast.Expr, names []token.Token, i int) {
+	name := argName(names, i)
+	switch {
+	case name.Spelling != "" && paramIndex(sig, name.Spelling) < 0:
+		c.report(name.Position, "Cannot find a parameter with this name: %s", name.Spelling)
+	case name.Spelling != "":
+		c.report(name.Position, "An argument is already passed for this parameter")
+	default:
+		for _, previous := range names[:min(i, len(names))] {
+			if previous.Spelling != "" {
+				c.report(ast.Position(args[i]), "Mixing named and positioned arguments is not allowed")
+				return
+			}
+		}
+		c.report(ast.Position(args[i]), "Too many arguments for %s", sig)
+	}
+}
+
+// unify infers the type arguments of typeParams that make an argument of
+// type arg fit a parameter of type param. A type parameter given several
+// arguments gets their common supertype.
+func unify(param Type, arg Type, inferred map[string]Type, typeParams map[string]bool) {
+	switch p := param.(type) {
+	case *TypeParam:
+		if typeParams[p.Name] {
+			inferred[p.Name] = lub(inferred[p.Name], arg)
+		}
+	case *Nullable:
+		unify(p.Type, nonNull(arg), inferred, typeParams)
+	case *Named:
+		a, ok := nonNull(arg).(*Named)
+		if !ok {
+			return
+		}
+		if super := supertype(a, p.Class); super != nil && len(super.Args) == len(p.Args) {
+			for i := range p.Args {
+				unify(p.Args[i], super.Args[i], inferred, typeParams)
+			}
+		}
+	case *Function:
+		a, ok := nonNull(arg).(*Function)
+		if !ok || len(a.Params) != len(p.Params) {
+			return
+		}
+		for i := range p.Params {
+			unify(p.Params[i], a.Params[i], inferred, typeParams)
+		}
+		unify(p.Result, a.Result, inferred, typeParams)
+	}
+}
+
+// superCall checks the constructor arguments a class passes to its
+// superclass.
+func (c *Checker) superCall(super *Named, superType *ast.SuperType, pos token.Pos) {
+	if !superType.Call || super.Class.Constructor == nil {
+		c.checkArgs(superType.Args)
+		return
+	}
+	c.checkCall(candidate{sig: super.Class.Constructor, bindings: bindingsOf(super)}, superType.Args, superType.Names, nil, pos)
+}
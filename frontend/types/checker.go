@@ -0,0 +1,723 @@
+// Package types checks that a program uses its values according to their
+// static types. It infers the types of declarations without an explicit
+// type from their initializers, records the type of every expression it
+// checks, and reports the values whose type is not the one expected.
+//
+// It runs after the resolver, whose bindings tie each name to the
+// declaration its type comes from. Expressions the checker cannot type,
+// such as members of a receiver whose class is unknown, get the Unknown
+// type, which is compatible with every other so that one gap does not turn
+// into a cascade of errors.
+package types
+
+import (
+	"strings"
+
+	"gotlin/frontend/ast"
+	"gotlin/frontend/diag"
+	"gotlin/frontend/token"
+)
+
+const (
+	modifierCompanion = "companion"
+	modifierData      = "data"
+	modifierEnum      = "enum"
+	modifierInfix     = "infix"
+	modifierInner     = "inner"
+	modifierSealed    = "sealed"
+)
+
+// Operator functions a delegated property forwards its reads and writes to.
+const (
+	delegateGetter = "getValue"
+	delegateSetter = "setValue"
+)
+
+type Checker struct {
+	errors []error
+	// warnings holds the diagnostics about code that is valid but likely
+	// wrong, such as code that can never run.
+	warnings []error
+	// types holds the type of every expression checked.
+	types map[ast.Expr]Type
+	// classes holds the classes and objects of the program by declaration,
+	// and declared those of the package being checked in declaration order.
+	classes  map[any]*Class
+	declared []*Class
+	// packages holds the packages checked so far by name.
+	packages map[string]*packageScope
+	// expanding holds the type aliases being expanded, which cannot refer
+	// back to themselves.
+	expanding map[*ast.TypeAliasDecl]bool
+	// owners holds the class declaring each member by declaration.
+	owners map[any]*Class
+	// decls holds the types of declarations and signatures those of
+	// functions. computing marks the declarations whose type is being
+	// inferred, which cannot depend on themselves.
+	decls      map[any]Type
+	signatures map[*ast.FunctionDecl]*Signature
+	computing  map[any]bool
+	// contexts holds the context each member and top-level declaration is
+	// checked in, which their types are inferred in as well.
+	contexts map[any]*context
+	// bodies holds the context of the body of each class declared.
+	bodies map[*Class]*context
+	// changing holds the local variables closures assign to, and impossible
+	// the uses of values that have been checked but cannot be smart cast.
+	changing   map[any]bool
+	impossible map[*ast.IdentifierExpr]impossibleCast
+	// discarded holds the whens, ifs and tries whose value is not used,
+	// and exhaustive the whens that always take one of their branches.
+	discarded  map[ast.Expr]bool
+	exhaustive map[*ast.WhenExpr]bool
+	ctx        *context
+}
+
+// packageScope holds the top-level declarations of a package by name.
+type packageScope struct {
+	// named holds the classes and objects by qualified name.
+	named   map[string]*Class
+	aliases map[string]*ast.TypeAliasDecl
+	// functions holds the top-level functions, and extensions the top-level
+	// extension functions and properties.
+	functions  map[string][]*ast.FunctionDecl
+	extensions map[string][]ast.Stmt
+	// decls holds every top-level declaration, extensions included, which
+	// imports refer to.
+	decls map[string][]ast.Stmt
+	// classes holds the classes and objects in declaration order.
+	classes []*Class
+}
+
+func newPackageScope() *packageScope {
+	return &packageScope{
+		named:      make(map[string]*Class),
+		aliases:    make(map[string]*ast.TypeAliasDecl),
+		functions:  make(map[string][]*ast.FunctionDecl),
+		extensions: make(map[string][]ast.Stmt),
+		decls:      make(map[string][]ast.Stmt),
+	}
+}
+
+// sourceFile is a file of the package being checked.
+type sourceFile struct {
+	// path is the path of the file, which diagnostics name.
+	path    string
+	pkg     *packageScope
+	imports []*ast.ImportDirective
+}
+
+// context describes where the code being checked is.
+type context struct {
+	// file is the file the code is in.
+	file *sourceFile
+	// class is the innermost class around the code, whose nested classes
+	// type names find first.
+	class *Class
+	// this is the type of `this`, nil outside classes and extensions, and
+	// labels the receivers `this@label` names.
+	this   Type
+	labels map[string]Type
+	// result is the type `return` expects, nil where it is not checked.
+	result Type
+	// casts holds the types the values declared by its keys have been
+	// smart cast to. It is never changed once the context is made.
+	casts map[any]Type
+	// assignments holds the state of the local variables declared without
+	// a value. Like casts, it is never changed once the context is made.
+	assignments map[any]assignment
+}
+
+// with returns a copy of ctx, for the code nested in it.
+func (ctx *context) with() *context {
+	nested := *ctx
+	return &nested
+}
+
+// receiver returns a copy of ctx where `this` and `this@label` refer to a
+// receiver of type t.
+func (ctx *context) receiver(label string, t Type) *context {
+	nested := ctx.with()
+	nested.this = t
+	nested.labels = make(map[string]Type, len(ctx.labels)+1)
+	for name, receiver := range ctx.labels {
+		nested.labels[name] = receiver
+	}
+	if label != "" {
+		nested.labels[label] = t
+	}
+	return nested
+}
+
+func New() *Checker {
+	return &Checker{
+		types:      make(map[ast.Expr]Type),
+		classes:    make(map[any]*Class),
+		packages:   make(map[string]*packageScope),
+		expanding:  make(map[*ast.TypeAliasDecl]bool),
+		owners:     make(map[any]*Class),
+		decls:      make(map[any]Type),
+		signatures: make(map[*ast.FunctionDecl]*Signature),
+		computing:  make(map[any]bool),
+		contexts:   make(map[any]*context),
+		bodies:     make(map[*Class]*context),
+		changing:   make(map[any]bool),
+		impossible: make(map[*ast.IdentifierExpr]impossibleCast),
+		discarded:  make(map[ast.Expr]bool),
+		exhaustive: make(map[*ast.WhenExpr]bool),
+	}
+}
+
+func (c *Checker) Check(program *ast.Program) []error {
+	return c.CheckPackage([]*ast.Program{program})
+}
+
+// CheckPackage checks the files of a package together, so that each sees
+// the top-level declarations of the others. The programs must have been
+// resolved. The checker keeps the declarations of the packages it checked
+// before, so that checking packages after the ones they import gives the
+// imported declarations their types.
+func (c *Checker) CheckPackage(programs []*ast.Program) []error {
+	c.errors = nil
+	c.warnings = nil
+	c.declared = nil
+
+	name := ""
+	if len(programs) > 0 && programs[0].Package != nil {
+		name = programs[0].Package.Name
+	}
+	pkg := newPackageScope()
+	c.packages[name] = pkg
+	files := make([]*context, len(programs))
+	for i, program := range programs {
+		files[i] = &context{file: &sourceFile{path: program.File, pkg: pkg, imports: program.Imports}}
+		c.ctx = files[i]
+		c.collectTopLevel(program.Statements)
+		c.collectClasses(program.Statements, nil)
+		c.collectChanging(program.Statements)
+	}
+	for _, class := range c.declared {
+		c.completeClass(class)
+	}
+	c.breakCycles()
+	for i, program := range programs {
+		c.ctx = files[i]
+		c.checkStmts(program.Statements)
+	}
+	return c.errors
+}
+
+// TypeOf returns the type the checker found for expr, or nil if it did not
+// check it.
+func (c *Checker) TypeOf(expr ast.Expr) Type {
+	return c.types[expr]
+}
+
+// Warnings returns the warnings of the last check.
+func (c *Checker) Warnings() []error {
+	return c.warnings
+}
+
+func (c *Checker) report(pos token.Pos, format string, args ...any) {
+	c.errors = append(c.errors, c.diagnostic(pos, format, args...))
+}
+
+func (c *Checker) warn(pos token.Pos, format string, args ...any) {
+	c.warnings = append(c.warnings, c.diagnostic(pos, format, args...))
+}
+
+// diagnostic locates a message in the file of the code being checked.
+func (c *Checker) diagnostic(pos token.Pos, format string, args ...any) *diag.Error {
+	err := diag.Errorf(pos, format, args...)
+	err.File = c.ctx.file.path
+	return err
+}
+
+// mismatch reports a value of type found where one of type expected is
+// needed, unless it is assignable.
+func (c *Checker) mismatch(pos token.Pos, found Type, expected Type) {
+	if !Assignable(found, expected) {
+		c.report(pos, "Type mismatch: inferred type is %s but %s was expected", found, expected)
+	}
+}
+
+// enter makes ctx the context of the code being checked until the
+// returned function restores the previous one.
+func (c *Checker) enter(ctx *context) func() {
+	previous := c.ctx
+	c.ctx = ctx
+	return func() { c.ctx = previous }
+}
+
+func (c *Checker) collectTopLevel(stmts []ast.Stmt) {
+	pkg := c.ctx.file.pkg
+	for _, stmt := range stmts {
+		c.contexts[stmt] = c.ctx
+		for _, name := range ast.DeclaredNames(stmt) {
+			pkg.decls[name.Spelling] = append(pkg.decls[name.Spelling], stmt)
+		}
+		switch decl := stmt.(type) {
+		case *ast.FunctionDecl:
+			if decl.Receiver != nil {
+				pkg.extensions[decl.Name.Spelling] = append(pkg.extensions[decl.Name.Spelling], decl)
+				pkg.decls[decl.Name.Spelling] = append(pkg.decls[decl.Name.Spelling], decl)
+			} else {
+				pkg.functions[decl.Name.Spelling] = append(pkg.functions[decl.Name.Spelling], decl)
+			}
+		case *ast.VariableDecl:
+			if decl.Receiver != nil {
+				pkg.extensions[decl.Name.Spelling] = append(pkg.extensions[decl.Name.Spelling], decl)
+				pkg.decls[decl.Name.Spelling] = append(pkg.decls[decl.Name.Spelling], decl)
+			}
+		case *ast.TypeAliasDecl:
+			pkg.aliases[decl.Name.Spelling] = decl
+		}
+	}
+}
+
+// collectClasses registers the classes and objects declared anywhere in
+// stmts, qualified with the name of the class around them, before their
+// supertypes and members can refer to each other.
+func (c *Checker) collectClasses(stmts []ast.Stmt, outer *Class) {
+	for _, stmt := range stmts {
+		ast.Inspect(stmt, func(node any) bool {
+			switch decl := node.(type) {
+			case *ast.ClassDeclStmt:
+				class := c.addClass(decl, decl.Name.Spelling, outer)
+				class.Interface = decl.Interface
+				for _, entry := range decl.Entries {
+					c.collectClasses(entry.Members, class)
+				}
+				c.collectClasses(decl.Members, class)
+				return false
+			case *ast.ObjectDeclStmt:
+				class := c.addClass(decl, decl.ObjectName(), outer)
+				class.Object = true
+				c.collectClasses(decl.Members, class)
+				return false
+			}
+			return true
+		})
+	}
+}
+
+func (c *Checker) addClass(decl ast.Stmt, name string, outer *Class) *Class {
+	class := newClass(qualify(outer, name))
+	class.decl = decl
+	class.file = c.ctx.file
+	class.outer = outer
+	c.classes[decl] = class
+	c.ctx.file.pkg.named[class.Name] = class
+	c.ctx.file.pkg.classes = append(c.ctx.file.pkg.classes, class)
+	c.declared = append(c.declared, class)
+	return class
+}
+
+func qualify(outer *Class, name string) string {
+	if outer == nil {
+		return name
+	}
+	return outer.Name + "." + name
+}
+
+// completeClass resolves the supertypes of a class and records its
+// members, constructor and static members.
+func (c *Checker) completeClass(class *Class) {
+	defer c.enter(&context{file: class.file})()
+	self := &Named{Class: class}
+	var superTypes []*ast.SuperType
+	var members []ast.Stmt
+	switch decl := class.decl.(type) {
+	case *ast.ClassDeclStmt:
+		superTypes, members = decl.SuperTypes, decl.Members
+		c.completeConstructor(class, decl)
+		for _, entry := range decl.Entries {
+			class.Static[entry.Name.Spelling] = append(class.Static[entry.Name.Spelling], &Member{Name: entry.Name.Spelling, Type: self})
+			c.decls[entry] = self
+		}
+		if decl.Modifiers.Has(modifierEnum) {
+			class.Supers = append(class.Supers, named(comparableClass, self))
+			class.property("name", String)
+			class.property("ordinal", Int)
+			class.Static["values"] = []*Member{{Name: "values", Signature: signature("values", named(arrayClass, self))}}
+			class.Static["valueOf"] = []*Member{{Name: "valueOf", Signature: signature("valueOf", self, param("value", String))}}
+			class.Static["entries"] = []*Member{{Name: "entries", Type: named(listClass, self)}}
+		}
+	case *ast.ObjectDeclStmt:
+		superTypes, members = decl.SuperTypes, decl.Members
+	}
+
+	// Only the body of an inner class still sees the receivers around it.
+	ctx := &context{file: class.file}
+	if decl, ok := class.decl.(*ast.ClassDeclStmt); ok && decl.Modifiers.Has(modifierInner) && c.bodies[class.outer] != nil {
+		ctx = c.bodies[class.outer]
+	}
+	ctx = ctx.receiver(class.Name[strings.LastIndex(class.Name, ".")+1:], self)
+	ctx.class = class
+	ctx.result = nil
+	c.bodies[class] = ctx
+	for _, superType := range superTypes {
+		if super, ok := c.resolveType(superType.Type, class.outer).(*Named); ok && super.Class != anyClass {
+			class.Supers = append(class.Supers, super)
+		} else if !ok {
+			class.Open = true
+		}
+	}
+	if len(class.Supers) == 0 {
+		class.Supers = []Type{Any}
+	}
+	c.addMembers(class, members, ctx)
+}
+
+// breakCycles reports the supertypes through which a class of the package
+// inherits from itself, and drops them, so that walks up the hierarchy
+// end.
+func (c *Checker) breakCycles() {
+	cyclic := make(map[*Class]map[*Class]bool)
+	for _, class := range c.declared {
+		var superTypes []*ast.SuperType
+		switch decl := class.decl.(type) {
+		case *ast.ClassDeclStmt:
+			superTypes = decl.SuperTypes
+		case *ast.ObjectDeclStmt:
+			superTypes = decl.SuperTypes
+		}
+		restore := c.enter(&context{file: class.file})
+		for _, superType := range superTypes {
+			super, ok := c.resolveType(superType.Type, class.outer).(*Named)
+			if !ok || !inherits(super.Class, class, make(map[*Class]bool)) {
+				continue
+			}
+			if name, ok := superType.Type.(*ast.TypeName); ok {
+				c.report(name.Position, "There's a cycle in the inheritance hierarchy for this type")
+			}
+			if cyclic[class] == nil {
+				cyclic[class] = make(map[*Class]bool)
+			}
+			cyclic[class][super.Class] = true
+		}
+		restore()
+	}
+	for class, supers := range cyclic {
+		var kept []Type
+		for _, super := range class.Supers {
+			if named, ok := super.(*Named); !ok || !supers[named.Class] {
+				kept = append(kept, super)
+			}
+		}
+		if len(kept) == 0 {
+			kept = []Type{Any}
+		}
+		class.Supers = kept
+	}
+}
+
+func (c *Checker) completeConstructor(class *Class, decl *ast.ClassDeclStmt) {
+	if decl.Interface {
+		return
+	}
+	self := &Named{Class: class}
+	class.Constructor = signature(decl.Name.Spelling, self)
+	if decl.PrimaryConstructor == nil {
+		return
+	}
+	var components []*ast.ClassParam
+	for i := range decl.PrimaryConstructor.Parameters {
+		p := &decl.PrimaryConstructor.Parameters[i]
+		t := c.resolveType(p.Type, class)
+		c.decls[p] = parameterType(t, p.Vararg)
+		class.Constructor.Params = append(class.Constructor.Params, &Param{Name: p.Name, Type: t, Default: p.DefaultValue != nil, Vararg: p.Vararg})
+		if p.Property {
+			class.Members[p.Name] = append(class.Members[p.Name], &Member{Name: p.Name, Decl: p, owner: class})
+			c.owners[p] = class
+			components = append(components, p)
+		}
+	}
+	if !decl.Modifiers.Has(modifierData) {
+		return
+	}
+	copied := signature("copy", self)
+	for _, p := range class.Constructor.Params {
+		copied.Params = append(copied.Params, optional(p.Name, p.Type))
+	}
+	class.method(copied)
+	for i, p := range components {
+		class.method(signature(ast.ComponentFunction(i), c.decls[p]))
+	}
+}
+
+// addMembers records the members a class body declares.
+func (c *Checker) addMembers(class *Class, members []ast.Stmt, ctx *context) {
+	for _, stmt := range members {
+		c.contexts[stmt] = ctx
+		switch decl := stmt.(type) {
+		case *ast.VariableDecl:
+			if decl.Receiver == nil {
+				class.Members[decl.Name.Spelling] = append(class.Members[decl.Name.Spelling], &Member{Name: decl.Name.Spelling, Decl: decl, owner: class})
+				c.owners[decl] = class
+			}
+		case *ast.FunctionDecl:
+			if decl.Receiver == nil {
+				class.Members[decl.Name.Spelling] = append(class.Members[decl.Name.Spelling], &Member{Name: decl.Name.Spelling, Decl: decl, owner: class})
+				c.owners[decl] = class
+			}
+		case *ast.ClassDeclStmt:
+			// An inner class is constructed through an instance of the
+			// class around it, a nested one through its name.
+			nested := &Member{Name: decl.Name.Spelling, Type: &classifier{class: c.classes[decl]}, owner: class}
+			if decl.Modifiers.Has(modifierInner) {
+				class.Members[decl.Name.Spelling] = append(class.Members[decl.Name.Spelling], nested)
+			} else {
+				class.Static[decl.Name.Spelling] = append(class.Static[decl.Name.Spelling], nested)
+			}
+		case *ast.ObjectDeclStmt:
+			object := c.classes[decl]
+			if decl.Modifiers.Has(modifierCompanion) {
+				class.companion = object
+			}
+			name := decl.ObjectName()
+			class.Static[name] = append(class.Static[name], &Member{Name: name, Type: &Named{Class: object}, owner: class})
+		}
+	}
+}
+
+// lookupClass finds the class a possibly qualified type name written in
+// the body of from refers to: a class nested in from or in the classes
+// around it, a top-level class, an imported one, then a built-in one.
+func (c *Checker) lookupClass(name string, from *Class) *Class {
+	named := c.ctx.file.pkg.named
+	for outer := from; outer != nil; outer = outer.outer {
+		if class, ok := named[qualify(outer, name)]; ok {
+			return class
+		}
+	}
+	if class, ok := named[name]; ok {
+		return class
+	}
+	root, nested, _ := strings.Cut(name, ".")
+	for _, decl := range c.imported(root) {
+		if class, ok := c.classes[decl]; ok {
+			if nested == "" {
+				return class
+			}
+			return class.file.pkg.named[class.Name+"."+nested]
+		}
+	}
+	return builtinClasses[name]
+}
+
+// alias finds the type alias a type name written in the code being
+// checked refers to, declared in its package or imported.
+func (c *Checker) alias(name string) *ast.TypeAliasDecl {
+	if alias, ok := c.ctx.file.pkg.aliases[name]; ok {
+		return alias
+	}
+	for _, decl := range c.imported(name) {
+		if alias, ok := decl.(*ast.TypeAliasDecl); ok {
+			return alias
+		}
+	}
+	return nil
+}
+
+// imported returns the top-level declarations called name that the
+// imports of the file being checked bring in.
+func (c *Checker) imported(name string) []ast.Stmt {
+	var found []ast.Stmt
+	for _, directive := range c.ctx.file.imports {
+		if directive.All || directive.ImportedName() == name {
+			found = append(found, c.importedBy(directive, name)...)
+		}
+	}
+	return found
+}
+
+// importedBy returns the declarations an import makes visible under name,
+// leaving out those private to their file. Only packages checked before
+// have their declarations known.
+func (c *Checker) importedBy(directive *ast.ImportDirective, name string) []ast.Stmt {
+	path, declared := directive.Path, name
+	if !directive.All {
+		path, declared = "", directive.Path
+		if i := strings.LastIndex(directive.Path, "."); i >= 0 {
+			path, declared = directive.Path[:i], directive.Path[i+1:]
+		}
+	}
+	pkg, ok := c.packages[path]
+	if !ok {
+		return nil
+	}
+	var found []ast.Stmt
+	for _, decl := range pkg.decls[declared] {
+		if !ast.DeclarationModifiers(decl).IsPrivate() {
+			found = append(found, decl)
+		}
+	}
+	return found
+}
+
+// resolveType returns the type an ast.Type written in the body of class
+// stands for.
+func (c *Checker) resolveType(t ast.Type, class *Class) Type {
+	return c.resolve(t, class, nil)
+}
+
+// resolve is resolveType where the names in params stand for the type
+// arguments of the type alias being expanded.
+func (c *Checker) resolve(t ast.Type, class *Class, params map[string]Type) Type {
+	switch tp := t.(type) {
+	case *ast.TypeName:
+		if bound, ok := params[tp.Name]; ok {
+			return bound
+		}
+		args := make([]Type, len(tp.Args))
+		for i, arg := range tp.Args {
+			args[i] = c.resolve(arg, class, params)
+		}
+		if found := c.lookupClass(tp.Name, class); found != nil {
+			if len(args) != len(found.TypeParams) {
+				args = make([]Type, len(found.TypeParams))
+				for i := range args {
+					args[i] = Unknown
+				}
+			}
+			return &Named{Class: found, Args: args}
+		}
+		if alias := c.alias(tp.Name); alias != nil && !c.expanding[alias] && len(args) == len(alias.TypeParams) {
+			c.expanding[alias] = true
+			defer delete(c.expanding, alias)
+			bindings := make(map[string]Type, len(args))
+			for i, param := range alias.TypeParams {
+				bindings[param.Spelling] = args[i]
+			}
+			// The alias names types the way the file declaring it does.
+			defer c.enter(c.contexts[alias])()
+			name := tp.Name
+			if len(args) > 0 {
+				name += "<" + typeStrings(args) + ">"
+			}
+			return aliased(c.resolve(alias.Type, nil, bindings), name)
+		}
+	case *ast.NullableType:
+		return nullable(c.resolve(tp.Type, class, params))
+	case *ast.ArrayType:
+		return named(arrayClass, c.resolve(tp.Underlying, class, params))
+	case *ast.FunctionType:
+		fn := &Function{Params: make([]Type, len(tp.Params)), Result: c.resolve(tp.Return, class, params)}
+		for i, param := range tp.Params {
+			fn.Params[i] = c.resolve(param, class, params)
+		}
+		return fn
+	}
+	return Unknown
+}
+
+// typeOf resolves a type written in the code being checked.
+func (c *Checker) typeOf(t ast.Type) Type {
+	return c.resolveType(t, c.ctx.class)
+}
+
+// declType returns the type of the declaration a name is bound to,
+// inferring it from the initializer of a property declared without one.
+func (c *Checker) declType(decl any) Type {
+	if t, ok := c.decls[decl]; ok {
+		return t
+	}
+	if c.computing[decl] {
+		return Unknown
+	}
+	c.computing[decl] = true
+	defer delete(c.computing, decl)
+
+	t := Unknown
+	switch d := decl.(type) {
+	case *ast.VariableDecl:
+		if ctx, ok := c.contexts[d]; ok {
+			defer c.enter(ctx)()
+		}
+		t = c.propertyType(d)
+	case *ast.ParameterWithOptionalType:
+		if d.Type != nil {
+			t = c.typeOf(d.Type)
+		}
+	case *ast.CatchClause:
+		t = c.typeOf(d.Type)
+	case *ast.ClassDeclStmt:
+		t = &classifier{class: c.classes[d]}
+	case *ast.ObjectDeclStmt:
+		t = &Named{Class: c.classes[d]}
+	default:
+		return Unknown
+	}
+	c.decls[decl] = t
+	return t
+}
+
+// propertyType returns the declared type of a property, or the one it
+// gets from its initializer, getter or delegate.
+func (c *Checker) propertyType(decl *ast.VariableDecl) Type {
+	switch {
+	case decl.Type != nil:
+		return c.typeOf(decl.Type)
+	case decl.Value != nil:
+		return c.checkExpr(decl.Value, nil)
+	case decl.Getter != nil && decl.Getter.Body != nil && decl.Getter.Body.Expr != nil:
+		return c.checkExpr(decl.Getter.Body.Expr, nil)
+	case decl.Delegate != nil:
+		return c.delegateType(c.checkExpr(decl.Delegate, nil))
+	}
+	return Unknown
+}
+
+// signatureOf returns the signature of a function of the program. The
+// result of a function whose expression body gives it is inferred.
+func (c *Checker) signatureOf(decl *ast.FunctionDecl) *Signature {
+	if sig, ok := c.signatures[decl]; ok {
+		return sig
+	}
+	outer := c.ctx
+	if ctx, ok := c.contexts[decl]; ok {
+		outer = ctx
+	}
+	defer c.enter(outer)()
+
+	sig := &Signature{Name: decl.Name.Spelling, Result: Unknown, Decl: decl}
+	for _, p := range decl.Parameters {
+		t := c.typeOf(p.Type)
+		c.decls[p] = parameterType(t, p.Vararg)
+		sig.Params = append(sig.Params, &Param{Name: p.Name, Type: t, Default: p.DefaultValue != nil, Vararg: p.Vararg})
+	}
+	c.signatures[decl] = sig
+	switch {
+	case decl.Type != nil:
+		sig.Result = c.typeOf(decl.Type)
+	case decl.Body == nil || decl.Body.Expr == nil:
+		sig.Result = Unit
+	case !c.computing[decl]:
+		c.computing[decl] = true
+		defer delete(c.computing, decl)
+		defer c.enter(c.functionContext(decl, nil))()
+		sig.Result = c.checkExpr(decl.Body.Expr, nil)
+	}
+	return sig
+}
+
+// parameterType returns the type of a parameter declared with type t in
+// the body of its function: an array of them for a vararg parameter.
+func parameterType(t Type, vararg bool) Type {
+	if vararg {
+		return named(arrayClass, t)
+	}
+	return t
+}
+
+// functionContext is the context of the body of a function declared in
+// the code being checked, whose return statements expect result.
+func (c *Checker) functionContext(decl *ast.FunctionDecl, result Type) *context {
+	ctx := c.ctx.with()
+	if decl.Receiver != nil {
+		ctx = ctx.receiver(decl.Name.Spelling, c.typeOf(decl.Receiver))
+	}
+	ctx.result = result
+	return ctx
+}
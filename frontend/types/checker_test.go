@@ -0,0 +1,839 @@
+package types_test
+
+import (
+	"strings"
+	"testing"
+
+	"gotlin/frontend/ast"
+	"gotlin/frontend/parser"
+	"gotlin/frontend/resolver"
+	"gotlin/frontend/scanner"
+	"gotlin/frontend/types"
+)
+
+func parse(t *testing.T, input string) *ast.Program {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Fatalf("%q: %v", input, r)
+		}
+	}()
+	program := parser.New(scanner.NewScanner(strings.NewReader(input))).Parse()
+	if errs := resolver.New().Resolve(program); len(errs) > 0 {
+		t.Fatalf("%q: resolver errors %v", input, errs)
+	}
+	return program
+}
+
+func TestChecker_Diagnostics(t *testing.T) {
+	tests := []struct {
+		input  string
+		errors []string
+	}{
+		{`val limit = 10
+		class Counter(start: Int, val step: Int = 1) : Comparable<Counter> {
+			var count = start
+			companion object { const val MAX = 100 }
+			fun next(): Int {
+				count = count + step
+				return minOf(count, MAX, limit)
+			}
+			fun compareTo(other: Counter) = count - other.count
+		}
+		fun String.shout() = uppercase() + "!"
+		fun sum(values: List<Int>): Int {
+			var total = 0
+			for (value in values) { total = total + value }
+			values.forEach { total = total + it }
+			val (first, second) = Pair(1, "two")
+			return total + first + second.length
+		}
+		fun names(): List<String> = listOf(1, 2, 3).map { it.toString() }.filter { it.isNotEmpty() }
+		fun later() = earlier()
+		fun earlier() = with(Counter(1)) { next() }
+		fun lengths(words: Map<String, Int>): Int {
+			var total: Long = 0
+			for ((word, length) in words) { total = total + word.length + length }
+			return total.toInt()
+		}
+		val ratio: Double = 1 / 2.0
+		val nothing: String? = null
+		val chars = "abc".map { it.code }.sum()
+		val pair: Pair<Int, String> = 1 to "one"
+		val shout = "hi".shout().let { it + it }`, nil},
+		{`data class Point(val x: Int, val y: Int)
+		enum class Color(val rgb: Int) { RED(0xff0000), GREEN(0x00ff00); fun hex() = rgb.toString(16) }
+		object Registry { val points: MutableList<Point> = mutableListOf() }
+		fun f(): String {
+			val p = Point(1, 2).copy(1, 3)
+			Registry.points.add(p)
+			val (x, y) = p
+			val color = Color.valueOf("RED")
+			return when (color) {
+				Color.RED -> "red" + x
+				else -> color.name + y + Color.GREEN.hex()
+			}
+		}`, nil},
+		{`interface Shape { fun area(): Double }
+		class Square(val side: Double) : Shape { fun area() = side * side }
+		fun total(shapes: List<Shape>) = shapes.sumOf { it.area() }
+		val shapes: List<Shape> = listOf(Square(1.0), Square(2.0))
+		val any: Any = total(shapes)
+		val lazyValue by lazy { "value" }
+		fun fail(): Nothing = throw IllegalStateException("fail")
+		fun g(x: Int): Int = if2(x) ?: fail()
+		fun if2(x: Int): Int? = null`, nil},
+		{"val x: Int = \"one\"", []string{"[1, 14] Type mismatch: inferred type is String but Int was expected"}},
+		{"fun f() {\n\tval s: String = 1 + 2\n}", []string{"[2, 18] Type mismatch: inferred type is Int but String was expected"}},
+		{"fun f(): String = 1 + 2", []string{"Type mismatch: inferred type is Int but String was expected"}},
+		{"fun f(): Int { return \"one\" }", []string{"Type mismatch: inferred type is String but Int was expected"}},
+		{"val x: Byte = 300", []string{"The integer literal does not conform to the expected type Byte"}},
+		{"val x: Long = 3", nil},
+		{"val x: Byte = -128\nval y: Int = 3000000000", []string{"Type mismatch: inferred type is Long but Int was expected"}},
+		{"val x: String = null", []string{"Type mismatch: inferred type is Nothing? but String was expected"}},
+		{"fun f() { var x = 1\nx = \"two\" }", []string{"Type mismatch: inferred type is String but Int was expected"}},
+		{"fun f() { throw 1 }", []string{"Type mismatch: inferred type is Int but Throwable was expected"}},
+		{"class Point(val x: Int)\nfun f() { throw Point(1) }", []string{"Type mismatch: inferred type is Point but Throwable was expected"}},
+		{"class Failure : Exception()\nfun f(): Nothing = throw Failure()", nil},
+		{"fun f(x: Int) = x + \"one\"", []string{"Operator '+' cannot be applied to 'Int' and 'String'"}},
+		{"fun f(x: String) = -x", []string{"Operator '-' cannot be applied to 'String'"}},
+		{"fun f(x: Int) { while (x) { } }", []string{"Condition type mismatch: inferred type is Int but Boolean was expected"}},
+		{"class A(val x: Int)\nfun f(a: A) = a.missing", []string{"Unresolved reference: missing"}},
+		{"fun f(a: Int, b: String) = a\nval x = f(1)", []string{"No value passed for parameter 'b'"}},
+		{"fun f(a: Int) = a\nval x = f(1, 2)", []string{"Too many arguments for fun f(a: Int): Int"}},
+		{"enum class E(val x: Int) { A(1), B, C(2, 3) }", []string{"No value passed for parameter 'x'", "Too many arguments for fun E(x: Int): E"}},
+		{"fun f(a: Int) = a\nval x = f(\"one\")", []string{"Type mismatch: inferred type is String but Int was expected"}},
+		{"val xs: List<String> = listOf(1, 2)", []string{"Type mismatch: inferred type is List<Int> but List<String> was expected"}},
+		{"val xs: List<Any> = listOf(1, 2)", nil},
+		{"val ints: MutableList<Int> = mutableListOf()\nval xs: MutableList<Any> = ints", []string{"Type mismatch: inferred type is MutableList<Int> but MutableList<Any> was expected"}},
+		{"val f: (Int) -> String = { it + 1 }", []string{"Type mismatch: inferred type is Int but String was expected"}},
+		{"class A(val x: Int)\nval x = listOf(A(1)).map { it.length }", []string{"Unresolved reference: length"}},
+		{"fun f() { for (c in 42) { } }", []string{"For-loop range must have an 'iterator()' method"}},
+		{"fun f(x: Int) = when { x > 0 -> 1\nelse -> \"no\" }\nval y: Int = f(1)", []string{"Type mismatch: inferred type is Any but Int was expected"}},
+		{"class A(val x: Int)\nclass B : A(\"x\")", []string{"Type mismatch: inferred type is String but Int was expected"}},
+		{"class Outer { val x = 1\ninner class Inner { fun f(): String = this@Outer.x } }", []string{"Type mismatch: inferred type is Int but String was expected"}},
+		{"class Outer { val x = 1\ninner class Inner(val y: Int) { fun sum() = x + y } }\nval s: String = Outer().Inner(2).sum()", []string{"Type mismatch: inferred type is Int but String was expected"}},
+		{"open class Outer { inner class Inner\nfun make(): Inner = Inner() }\nclass Sub : Outer()\nval i: Outer.Inner = Sub().Inner()", nil},
+		{"class Outer { inner class Inner\nclass Nested }\nval i = Outer.Inner()\nval n = Outer.Nested()", []string{"Constructor of inner class Inner can be called only with receiver of containing class"}},
+		{"class Outer { class Nested }\nval n = Outer().Nested()", []string{"Unresolved reference: Nested"}},
+		{"typealias Handler = (Int) -> String\nval h: Handler = { it.toString() }\nval n: Int = h", []string{"Type mismatch: inferred type is Handler but Int was expected"}},
+		{"typealias StringMap<V> = Map<String, V>\nval m: StringMap<Int> = mapOf()\nval s: String = m", []string{"Type mismatch: inferred type is StringMap<Int> but String was expected"}},
+		{"typealias Name = String\nval n: Name? = null\nval x: Int = n", []string{"Type mismatch: inferred type is Name? but Int was expected"}},
+		{"val p: Pair<Int, Int> = 1 to \"one\"", []string{"Type mismatch: inferred type is Pair<Int, String> but Pair<Int, Int> was expected"}},
+		{"class A { fun f(o: A) = 1 }\nval x = A() f A()", []string{"'infix' modifier is required on 'f'"}},
+		{"class A { infix fun f(o: A) = 1 }\nval x: Int = A() f A()", nil},
+		{"open class C : C()\nfun f(c: C) = c.x", []string{
+			"[1, 16] There's a cycle in the inheritance hierarchy for this type",
+			"Unresolved reference: x",
+		}},
+		{"interface I : J\ninterface J : I\nclass K : I\nval i: I = K()", []string{
+			"[1, 15] There's a cycle in the inheritance hierarchy for this type",
+			"[2, 15] There's a cycle in the inheritance hierarchy for this type",
+		}},
+	}
+
+	for _, test := range tests {
+		errs := types.New().Check(parse(t, test.input))
+		if len(errs) != len(test.errors) {
+			t.Errorf("%q: got errors %v, want %v", test.input, errs, test.errors)
+			continue
+		}
+		for i, err := range errs {
+			if !strings.Contains(err.Error(), test.errors[i]) {
+				t.Errorf("%q: error %q does not mention %q", test.input, err, test.errors[i])
+			}
+		}
+	}
+}
+
+func TestChecker_TypeOf(t *testing.T) {
+	program := parse(t, `val a = listOf(1, 2).map { it * 2.0 }
+	val b = mapOf("one" to 1)
+	val c = "abc".length > 2
+	val d = listOf("x").firstOrNull()`)
+	checker := types.New()
+	if errs := checker.Check(program); len(errs) > 0 {
+		t.Fatalf("got errors %v", errs)
+	}
+	want := []string{"List<Double>", "Map<String, Int>", "Boolean", "String?"}
+	for i, stmt := range program.Statements {
+		decl := stmt.(*ast.VariableDecl)
+		if got := checker.TypeOf(decl.Value).String(); got != want[i] {
+			t.Errorf("type of %s: got %s, want %s", decl.Name.Spelling, got, want[i])
+		}
+	}
+}
+
+func TestChecker_NullSafety(t *testing.T) {
+	tests := []struct {
+		input  string
+		errors []string
+	}{
+		{`fun length(s: String?): Int {
+			if (s != null) return s.length
+			return 0
+		}
+		fun first(s: String?) = if (s == null) 0 else s.length
+		fun safe(s: String?): Int = s?.length ?: s!!.length
+		fun elvis(s: String?): Int {
+			s ?: return 0
+			return s.length
+		}
+		fun early(s: String?): Int {
+			if (s == null) {
+				return 0
+			}
+			return s.length
+		}
+		fun both(a: String?, b: String?) = a != null && b != null && a.length == b.length
+		fun either(s: String?) = s == null || s.isEmpty()
+		fun kind(x: Any): Int {
+			if (x is String) return x.length
+			if (x !is List<Any>) return 0
+			return x.size
+		}
+		fun describe(x: Any?) = when (x) {
+			null -> 0
+			is String -> x.length
+			else -> x.hashCode()
+		}
+		fun loop(s: String?): Int {
+			var rest = s
+			while (rest != null) {
+				rest = rest.drop(1)
+			}
+			return 0
+		}
+		fun assigned(): Int {
+			var s: String? = null
+			s = "value"
+			return s.length
+		}
+		fun unsafe(s: String?) = s.toString() + s.isNullOrEmpty() + s.let { 1 }
+		fun asserted(s: String?): Int {
+			s!!
+			return s.length
+		}
+		fun elvisValue(s: String?): Int {
+			val t = s ?: return 0
+			return t.length + s.length
+		}`, nil},
+		{"fun f(s: String?) = s.length", []string{"[1, 23] Only safe (?.) or non-null asserted (!!.) calls are allowed on a nullable receiver of type String?"}},
+		{"fun f(s: String?) = s.uppercase()", []string{"Only safe (?.) or non-null asserted (!!.) calls are allowed on a nullable receiver of type String?"}},
+		{"fun f(x: Int?) = x + 1", []string{"Only safe (?.) or non-null asserted (!!.) calls are allowed on a nullable receiver of type Int?"}},
+		{"fun f(s: String?) { if (s == null) s.length }", []string{"Only safe (?.) or non-null asserted (!!.) calls are allowed on a nullable receiver of type String?"}},
+		{"fun f(s: String?) { if (s != null) { }\ns.length }", []string{"Only safe (?.) or non-null asserted (!!.) calls are allowed on a nullable receiver of type String?"}},
+		{"fun f(s: String?, t: String) { var u = s\nif (u != null) { u = null\nu.length } }", []string{"Only safe (?.) or non-null asserted (!!.) calls are allowed on a nullable receiver of type String?"}},
+		{"fun f(s: String?) = s != null || s.isEmpty()", []string{"Only safe (?.) or non-null asserted (!!.) calls are allowed on a nullable receiver of type String?"}},
+		{"fun f(xs: List<Int>?) { for (x in xs) { } }", []string{"Not nullable value required to call an 'iterator()' method on for-loop range"}},
+		{"fun f(p: Pair<Int, Int>?) { val (a, b) = p }", []string{"Not nullable value required to call 'component1()' function of destructuring declaration initializer"}},
+		{"fun f(g: (() -> Unit)?) = g()", []string{"Reference has a nullable type '(() -> Unit)?', use explicit '?.invoke()' to make a function-like call instead"}},
+		{"fun f(s: String?): String = s", []string{"Type mismatch: inferred type is String? but String was expected"}},
+		{`fun f(): Int {
+			var s: String? = "a"
+			val reset = { s = null }
+			if (s != null) return s.length
+			return 0
+		}`, []string{"Smart cast to 'String' is impossible, because 's' is a local variable that is captured by a changing closure"}},
+		{`class Box(var value: String?) {
+			fun f(): Int {
+				if (value != null) return value.length
+				return 0
+			}
+		}`, []string{"Smart cast to 'String' is impossible, because 'value' is a mutable property that could have been changed by this time"}},
+		{`fun take(s: String) = s
+		fun f() {
+			var s: String? = "a"
+			listOf(1).forEach { s = null }
+			if (s != null) take(s)
+		}`, []string{"Smart cast to 'String' is impossible, because 's' is a local variable that is captured by a changing closure"}},
+		{`fun f(s: String?) {
+			var t = s
+			if (t != null) {
+				listOf(1).forEach { t.length }
+			}
+		}`, []string{"Only safe (?.) or non-null asserted (!!.) calls are allowed on a nullable receiver of type String?"}},
+	}
+
+	for _, test := range tests {
+		errs := types.New().Check(parse(t, test.input))
+		if len(errs) != len(test.errors) {
+			t.Errorf("%q: got errors %v, want %v", test.input, errs, test.errors)
+			continue
+		}
+		for i, err := range errs {
+			if !strings.Contains(err.Error(), test.errors[i]) {
+				t.Errorf("%q: error %q does not mention %q", test.input, err, test.errors[i])
+			}
+		}
+	}
+}
+
+func TestChecker_DefiniteAssignment(t *testing.T) {
+	tests := []struct {
+		input  string
+		errors []string
+	}{
+		{`fun branches(c: Boolean): Int {
+			val x: Int
+			if (c) x = 1 else x = 2
+			return x
+		}
+		fun early(c: Boolean): Int {
+			val x: Int
+			if (c) x = 1 else return 0
+			return x
+		}
+		fun later(): Int {
+			var s: String
+			s = "a"
+			return s.length
+		}
+		fun choice(n: Int): String {
+			val name: String
+			when (n) {
+				1 -> name = "one"
+				2 -> { name = "two" }
+				else -> throw IllegalArgumentException("n")
+			}
+			return name
+		}
+		enum class Color { RED, GREEN }
+		fun hex(color: Color): String {
+			val hex: String
+			when (color) {
+				Color.RED -> hex = "ff0000"
+				Color.GREEN -> hex = "00ff00"
+			}
+			return hex
+		}
+		fun toggle(c: Boolean?): Int {
+			val x: Int
+			when (c) {
+				true -> x = 1
+				false -> x = 2
+				null -> x = 0
+			}
+			return x
+		}
+		fun attempt(): Int {
+			var x: Int
+			try { x = 1 } catch (e: Exception) { x = 2 }
+			return x
+		}
+		fun loop(c: Boolean): Int {
+			var x: Int
+			x = 0
+			while (c) { x = x + 1 }
+			return x
+		}`, nil},
+		{"fun f(): Int { var v2: String\nreturn v2.length }", []string{"[2, 8] Variable 'v2' must be initialized"}},
+		{"fun f(c: Boolean): Int { val x: Int\nif (c) x = 1\nreturn x }", []string{"Variable 'x' must be initialized"}},
+		{"fun f(c: Boolean): Int { var x: Int\nwhile (c) { x = 1 }\nreturn x }", []string{"Variable 'x' must be initialized"}},
+		{"fun f(n: Int): Int { val x: Int\nwhen (n) { 1 -> x = 1\n2 -> x = 2 }\nreturn x }", []string{"Variable 'x' must be initialized"}},
+		{"fun f(c: Boolean): Int { val x: Int\nwhen (c) { true -> x = 1 }\nreturn x }", []string{"Variable 'x' must be initialized"}},
+		{"fun f(c: Boolean?): Int { val x: Int\nwhen (c) { true -> x = 1\nfalse -> x = 2 }\nreturn x }", []string{"Variable 'x' must be initialized"}},
+		{"enum class Color { RED }\nfun f(c: Color?): Int { val x: Int\nwhen (c) { Color.RED -> x = 1 }\nreturn x }", []string{"Variable 'x' must be initialized"}},
+		{"fun f(): Int { var x: Int\ntry { x = 1 } catch (e: Exception) { }\nreturn x }", []string{"Variable 'x' must be initialized"}},
+		{"fun f() { val x: Int\nx = 1\nx = 2 }", []string{"[3, 1] Val cannot be reassigned"}},
+		{"fun f(c: Boolean) { val x: Int\nif (c) x = 1\nx = 2 }", []string{"Val cannot be reassigned"}},
+		{"fun f(c: Boolean) { val x: Int\nwhile (c) { x = 1 } }", []string{"Val cannot be reassigned"}},
+		{"fun f() { val x: Int\nlistOf(1).forEach { x = it } }", []string{"Captured values initialization is forbidden due to possible reassignment"}},
+	}
+
+	for _, test := range tests {
+		errs := types.New().Check(parse(t, test.input))
+		if len(errs) != len(test.errors) {
+			t.Errorf("%q: got errors %v, want %v", test.input, errs, test.errors)
+			continue
+		}
+		for i, err := range errs {
+			if !strings.Contains(err.Error(), test.errors[i]) {
+				t.Errorf("%q: error %q does not mention %q", test.input, err, test.errors[i])
+			}
+		}
+	}
+}
+
+func TestChecker_ControlFlow(t *testing.T) {
+	tests := []struct {
+		input    string
+		errors   []string
+		warnings []string
+	}{
+		{`enum class Color { RED, GREEN }
+		fun fail(): Nothing = throw IllegalStateException("fail")
+		fun loop(x: Int): Int {
+			while (true) {
+				if (x > 0) return x
+			}
+		}
+		fun color(c: Color): Int {
+			when (c) {
+				Color.RED -> return 1
+				Color.GREEN -> return 2
+			}
+		}
+		fun flag(c: Boolean): Int {
+			when (c) {
+				true -> return 1
+				false -> return 2
+			}
+		}
+		fun attempt(): Int {
+			try { return 1 } finally { listOf(1) }
+		}
+		fun failing(x: Int): Int {
+			if (x > 0) return x
+			fail()
+		}
+		fun nullable(s: String?) = s != null
+		fun instance(x: Any) = x is String`, nil, nil},
+		{"fun f(x: Int): Int { if (x > 0) return 1 }", []string{"A 'return' expression required in a function with a block body ('{...}')"}, nil},
+		{"fun f(): Int { }", []string{"A 'return' expression required in a function with a block body ('{...}')"}, nil},
+		{"val g = fun(): Int { }", []string{"A 'return' expression required in a function with a block body ('{...}')"}, nil},
+		{"val x: Int get() { }", []string{"A 'return' expression required in a function with a block body ('{...}')"}, nil},
+		{"fun f(): Int { return 1\nlistOf(1) }", nil, []string{"[2, 1] Unreachable code"}},
+		{"fun fail(): Nothing = throw IllegalStateException()\nfun f(): Int { fail()\nreturn 1 }", nil, []string{"Unreachable code"}},
+		{"fun f() { listOf(1).forEach { return@forEach\nlistOf(it) } }", nil, []string{"Unreachable code"}},
+		{"fun f(s: String) = s != null", nil, []string{"Condition is always 'true'"}},
+		{"fun f(s: String) = null == s", nil, []string{"Condition is always 'false'"}},
+		{"fun f(c: Boolean?): Int { when (c) { true -> return 1\nfalse -> return 2 } }", []string{"A 'return' expression required in a function with a block body ('{...}')"}, nil},
+		{"fun f() { if (true) listOf(1) }", nil, []string{"[1, 15] Condition is always 'true'"}},
+		{"fun f() { while (false) { listOf(1) } }", nil, []string{"[1, 18] Condition is always 'false'"}},
+		{"fun f(): Int { if (1 == 1) return 1\nreturn 2 }", nil, []string{"Condition is always 'true'"}},
+		{"fun f(): Int { if (!(\"a\" != \"a\") && 2 == 2) return 1\nreturn 2 }", nil, []string{"Condition is always 'true'"}},
+		{"fun f(x: Int) { when { x > 0 || false -> listOf(1)\n1 != 1 -> listOf(2) } }", nil, []string{"Condition is always 'false'"}},
+		{"fun f(s: String) = s is String", nil, []string{"Check for instance is always 'true'"}},
+		{"fun f(s: String) = s !is Any", nil, []string{"Check for instance is always 'false'"}},
+	}
+
+	for _, test := range tests {
+		checker := types.New()
+		errs := checker.Check(parse(t, test.input))
+		for _, diagnostics := range []struct {
+			got  []error
+			want []string
+		}{{errs, test.errors}, {checker.Warnings(), test.warnings}} {
+			if len(diagnostics.got) != len(diagnostics.want) {
+				t.Errorf("%q: got %v, want %v", test.input, diagnostics.got, diagnostics.want)
+				continue
+			}
+			for i, err := range diagnostics.got {
+				if !strings.Contains(err.Error(), diagnostics.want[i]) {
+					t.Errorf("%q: %q does not mention %q", test.input, err, diagnostics.want[i])
+				}
+			}
+		}
+	}
+}
+
+func TestChecker_When(t *testing.T) {
+	enum := "enum class Color(val rgb: Int) { RED(1), GREEN(2), BLUE(3) }\n"
+	sealed := `sealed class Result {
+		class Ok(val value: Int) : Result()
+		sealed class Err : Result()
+	}
+	class Timeout : Result.Err()
+	object Refused : Result.Err()
+	`
+	tests := []struct {
+		input  string
+		errors []string
+	}{
+		{enum + `fun warm(c: Color) = when (c) {
+			Color.RED, Color.GREEN -> true
+			Color.BLUE -> false
+		}`, nil},
+		{enum + `fun warm(c: Color) = when (c) {
+			Color.RED -> true
+			else -> false
+		}`, nil},
+		{enum + `fun warm(c: Color) = when (c) {
+			Color.RED -> true
+		}`, []string{"add necessary 'GREEN', 'BLUE' branches or 'else' branch instead"}},
+		{enum + `fun f(c: Color) {
+			when (c) {
+				Color.RED, Color.GREEN -> println(c)
+			}
+		}`, []string{"add necessary 'BLUE' branch or 'else' branch instead"}},
+		{enum + `fun f(c: Color?): Int = when (c) {
+			Color.RED -> 1
+			Color.GREEN -> 2
+		}`, []string{"add necessary 'BLUE', 'null' branches or 'else' branch instead"}},
+		{enum + `fun f(c: Color?): Int = when (c) {
+			Color.RED, Color.GREEN, Color.BLUE -> 1
+			null -> 0
+		}`, nil},
+		{`fun f(n: Int) = when (n) {
+			1 -> "one"
+		}`, []string{"add necessary 'else' branch"}},
+		{`fun f(n: Int) {
+			when (n) {
+				1 -> println(n)
+			}
+		}`, nil},
+		{`fun f(b: Boolean) = when (b) {
+			true -> 1
+			false -> 0
+		}`, nil},
+		{`fun f(b: Boolean) = when (b) {
+			true -> 1
+		}`, []string{"add necessary 'false' branch or 'else' branch instead"}},
+		{sealed + `fun f(r: Result) = when (r) {
+			is Result.Ok -> 1
+			is Result.Err -> 2
+		}`, nil},
+		{sealed + `fun f(r: Result) = when (r) {
+			is Result.Ok -> 1
+			is Timeout -> 2
+			Refused -> 3
+		}`, nil},
+		{sealed + `fun f(r: Result) = when (r) {
+			is Result.Ok -> 1
+			is Timeout -> 2
+		}`, []string{"add necessary 'Refused' branch or 'else' branch instead"}},
+		{sealed + `fun f(r: Result) {
+			when (r) {
+				is Timeout -> println(r)
+			}
+		}`, []string{"add necessary 'is Result.Ok', 'Refused' branches or 'else' branch instead"}},
+		{sealed + `fun f(r: Result.Err) = when (r) {
+			is Timeout -> 1
+			Refused -> 2
+		}`, nil},
+		{`sealed interface Shape
+		sealed class Expr : Shape
+		class Num : Expr()
+		class Neg : Expr()
+		class Circle : Shape
+		class Square : Shape
+		fun f(x: Expr): Int = when (x) {
+			is Num -> 1
+			is Neg -> 2
+		}`, nil},
+		{`sealed class Result
+		class Ok : Result()
+		class Err : Result()
+		typealias Success = Ok
+		fun f(r: Result) = when (r) { is Success -> 1 }`, []string{"add necessary 'is Err' branch"}},
+	}
+
+	for _, test := range tests {
+		errs := types.New().Check(parse(t, test.input))
+		if len(errs) != len(test.errors) {
+			t.Errorf("%q: got errors %v, want %v", test.input, errs, test.errors)
+			continue
+		}
+		for i, err := range errs {
+			if !strings.Contains(err.Error(), test.errors[i]) {
+				t.Errorf("%q: error %q does not mention %q", test.input, err, test.errors[i])
+			}
+		}
+	}
+}
+
+func TestChecker_Destructuring(t *testing.T) {
+	point := "data class Point(val x: Int, val y: Int)\n"
+	tests := []struct {
+		input  string
+		errors []string
+	}{
+		{point + `fun f(p: Point): Int {
+			val (x, y) = p
+			val (_, second) = listOf("a", "b")
+			for ((k, v) in mapOf(1 to "one")) println(v)
+			return x + y
+		}`, nil},
+		{point + "fun f(p: Point) { val (x, y, z) = p }", []string{"[2, 30] Destructuring declaration initializer of type Point must have a 'component3()' function"}},
+		{"fun f() { val (a, b) = 5 }", []string{
+			"Destructuring declaration initializer of type Int must have a 'component1()' function",
+			"Destructuring declaration initializer of type Int must have a 'component2()' function",
+		}},
+		{"fun f(p: Pair<Int, String>) { val (_, b, _) = p }", nil},
+		{"fun f(p: Pair<Int, String>) { val (a: Int, b: Int) = p }", []string{"[1, 44] Type mismatch: inferred type is String but Int was expected"}},
+		{point + "fun f(ps: List<Point>) { for ((x: String, y) in ps) println(y) }", []string{"Type mismatch: inferred type is Int but String was expected"}},
+		{"fun f(n: Int) = listOf(n).map { (a, _) -> a }", []string{"[1, 34] Destructuring declaration initializer of type Int must have a 'component1()' function"}},
+	}
+
+	for _, test := range tests {
+		errs := types.New().Check(parse(t, test.input))
+		if len(errs) != len(test.errors) {
+			t.Errorf("%q: got errors %v, want %v", test.input, errs, test.errors)
+			continue
+		}
+		for i, err := range errs {
+			if !strings.Contains(err.Error(), test.errors[i]) {
+				t.Errorf("%q: error %q does not mention %q", test.input, err, test.errors[i])
+			}
+		}
+	}
+}
+
+func TestChecker_Overloads(t *testing.T) {
+	tests := []struct {
+		input  string
+		errors []string
+	}{
+		{`fun log(msg: String): Int = 1
+		fun log(e: Throwable): String = ""
+		fun log(msg: String, vararg args: Any?): Boolean = args.size > 0
+		fun show(x: Any): Int = 1
+		fun show(x: String?): String = ""
+		fun show(x: String): Boolean = true
+		fun num(x: Long): Long = x
+		fun num(x: Int): Int = x
+		fun num(x: Double): Double = x
+		fun pad(s: String, width: Int = 0): Int = width
+		fun pad(s: String): String = s
+		class Logger {
+			fun log(msg: String): Int = 1
+			fun log(e: Throwable): String = ""
+		}
+		val a: Int = log("x")
+		val b: String = log(IllegalStateException())
+		val c: Boolean = log("x", 1, null)
+		val d: Int = log(msg = "x")
+		val e: Boolean = show("x")
+		val f: String = show(null)
+		val g: Int = show(1)
+		val h: Int = num(1)
+		val i: Long = num(3000000000)
+		val j: Double = num(1.5)
+		val k: String = pad("x")
+		val l: Int = pad("x", width = 2)
+		val m: String = Logger().log(Exception())`, nil},
+		{"fun f(a: Int, b: Int = a) = b\nval x = f(b = 2, a = 1) + f(1)", nil},
+		{"fun f(vararg xs: Int): Array<Int> = xs\nval x = f() + f(1, 2)", nil},
+		{"fun f(a: Any, b: String) {}\nfun f(a: String, b: Any) {}\nval x = f(\"a\", \"b\")", []string{"Overload resolution ambiguity: fun f(a: Any, b: String): Unit; fun f(a: String, b: Any): Unit"}},
+		{"fun log(msg: String) {}\nfun log(e: Throwable) {}\nval x = log(null)", []string{"None of the following functions can be called with the arguments supplied: fun log(msg: String): Unit; fun log(e: Throwable): Unit"}},
+		{"fun log(msg: String) {}\nfun log(text: String): Int = 1", []string{"[2, 5] Conflicting overloads: fun log(msg: String): Unit; fun log(text: String): Int"}},
+		{"fun f(a: Int, b: String) {}\nval x = f(c = 1)", []string{"Cannot find a parameter with this name: c"}},
+		{"fun f(a: Int, b: String) {}\nval x = f(1, a = 2)", []string{"An argument is already passed for this parameter"}},
+		{"fun f(a: Int, b: String) {}\nval x = f(a = 1, \"\")", []string{"Mixing named and positioned arguments is not allowed"}},
+		{"fun f(a: Int = \"\") {}", []string{"Type mismatch: inferred type is String but Int was expected"}},
+		{"fun f(vararg a: Int, vararg b: Int) {}", []string{"Multiple vararg-parameters are prohibited"}},
+		{"val g = fun(a: Int = 1) = a", []string{"An anonymous function is not allowed to specify default values for its parameters"}},
+	}
+
+	for _, test := range tests {
+		errs := types.New().Check(parse(t, test.input))
+		if len(errs) != len(test.errors) {
+			t.Errorf("%q: got errors %v, want %v", test.input, errs, test.errors)
+			continue
+		}
+		for i, err := range errs {
+			if !strings.Contains(err.Error(), test.errors[i]) {
+				t.Errorf("%q: error %q does not mention %q", test.input, err, test.errors[i])
+			}
+		}
+	}
+}
+
+func TestChecker_Visibility(t *testing.T) {
+	tests := []struct {
+		input  string
+		errors []string
+	}{
+		{`open class Account(private val id: Int, protected val owner: String) {
+			private fun audit() = id
+			fun report() = this.audit()
+			fun same(other: Account) = other.id + other.audit()
+			companion object {
+				private fun create() = 1
+			}
+			fun make() = Account.create()
+		}
+		class Savings : Account(1, "a") {
+			fun who(): String = this.owner
+		}
+		internal object Registry {
+			internal val size = 0
+		}
+		val n = Registry.size`, nil},
+		{`class A { private val x = 1 }
+		fun t(a: A) { val y = a.x }`, []string{"Cannot access 'x': it is private in 'A' (declared at [1"}},
+		{`class A { protected fun f() = 1 }
+		fun t() { val a = A()
+		a.f() }`, []string{"Cannot access 'f': it is protected in 'A'"}},
+		{`class Account { private val id = 1 }
+		val n = Account().id`, []string{"Cannot access 'id': it is private in 'Account'"}},
+		{`object Config { private val secret = 1 }
+		fun read() = Config.secret`, []string{"Cannot access 'secret': it is private in 'Config'"}},
+		{`open class Base { private val key = 1 }
+		class Derived : Base() { fun f(other: Base) = other.key }`, []string{"Cannot access 'key': it is private in 'Base'"}},
+		{`class Box { protected fun open() = 1 }
+		fun f() = Box().open()`, []string{"Cannot access 'open': it is protected in 'Box'"}},
+		{`open class Base { private val key = 1 }
+		class Derived : Base() { fun f() = this.key }`, []string{"Cannot access 'key': it is private in 'Base'"}},
+		{`class Outer { private class Hidden }
+		val h = Outer.Hidden()`, []string{"Cannot access 'Hidden': it is private in 'Outer'"}},
+		{`class C { var name = ""
+			private set
+			fun rename(other: C) { other.name = "y" } }
+		fun f(c: C) { c.name = "x" }`, []string{"Cannot assign to 'name': the setter is private in 'C'"}},
+		{`class Counter { var count = 0
+			private set }
+		fun f() { val c = Counter()
+		c.count = 1 }`, []string{"Cannot assign to 'count': the setter is private in 'Counter'"}},
+		{`open class Base { private fun hidden() = 1
+			protected fun shared() = hidden() }
+		class Derived : Base() { fun f() = hidden() + shared() }`, []string{"Cannot access 'hidden': it is private in 'Base'"}},
+		{`open class Base { protected val key = 1 }
+		fun Base.read() = key`, []string{"Cannot access 'key': it is protected in 'Base'"}},
+		{`open class Counter { var c = 0
+			private set
+			fun reset() { c = 0 } }
+		class Derived : Counter() { fun f() { c = 5 } }
+		fun Counter.bump() { c = c + 1 }`, []string{
+			"Cannot assign to 'c': the setter is private in 'Counter'",
+			"Cannot assign to 'c': the setter is private in 'Counter'",
+		}},
+	}
+
+	for _, test := range tests {
+		errs := types.New().Check(parse(t, test.input))
+		if len(errs) != len(test.errors) {
+			t.Errorf("%q: got errors %v, want %v", test.input, errs, test.errors)
+			continue
+		}
+		for i, err := range errs {
+			if !strings.Contains(err.Error(), test.errors[i]) {
+				t.Errorf("%q: error %q does not mention %q", test.input, err, test.errors[i])
+			}
+		}
+	}
+}
+
+func TestChecker_Delegates(t *testing.T) {
+	tests := []struct {
+		input  string
+		errors []string
+	}{
+		{`class Store {
+			operator fun getValue(thisRef: Any?, property: Any?): String = ""
+			operator fun setValue(thisRef: Any?, property: Any?, value: String) {}
+		}
+		class User(map: Map<String, Any>, settings: MutableMap<String, Any>) {
+			val name: String by map
+			var theme: String by settings
+			var email: String by Store()
+			val config by lazy { 1 }
+		}
+		fun local(store: Store) {
+			var alias: String by store
+		}`, nil},
+		{"val x by 5", []string{"Property delegate must have a 'getValue' method"}},
+		{"fun f(s: String) { var x: Int by s }", []string{"Property delegate must have a 'getValue' method", "Property delegate must have a 'setValue' method"}},
+		{"var x by lazy { 1 }", []string{"Property delegate must have a 'setValue' method"}},
+		{`class Store { operator fun getValue(thisRef: Any?, property: Any?): String = "" }
+		fun make() = Store()
+		var name: String by make()`, []string{"Property delegate must have a 'setValue' method"}},
+		{`class Store
+		val name: String by Store()`, []string{"Property delegate must have a 'getValue' method"}},
+		{`class Store
+		operator fun Store.getValue(thisRef: Any?, property: Any?): String = ""
+		val name: String by Store()`, nil},
+		{`import kotlin.properties.Delegates
+		import kotlin.properties.Delegates as D
+		var name: String by Delegates.observable("a") { _, old, new -> println(old + new) }
+		var age: Int by D.vetoable(0) { _, _, new -> new >= 0 }
+		var id: Int by Delegates.notNull()
+		val size: Int by lazy { name.length }`, nil},
+		{"val bad2: String by lazy { 1 }", []string{"[1, 21] Type mismatch: inferred type is Int but String was expected"}},
+		{"var count: String by Delegates.observable(0) { _, _, _ -> }", []string{"Type mismatch: inferred type is Int but String was expected"}},
+		{`class Store { operator fun getValue(thisRef: Any?, property: Any?): Int = 0 }
+		val name: String by Store()`, []string{"Type mismatch: inferred type is Int but String was expected"}},
+		{`interface Base2 { fun show() }
+		class Impl : Base2 { override fun show() {} }
+		class Derived(b: Impl) : Base2 by b
+		class Derived2(b: Impl) : Base2 by 5`, []string{"[4, 38] Type mismatch: inferred type is Int but Base2 was expected"}},
+		{`interface Base2
+		val b = object : Base2 by "text" {}`, []string{"Type mismatch: inferred type is String but Base2 was expected"}},
+	}
+
+	for _, test := range tests {
+		errs := types.New().Check(parse(t, test.input))
+		if len(errs) != len(test.errors) {
+			t.Errorf("%q: got errors %v, want %v", test.input, errs, test.errors)
+			continue
+		}
+		for i, err := range errs {
+			if !strings.Contains(err.Error(), test.errors[i]) {
+				t.Errorf("%q: error %q does not mention %q", test.input, err, test.errors[i])
+			}
+		}
+	}
+}
+
+func TestChecker_CheckPackage(t *testing.T) {
+	files := []struct{ path, source string }{
+		{"a.gt", "val x: Int = text()\nval b = B()"},
+		{"b.gt", "fun text(): String = 1\nclass B { val y: Int = \"y\" }"},
+		{"c.gt", "sealed interface Shape\nclass Square : Shape"},
+		{"d.gt", "object Dot : Shape\nfun sides(s: Shape) = when (s) { is Square -> 4 }"},
+	}
+	var programs []*ast.Program
+	for _, file := range files {
+		program := parser.New(scanner.NewScanner(strings.NewReader(file.source))).Parse()
+		program.File = file.path
+		programs = append(programs, program)
+	}
+	if errs := resolver.New().ResolvePackage(programs, nil); len(errs) > 0 {
+		t.Fatalf("resolver errors %v", errs)
+	}
+
+	errs := types.New().CheckPackage(programs)
+	want := []string{
+		"a.gt: [1, 14] Type mismatch: inferred type is String but Int was expected",
+		"b.gt: [1, 22] Type mismatch: inferred type is Int but String was expected",
+		"b.gt: [2, 24] Type mismatch: inferred type is String but Int was expected",
+		"d.gt: [2, 23] 'when' expression must be exhaustive, add necessary 'Dot' branch or 'else' branch instead",
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("got errors %v, want %v", errs, want)
+	}
+	for i, err := range errs {
+		if err.Error() != want[i] {
+			t.Errorf("error %q, want %q", err, want[i])
+		}
+	}
+}
+
+func TestChecker_Imports(t *testing.T) {
+	packages := []struct {
+		file, source string
+		exports      []string
+	}{
+		{"util/util.gt", "package util\nfun one(): Int = 1\nclass Box(val v: Int)\nfun Int.twice() = this * 2\ntypealias Count = Int\nopen class Base { protected val p = 1; private val q = 2 }", []string{"Base", "Box", "Count", "one", "twice"}},
+		{"app/app.gt", "package app\nimport util.*\nimport util.one as first\nval n: String = one()\nval b: Box = Box(1)\nval s: String = b.v\nval t: String = 2.twice()\nval c: Count = \"c\"\nval m: String = first()\nclass D : Base() { val r: Int = p }\nfun g(b: Base) = b.p + b.q", nil},
+	}
+	exports := make(map[string][]string)
+	checker := types.New()
+	var errs []error
+	for _, pkg := range packages {
+		program := parser.New(scanner.NewScanner(strings.NewReader(pkg.source))).Parse()
+		program.File = pkg.file
+		if errs := resolver.New().ResolvePackage([]*ast.Program{program}, exports); len(errs) > 0 {
+			t.Fatalf("resolver errors %v", errs)
+		}
+		exports[program.Package.Name] = pkg.exports
+		errs = append(errs, checker.CheckPackage([]*ast.Program{program})...)
+	}
+	want := []string{
+		"app/app.gt: [4, 17] Type mismatch: inferred type is Int but String was expected",
+		"app/app.gt: [6, 17] Type mismatch: inferred type is Int but String was expected",
+		"app/app.gt: [7, 17] Type mismatch: inferred type is Int but String was expected",
+		"app/app.gt: [8, 16] Type mismatch: inferred type is String but Count was expected",
+		"app/app.gt: [9, 17] Type mismatch: inferred type is Int but String was expected",
+		"app/app.gt: [11, 20] Cannot access 'p': it is protected in 'Base' (declared at util/util.gt [6, 33])",
+		"app/app.gt: [11, 26] Cannot access 'q': it is private in 'Base' (declared at util/util.gt [6, 52])",
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("got errors %v, want %v", errs, want)
+	}
+	for i, err := range errs {
+		if err.Error() != want[i] {
+			t.Errorf("error %q, want %q", err, want[i])
+		}
+	}
+}
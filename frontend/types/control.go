@@ -0,0 +1,128 @@
+package types
+
+import (
+	"gotlin/frontend/ast"
+	"gotlin/frontend/cfg"
+	"gotlin/frontend/token"
+)
+
+// flowInfo tells the builder of control-flow graphs what the checker found
+// about the code.
+type flowInfo struct {
+	c *Checker
+}
+
+func (i flowInfo) NoReturn(call ast.Expr) bool {
+	return isNothing(i.c.types[call])
+}
+
+func (i flowInfo) Exhaustive(when *ast.WhenExpr) bool {
+	return i.c.exhaustive[when]
+}
+
+// controlFlow builds the control-flow graph of a block body that has been
+// checked, and warns about the code it cannot reach. A body whose result
+// is not Unit must not be able to end without returning; result is nil for
+// the body of a lambda, whose value is that of its last expression.
+func (c *Checker) controlFlow(body []ast.Stmt, result Type, pos token.Pos) {
+	graph := cfg.New(body, flowInfo{c})
+	for _, node := range graph.Unreachable() {
+		c.warn(nodePosition(node), "Unreachable code")
+	}
+	if result != nil && graph.Exit.Live && !isUnknown(result) && !isClass(result, unitClass) {
+		c.report(pos, "A 'return' expression required in a function with a block body ('{...}')")
+	}
+}
+
+// nodePosition returns where a node of a control-flow graph starts.
+func nodePosition(node any) token.Pos {
+	switch n := node.(type) {
+	case ast.Expr:
+		return ast.Position(n)
+	case ast.Stmt:
+		return ast.StmtPosition(n)
+	}
+	return token.Pos{}
+}
+
+// nullComparison warns about the comparison of null with a value whose type
+// is not nullable, whose result is always the same.
+func (c *Checker) nullComparison(e *ast.BinaryExpr, left Type, right Type) {
+	value := left
+	if _, ok := e.Left.(*ast.NullLiteral); ok {
+		value = right
+	} else if _, ok := e.Right.(*ast.NullLiteral); !ok {
+		return
+	}
+	if !isNullable(value) && !isUnknown(value) {
+		c.warn(ast.Position(e), "Condition is always '%t'", e.Op.Kind == token.NOT_EQ)
+	}
+}
+
+// constant returns the value of a condition that is always the same,
+// such as `true` or `1 == 1`: one made of literals.
+func constant(expr ast.Expr) (value bool, ok bool) {
+	switch e := expr.(type) {
+	case *ast.GroupingExpr:
+		return constant(e.Expr)
+	case *ast.BoolLiteral:
+		return e.Value, true
+	case *ast.UnaryExpr:
+		if value, ok := constant(e.Right); ok && e.Op.Kind == token.NOT {
+			return !value, true
+		}
+	case *ast.BinaryExpr:
+		switch e.Op.Kind {
+		case token.AND, token.OR:
+			left, ok := constant(e.Left)
+			if !ok {
+				return false, false
+			}
+			right, ok := constant(e.Right)
+			if !ok {
+				return false, false
+			}
+			if e.Op.Kind == token.AND {
+				return left && right, true
+			}
+			return left || right, true
+		case token.EQ_EQ, token.NOT_EQ:
+			left, ok := literal(e.Left)
+			if !ok {
+				return false, false
+			}
+			right, ok := literal(e.Right)
+			if !ok {
+				return false, false
+			}
+			return (left == right) == (e.Op.Kind == token.EQ_EQ), true
+		}
+	}
+	return false, false
+}
+
+// literal returns the value of a literal that can be compared with ==.
+func literal(expr ast.Expr) (any, bool) {
+	switch e := expr.(type) {
+	case *ast.GroupingExpr:
+		return literal(e.Expr)
+	case *ast.IntLiteral:
+		return e.Value, true
+	case *ast.BoolLiteral:
+		return e.Value, true
+	case *ast.StringLiteral:
+		return e.Value, true
+	}
+	return nil, false
+}
+
+// instanceCheck warns about an is check on a value of type t, which is
+// always an instance of the type checked.
+func (c *Checker) instanceCheck(e *ast.IsExpr, t Type) {
+	target := c.typeOf(e.Type)
+	named, ok := nonNull(t).(*Named)
+	if !ok || named.Class.Open || isUnknown(t) || isUnknown(target) || !Assignable(t, target) {
+		return
+	}
+	c.warn(ast.Position(e), "Check for instance is always '%t'", e.Op.Kind == token.IS)
+}
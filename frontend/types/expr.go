@@ -0,0 +1,890 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+
+	"gotlin/frontend/ast"
+	"gotlin/frontend/token"
+)
+
+// operatorFunction returns the member function an operator calls on
+// operands the checker does not type itself.
+func operatorFunction(op token.Token, unary bool) string {
+	if unary {
+		name, _ := token.UnaryOperatorFunction(op.Kind)
+		return name
+	}
+	name, _ := token.BinaryOperatorFunction(op.Kind)
+	return name
+}
+
+// checkExpr checks expr and returns its type. expected is the type the
+// context needs, if any, which types literals and lambdas; the caller
+// reports a mismatch. Each expression is checked once, so asking again
+// returns the type found the first time.
+func (c *Checker) checkExpr(expr ast.Expr, expected Type) Type {
+	if t, ok := c.types[expr]; ok {
+		return t
+	}
+	t := c.exprType(expr, expected)
+	c.types[expr] = t
+	return t
+}
+
+// expect checks expr where a value of type expected is needed.
+func (c *Checker) expect(expr ast.Expr, expected Type) Type {
+	t := c.checkExpr(expr, expected)
+	c.conform(expr, t, expected)
+	return t
+}
+
+// conform reports a mismatch when expr, of type t, is used where a value of
+// type expected is needed.
+func (c *Checker) conform(expr ast.Expr, t Type, expected Type) {
+	if Assignable(t, expected) || c.smartCastImpossible(expr, func(cast Type) bool { return Assignable(cast, expected) }) {
+		return
+	}
+	c.mismatch(ast.Position(expr), t, expected)
+}
+
+// condition checks a condition, which must be a Boolean, and warns when
+// its value is always the same.
+func (c *Checker) condition(expr ast.Expr) {
+	if t := c.checkExpr(expr, Boolean); !Assignable(t, Boolean) {
+		c.report(ast.Position(expr), "Condition type mismatch: inferred type is %s but Boolean was expected", t)
+	}
+	if value, ok := constant(expr); ok {
+		c.warn(ast.Position(expr), "Condition is always '%t'", value)
+	}
+}
+
+func (c *Checker) exprType(expr ast.Expr, expected Type) Type {
+	switch e := expr.(type) {
+	case *ast.IntLiteral:
+		return c.intLiteral(e, e.Value, expected)
+	case *ast.DoubleLiteral:
+		return Double
+	case *ast.BoolLiteral:
+		return Boolean
+	case *ast.StringLiteral:
+		return String
+	case *ast.NullLiteral:
+		return Null
+	case *ast.IdentifierExpr:
+		return c.identifier(e)
+	case *ast.ThisExpr:
+		if e.Label.Spelling != "" {
+			if t, ok := c.ctx.labels[e.Label.Spelling]; ok {
+				return t
+			}
+			return Unknown
+		}
+		if c.ctx.this == nil {
+			return Unknown
+		}
+		return c.ctx.this
+	case *ast.GroupingExpr:
+		return c.checkExpr(e.Expr, expected)
+	case *ast.AnnotatedExpr:
+		return c.checkExpr(e.Expr, expected)
+	case *ast.UnaryExpr:
+		return c.unary(e, expected)
+	case *ast.BinaryExpr:
+		return c.binary(e, expected)
+	case *ast.IsExpr:
+		c.instanceCheck(e, c.checkExpr(e.Expr, nil))
+		return Boolean
+	case *ast.NonNullableExpr:
+		t := nonNull(c.checkExpr(e.Expr, nil))
+		c.smartCast(e.Expr, t)
+		return t
+	case *ast.MemberExpr:
+		return c.member(e)
+	case *ast.CallExpr:
+		return c.call(e, expected)
+	case *ast.InfixCallExpr:
+		return c.infixCall(e)
+	case *ast.LambdaExpr:
+		return c.lambda(e, expected)
+	case *ast.FunctionLiteral:
+		return c.functionLiteral(e)
+	case *ast.ObjectExpr:
+		return c.objectExpr(e)
+	case *ast.IfExpr:
+		return c.ifExpr(e)
+	case *ast.WhenExpr:
+		return c.when(e)
+	case *ast.ThrowExpr:
+		c.expect(e.Expr, throwable)
+		return Nothing
+	case *ast.TryExpr:
+		return c.tryExpr(e)
+	case *ast.ReturnExpr:
+		c.returnExpr(e)
+		return Nothing
+	case *ast.JumpExpr:
+		return Nothing
+	}
+	return Unknown
+}
+
+// intLiteral types an integer literal of the given value, which becomes a
+// Long, Short or Byte where one is expected and is a Long if it does not fit
+// an Int.
+func (c *Checker) intLiteral(literal *ast.IntLiteral, value int64, expected Type) Type {
+	t, ok := integerType(value, expected)
+	if !ok {
+		c.report(literal.Position, "The integer literal does not conform to the expected type %s", expected)
+	}
+	return t
+}
+
+// integerType returns the type of an integer literal of the given value
+// where a value of type expected is needed, and reports whether the
+// literal fits it.
+func integerType(value int64, expected Type) (Type, bool) {
+	if value != int64(int32(value)) {
+		return Long, true
+	}
+	named, ok := nonNull(expected).(*Named)
+	if !ok {
+		return Int, true
+	}
+	switch {
+	case named.Class == longClass,
+		named.Class == shortClass && value == int64(int16(value)),
+		named.Class == byteClass && value == int64(int8(value)):
+		return named, true
+	case named.Class == shortClass, named.Class == byteClass, named.Class == doubleClass, named.Class == floatClass:
+		return named, false
+	}
+	return Int, true
+}
+
+// identifier returns the type of the declaration a name is bound to.
+func (c *Checker) identifier(id *ast.IdentifierExpr) Type {
+	if id.Binding == nil {
+		return Unknown
+	}
+	if id.Binding.Symbol.Decl == nil {
+		if class, ok := builtinClasses[id.Binding.Symbol.Name.Spelling]; ok {
+			if class.Object {
+				return &Named{Class: class}
+			}
+			return &classifier{class: class}
+		}
+		return Unknown
+	}
+	decl := id.Binding.Symbol.Decl
+	if directive, ok := decl.(*ast.ImportDirective); ok {
+		imported := c.importedBy(directive, id.Value.Spelling)
+		if len(imported) != 1 {
+			return Unknown
+		}
+		decl = imported[0]
+	}
+	c.checkImplicitAccess(id, false)
+	c.initialized(id)
+	if t, ok := c.ctx.casts[decl]; ok {
+		reason := c.unstable(id)
+		if reason == "" {
+			return t
+		}
+		c.impossible[id] = impossibleCast{t: t, reason: reason}
+	}
+	return c.declType(decl)
+}
+
+func (c *Checker) unary(e *ast.UnaryExpr, expected Type) Type {
+	if literal, ok := e.Right.(*ast.IntLiteral); ok && e.Op.Kind == token.DASH {
+		c.types[literal] = c.intLiteral(literal, -literal.Value, expected)
+		return c.types[literal]
+	}
+	operand := c.operand(e.Right, c.checkExpr(e.Right, nil), operatorFunction(e.Op, true), e.Op.Position)
+	switch {
+	case isUnknown(operand):
+		return Unknown
+	case e.Op.Kind == token.NOT && isClass(operand, booleanClass):
+		return Boolean
+	case e.Op.Kind != token.NOT && isNumeric(operand):
+		return widen(operand, Int)
+	}
+	if t, ok := c.operator(e.Right, operand, operatorFunction(e.Op, true), nil, e.Op.Position); ok {
+		return t
+	}
+	c.report(e.Op.Position, "Operator '%s' cannot be applied to '%s'", e.Op.Spelling, operand)
+	return Unknown
+}
+
+func (c *Checker) binary(e *ast.BinaryExpr, expected Type) Type {
+	switch e.Op.Kind {
+	case token.AND, token.OR:
+		c.expect(e.Left, Boolean)
+		left := c.ctx
+		c.ctx = left.cast(c.casts(e.Left, e.Op.Kind == token.AND))
+		c.expect(e.Right, Boolean)
+		// The right operand may not have run.
+		c.ctx = left.join(left, c.ctx)
+		return Boolean
+	case token.ELVIS:
+		left := c.checkExpr(e.Left, expected)
+		before := c.ctx
+		right := c.checkExpr(e.Right, expected)
+		if !isNothing(right) {
+			c.ctx = before.join(before, c.ctx)
+		} else {
+			c.ctx = before
+			// `x ?: return` only goes on when x is not null.
+			c.smartCast(e.Left, nonNull(left))
+		}
+		return lub(nonNull(left), right)
+	}
+
+	left := c.checkExpr(e.Left, nil)
+	right := c.checkExpr(e.Right, nil)
+	if isUnknown(left) || isUnknown(right) {
+		switch e.Op.Kind {
+		case token.EQ_EQ, token.NOT_EQ, token.LT, token.LTE, token.GT, token.GTE, token.IN, token.NOT_IN:
+			return Boolean
+		}
+		return Unknown
+	}
+
+	switch e.Op.Kind {
+	case token.LT, token.LTE, token.GT, token.GTE:
+		left = c.operand(e.Left, left, operatorFunction(e.Op, false), e.Op.Position)
+	case token.PLUS, token.DASH, token.STAR, token.SLASH, token.PERCENT:
+		left = c.operand(e.Left, left, operatorFunction(e.Op, false), e.Op.Position)
+	}
+
+	switch e.Op.Kind {
+	case token.EQ_EQ, token.NOT_EQ:
+		if final(left) && final(right) && !Assignable(nonNull(left), nonNull(right)) && !Assignable(nonNull(right), nonNull(left)) {
+			c.report(e.Op.Position, "Operator '%s' cannot be applied to '%s' and '%s'", e.Op.Spelling, left, right)
+		}
+		c.nullComparison(e, left, right)
+		return Boolean
+	case token.LT, token.LTE, token.GT, token.GTE:
+		if isNumeric(left) && isNumeric(right) {
+			return Boolean
+		}
+		if _, ok := c.operator(e.Left, left, operatorFunction(e.Op, false), []ast.Expr{e.Right}, e.Op.Position); ok {
+			return Boolean
+		}
+	case token.IN, token.NOT_IN:
+		if _, ok := c.operator(e.Right, right, operatorFunction(e.Op, false), []ast.Expr{e.Left}, e.Op.Position); ok {
+			return Boolean
+		}
+	case token.PLUS, token.DASH, token.STAR, token.SLASH, token.PERCENT:
+		if isNumeric(left) && isNumeric(right) {
+			return widen(left, right)
+		}
+		if isClass(left, charClass) && (e.Op.Kind == token.PLUS || e.Op.Kind == token.DASH) {
+			if isClass(right, intClass) {
+				return Char
+			}
+			if isClass(right, charClass) && e.Op.Kind == token.DASH {
+				return Int
+			}
+		}
+		fallthrough
+	default:
+		if t, ok := c.operator(e.Left, left, operatorFunction(e.Op, false), []ast.Expr{e.Right}, e.Op.Position); ok {
+			return t
+		}
+	}
+	c.report(e.Op.Position, "Operator '%s' cannot be applied to '%s' and '%s'", e.Op.Spelling, left, right)
+	return Unknown
+}
+
+// operand returns the type of the receiver of an operator the checker
+// types itself on numbers, booleans and chars, which must not be nullable.
+func (c *Checker) operand(receiver ast.Expr, t Type, name string, pos token.Pos) Type {
+	value := nonNull(t)
+	if isNullable(t) && (isNumeric(value) || isClass(value, booleanClass) || isClass(value, charClass)) {
+		c.nullSafe(receiver, t, name, pos)
+		return value
+	}
+	return t
+}
+
+// operator calls the operator function called name on receiver, a value
+// of type t, with args, and reports whether t has one. Unknown receivers have all.
+func (c *Checker) operator(receiver ast.Expr, t Type, name string, args []ast.Expr, pos token.Pos) (Type, bool) {
+	if name == "" {
+		return nil, false
+	}
+	if isUnknown(t) {
+		return Unknown, true
+	}
+	c.nullSafe(receiver, t, name, pos)
+	candidates := c.methods(nonNull(t), name)
+	if len(candidates) == 0 {
+		if named, ok := nonNull(t).(*Named); ok && named.Class.Open {
+			return Unknown, true
+		}
+		return nil, false
+	}
+	return c.invoke(candidates, args, nil, nil, pos), true
+}
+
+// member returns the type of a property read through a receiver,
+// `receiver.name`.
+func (c *Checker) member(e *ast.MemberExpr) Type {
+	receiver := c.checkExpr(e.Receiver, nil)
+	if e.Safe {
+		receiver = nonNull(receiver)
+	} else {
+		c.nullSafe(e.Receiver, receiver, e.Name.Spelling, e.Name.Position)
+	}
+	t, ok := c.property(receiver, e.Name.Spelling)
+	if !ok {
+		c.report(e.Name.Position, "Unresolved reference: %s", e.Name.Spelling)
+	}
+	c.checkAccess(receiver, e.Name, false)
+	if e.Safe {
+		return nullable(t)
+	}
+	return t
+}
+
+// property returns the type of the property called name of a value of
+// type receiver, and reports whether the receiver may have it.
+func (c *Checker) property(receiver Type, name string) (Type, bool) {
+	members, bindings := c.members(receiver, name)
+	for _, m := range members {
+		if m.Signature == nil {
+			if _, ok := m.Decl.(*ast.FunctionDecl); !ok {
+				return c.memberType(m, bindings), true
+			}
+		}
+	}
+	for _, ext := range c.extensionsOf(receiver, name) {
+		if decl, ok := ext.(*ast.VariableDecl); ok {
+			return c.declType(decl), true
+		}
+	}
+	return Unknown, !knowsMembers(receiver)
+}
+
+// knowsMembers reports whether the checker knows every member of a value
+// of type t, so that a name it cannot find is an error. Built-in classes
+// are only described in part, as are classes with unknown supertypes.
+func knowsMembers(t Type) bool {
+	switch t := nonNull(t).(type) {
+	case *Named:
+		return !t.Class.Open && t.Class.decl != nil
+	case *classifier:
+		return t.class.decl != nil
+	}
+	return false
+}
+
+// infixCall types `left name right`, a call to an infix function.
+func (c *Checker) infixCall(e *ast.InfixCallExpr) Type {
+	left := c.checkExpr(e.Left, nil)
+	if e.Name.Spelling == "to" {
+		return named(pairClass, left, c.checkExpr(e.Right, nil))
+	}
+	c.nullSafe(e.Left, left, e.Name.Spelling, e.Name.Position)
+	candidates := c.methods(nonNull(left), e.Name.Spelling)
+	if len(candidates) == 0 {
+		c.checkExpr(e.Right, nil)
+		return Unknown
+	}
+	if infix := infixCandidates(candidates); len(infix) > 0 {
+		candidates = infix
+	} else {
+		c.report(e.Name.Position, "'infix' modifier is required on '%s'", e.Name.Spelling)
+	}
+	return c.invoke(candidates, []ast.Expr{e.Right}, nil, nil, e.Name.Position)
+}
+
+// infixCandidates returns the candidates an infix call may invoke: the
+// builtin ones and the functions declared infix.
+func infixCandidates(candidates []candidate) []candidate {
+	var infix []candidate
+	for _, cand := range candidates {
+		if cand.sig.Decl == nil || cand.sig.Decl.Modifiers.Has(modifierInfix) {
+			infix = append(infix, cand)
+		}
+	}
+	return infix
+}
+
+// lambda types a lambda. Where a function type is expected, it gives the
+// lambda its parameter types and the type of its result, which is the
+// value of the last expression of the body unless Unit is expected.
+func (c *Checker) lambda(e *ast.LambdaExpr, expected Type) Type {
+	defer c.enter(c.ctx.closure())()
+	fn, _ := nonNull(expected).(*Function)
+	var params []Type
+	if len(e.Parameters) == 0 {
+		if fn != nil && len(fn.Params) == 1 {
+			c.decls[e] = known(fn.Params[0])
+			params = []Type{c.decls[e]}
+		}
+	}
+	for i, p := range e.Parameters {
+		t := Unknown
+		switch {
+		case p.Type != nil:
+			t = c.typeOf(p.Type)
+		case fn != nil && len(fn.Params) == len(e.Parameters):
+			t = known(fn.Params[i])
+		}
+		if p.Pattern != nil {
+			c.destructure(p.Pattern, t)
+		} else {
+			c.decls[p] = t
+		}
+		params = append(params, t)
+	}
+
+	var result Type
+	if fn != nil && !isUnknown(fn.Result) {
+		result = fn.Result
+	}
+	t := &Function{Params: params, Result: Unit}
+	if len(e.Body) == 0 {
+		return t
+	}
+	last, ok := e.Body[len(e.Body)-1].(*ast.ExprStmt)
+	c.checkStmts(e.Body[:len(e.Body)-1])
+	switch {
+	case !ok:
+		c.checkStmt(e.Body[len(e.Body)-1])
+	case result != nil && isClass(result, unitClass):
+		c.value(last.Expr, false)
+	case result != nil:
+		// A mismatch is reported in the body, not again for the lambda.
+		c.expect(last.Expr, result)
+		t.Result = result
+	default:
+		t.Result = c.checkExpr(last.Expr, nil)
+	}
+	c.controlFlow(e.Body, nil, e.Open.Position)
+	return t
+}
+
+// known is t, unless it still refers to type parameters that have not been
+// inferred.
+func known(t Type) Type {
+	if containsTypeParam(t) {
+		return Unknown
+	}
+	return t
+}
+
+func containsTypeParam(t Type) bool {
+	switch tp := t.(type) {
+	case *TypeParam:
+		return true
+	case *Nullable:
+		return containsTypeParam(tp.Type)
+	case *Named:
+		for _, arg := range tp.Args {
+			if containsTypeParam(arg) {
+				return true
+			}
+		}
+	case *Function:
+		for _, param := range tp.Params {
+			if containsTypeParam(param) {
+				return true
+			}
+		}
+		return containsTypeParam(tp.Result)
+	}
+	return false
+}
+
+// functionLiteral types an anonymous function, `fun(x: Int) = x + 1`.
+func (c *Checker) functionLiteral(e *ast.FunctionLiteral) Type {
+	t := &Function{Result: Unit}
+	for _, p := range e.Parameters {
+		if p.DefaultValue != nil {
+			c.report(p.Position, "An anonymous function is not allowed to specify default values for its parameters")
+		}
+		c.decls[p] = c.typeOf(p.Type)
+		t.Params = append(t.Params, c.decls[p])
+	}
+	if e.Type != nil {
+		t.Result = c.typeOf(e.Type)
+	}
+	ctx := c.ctx.closure()
+	ctx.result = t.Result
+	defer c.enter(ctx)()
+	if e.Body.Expr != nil {
+		body := c.checkExpr(e.Body.Expr, nil)
+		if e.Type == nil {
+			t.Result = body
+		} else {
+			c.conform(e.Body.Expr, body, t.Result)
+		}
+	} else {
+		c.checkStmts(e.Body.Block)
+		c.controlFlow(e.Body.Block, t.Result, e.Fun.Position)
+	}
+	return t
+}
+
+// objectExpr types an anonymous object, whose type is a class of its own
+// extending its supertypes.
+func (c *Checker) objectExpr(e *ast.ObjectExpr) Type {
+	class := newClass("<no name provided>")
+	class.outer = c.ctx.class
+	c.classes[e] = class
+	for _, superType := range e.SuperTypes {
+		super, ok := c.typeOf(superType.Type).(*Named)
+		if ok && super.Class != anyClass {
+			class.Supers = append(class.Supers, super)
+			c.superCall(super, superType, ast.Position(e))
+		} else if !ok {
+			class.Open = true
+		}
+		c.superDelegate(super, superType.Delegate)
+	}
+	if len(class.Supers) == 0 {
+		class.Supers = []Type{Any}
+	}
+	self := &Named{Class: class}
+	ctx := c.ctx.closure().receiver("", self)
+	ctx.class = class
+	c.addMembers(class, e.Members, ctx)
+	defer c.enter(ctx)()
+	c.checkStmts(e.Members)
+	return self
+}
+
+// ifExpr types an if, whose value is that of the branch taken. Each branch
+// is checked with the smart casts of the condition that leads to it, and
+// when one branch cannot complete, those leading to the other hold after
+// the if, as after `if (x == null) return`. A variable is assigned after
+// the if when it is at the end of every branch that completes.
+func (c *Checker) ifExpr(e *ast.IfExpr) Type {
+	c.condition(e.Condition)
+	before := c.ctx
+	var paths []*context
+	c.ctx = before.cast(c.casts(e.Condition, true))
+	used := !c.discarded[e]
+	then := c.branch(e.Then, used)
+	if !isNothing(then) {
+		paths = append(paths, c.ctx)
+	}
+	c.ctx = before.cast(c.casts(e.Condition, false))
+	otherwise := Unit
+	if e.Else != nil {
+		otherwise = c.branch(e.Else, used)
+	}
+	if !isNothing(otherwise) {
+		paths = append(paths, c.ctx)
+	}
+	c.ctx = before.uncast(assigned(e.Then, e.Else)).join(paths...)
+	switch {
+	case isNothing(then):
+		c.ctx = c.ctx.cast(c.casts(e.Condition, false))
+	case isNothing(otherwise):
+		c.ctx = c.ctx.cast(c.casts(e.Condition, true))
+	}
+	if e.Else == nil {
+		return Unit
+	}
+	return lub(then, otherwise)
+}
+
+// when types a when expression, whose value is that of the branch taken.
+// A branch is checked with the smart casts of its condition, and of the
+// conditions before it being false.
+func (c *Checker) when(e *ast.WhenExpr) Type {
+	var subject Type
+	if e.Subject != nil {
+		subject = c.checkExpr(e.Subject, nil)
+	}
+	before := c.ctx
+	var t Type
+	var bodies []ast.Stmt
+	var paths []*context
+	for _, branch := range e.Branches {
+		rest := c.ctx
+		var taken, skipped map[any]Type
+		for _, condition := range branch.Conditions {
+			switch {
+			case condition.Type != nil:
+			case subject == nil:
+				c.condition(condition.Expr)
+			case condition.Op.Kind == token.IN || condition.Op.Kind == token.NOT_IN:
+				c.checkExpr(condition.Expr, nil)
+			default:
+				value := c.checkExpr(condition.Expr, nil)
+				if final(subject) && final(value) && !Assignable(nonNull(value), nonNull(subject)) && !Assignable(nonNull(subject), nonNull(value)) {
+					c.report(ast.Position(condition.Expr), "Incompatible types: %s and %s", value, subject)
+				}
+			}
+			taken = c.whenCasts(e.Subject, subject, condition, true)
+			for decl, cast := range c.whenCasts(e.Subject, subject, condition, false) {
+				if skipped == nil {
+					skipped = make(map[any]Type)
+				}
+				skipped[decl] = cast
+			}
+		}
+		if len(branch.Conditions) == 1 {
+			c.ctx = rest.cast(taken)
+		}
+		body := c.branch(branch.Body, !c.discarded[e])
+		if !isNothing(body) {
+			paths = append(paths, c.ctx)
+		}
+		t = lub(t, body)
+		c.ctx = rest.cast(skipped)
+		bodies = append(bodies, branch.Body)
+	}
+	if !c.checkExhaustive(e, subject) {
+		paths = append(paths, c.ctx)
+	}
+	c.ctx = before.uncast(assigned(bodies...)).join(paths...)
+	if t == nil {
+		return Unit
+	}
+	return t
+}
+
+// checkExhaustive reports whether a when always takes one of its
+// branches: it has an else branch, or covers every value of its subject,
+// null included. A when used as an expression must be exhaustive, and one
+// over an enum or sealed subject must cover its entries or classes even
+// when its value is not used.
+func (c *Checker) checkExhaustive(e *ast.WhenExpr, subject Type) bool {
+	for _, branch := range e.Branches {
+		if branch.Else {
+			c.exhaustive[e] = true
+			return true
+		}
+	}
+	missing, listed := c.missingBranches(e, subject, true)
+	c.exhaustive[e] = listed && len(missing) == 0
+	if c.discarded[e] {
+		missing, _ = c.missingBranches(e, subject, false)
+	} else if !listed {
+		c.report(e.When.Position, "'when' expression must be exhaustive, add necessary 'else' branch")
+	}
+	switch {
+	case len(missing) == 1:
+		c.report(e.When.Position, "'when' expression must be exhaustive, add necessary %s branch or 'else' branch instead", missing[0])
+	case len(missing) > 1:
+		c.report(e.When.Position, "'when' expression must be exhaustive, add necessary %s branches or 'else' branch instead", strings.Join(missing, ", "))
+	}
+	return c.exhaustive[e]
+}
+
+// missingBranches returns the branches a when without an else branch
+// lacks to cover every value of a subject of type subject, and false when
+// these values cannot be listed: only those of a Boolean, enum or sealed
+// subject can. Unless used is true, the when only needs to cover the
+// entries of an enum or the classes of a sealed hierarchy.
+func (c *Checker) missingBranches(e *ast.WhenExpr, subject Type, used bool) ([]string, bool) {
+	named, ok := nonNull(subject).(*Named)
+	if e.Subject == nil || !ok {
+		return nil, false
+	}
+	booleans := make(map[bool]bool)
+	entries := make(map[string]bool)
+	classes := make(map[*Class]bool)
+	null := false
+	for _, branch := range e.Branches {
+		for _, condition := range branch.Conditions {
+			switch {
+			case condition.Type != nil:
+				if is, ok := c.typeOf(condition.Type).(*Named); ok && condition.Op.Kind == token.IS {
+					classes[is.Class] = true
+				}
+			case condition.Op.Kind != "":
+			default:
+				switch value := condition.Expr.(type) {
+				case *ast.BoolLiteral:
+					booleans[value.Value] = true
+				case *ast.NullLiteral:
+					null = true
+				case *ast.MemberExpr:
+					if enum, ok := c.types[value.Receiver].(*classifier); ok && enum.class == named.Class {
+						entries[value.Name.Spelling] = true
+					}
+				}
+				if object, ok := c.types[condition.Expr].(*Named); ok && object.Class.Object {
+					classes[object.Class] = true
+				}
+			}
+		}
+	}
+
+	var missing []string
+	decl, _ := named.Class.decl.(*ast.ClassDeclStmt)
+	switch {
+	case named.Class == booleanClass:
+		for _, value := range []bool{true, false} {
+			if used && !booleans[value] {
+				missing = append(missing, fmt.Sprintf("'%t'", value))
+			}
+		}
+	case decl != nil && decl.Modifiers.Has(modifierEnum):
+		for _, entry := range decl.Entries {
+			if !entries[entry.Name.Spelling] {
+				missing = append(missing, fmt.Sprintf("'%s'", entry.Name.Spelling))
+			}
+		}
+	case decl != nil && decl.Modifiers.Has(modifierSealed):
+		for _, class := range missingCases(named.Class, classes) {
+			if class.Object {
+				missing = append(missing, fmt.Sprintf("'%s'", class.Name))
+			} else {
+				missing = append(missing, fmt.Sprintf("'is %s'", class.Name))
+			}
+		}
+	default:
+		return nil, false
+	}
+	if used && isNullable(subject) && !null {
+		missing = append(missing, "'null'")
+	}
+	return missing, true
+}
+
+// missingCases returns the classes of the sealed hierarchy below class
+// that the classes covered leave out. A sealed class is covered by all of
+// its subclasses.
+func missingCases(class *Class, covered map[*Class]bool) []*Class {
+	if covered[class] {
+		return nil
+	}
+	decl, ok := class.decl.(*ast.ClassDeclStmt)
+	subclasses := class.subclasses()
+	if !ok || !decl.Modifiers.Has(modifierSealed) || len(subclasses) == 0 {
+		return []*Class{class}
+	}
+	var missing []*Class
+	for _, sub := range subclasses {
+		missing = append(missing, missingCases(sub, covered)...)
+	}
+	return missing
+}
+
+// whenCasts returns the smart casts that hold where a condition of a when
+// with the given subject is truth.
+func (c *Checker) whenCasts(subject ast.Expr, t Type, condition *ast.WhenCondition, truth bool) map[any]Type {
+	if subject == nil {
+		return c.casts(condition.Expr, truth)
+	}
+	decl := declOf(subject)
+	if decl == nil {
+		return nil
+	}
+	switch {
+	case condition.Type != nil && (condition.Op.Kind == token.IS) == truth:
+		return map[any]Type{decl: castTo(t, c.typeOf(condition.Type))}
+	case condition.Op.Kind == "" && !truth:
+		if _, ok := condition.Expr.(*ast.NullLiteral); ok {
+			return map[any]Type{decl: nonNull(t)}
+		}
+	}
+	return nil
+}
+
+// branch checks the body of a when or if branch and returns its value,
+// which is used when used is true.
+func (c *Checker) branch(body ast.Stmt, used bool) Type {
+	switch b := body.(type) {
+	case *ast.ExprStmt:
+		return c.value(b.Expr, used)
+	case *ast.BlockStmt:
+		return c.block(b.Statements, used)
+	}
+	c.checkStmt(body)
+	return Unit
+}
+
+// block checks the statements of a block and returns its value, that of
+// its last statement when it is an expression, which is used when used is
+// true.
+func (c *Checker) block(stmts []ast.Stmt, used bool) Type {
+	if len(stmts) == 0 {
+		return Unit
+	}
+	c.checkStmts(stmts[:len(stmts)-1])
+	if last, ok := stmts[len(stmts)-1].(*ast.ExprStmt); ok {
+		return c.value(last.Expr, used)
+	}
+	c.checkStmt(stmts[len(stmts)-1])
+	return Unit
+}
+
+// value checks an expression and returns its type. When used is false,
+// the value is discarded, so that a when or if need not give one: only a
+// when used as an expression must have an else branch whatever its
+// subject.
+func (c *Checker) value(expr ast.Expr, used bool) Type {
+	if !used {
+		switch expr.(type) {
+		case *ast.WhenExpr, *ast.IfExpr, *ast.TryExpr:
+			c.discarded[expr] = true
+		}
+	}
+	return c.checkExpr(expr, nil)
+}
+
+// tryExpr types a try, whose value is that of the try block or of the
+// catch clause run. Any part of the try block may have run before a catch
+// clause, and any part of both before the finally block.
+func (c *Checker) tryExpr(e *ast.TryExpr) Type {
+	before := c.ctx
+	used := !c.discarded[e]
+	t := c.block(e.Body.Statements, used)
+	body := c.ctx
+	var paths []*context
+	if !isNothing(t) {
+		paths = append(paths, body)
+	}
+	for _, catch := range e.Catches {
+		c.ctx = before.uncast(assigned(e.Body)).join(before, body)
+		caught := c.block(catch.Body.Statements, used)
+		if !isNothing(caught) {
+			paths = append(paths, c.ctx)
+		}
+		t = lub(t, caught)
+	}
+	if e.Finally != nil {
+		c.ctx = before.uncast(assigned(e.Body)).join(append([]*context{before, body}, paths...)...)
+		for _, catch := range e.Catches {
+			c.ctx = c.ctx.uncast(assigned(catch.Body))
+		}
+		c.block(e.Finally.Statements, false)
+		if len(paths) > 0 {
+			paths = []*context{c.ctx}
+		}
+	}
+	c.ctx = before.uncast(assigned(e.Body))
+	for _, catch := range e.Catches {
+		c.ctx = c.ctx.uncast(assigned(catch.Body))
+	}
+	if e.Finally != nil {
+		c.ctx = c.ctx.uncast(assigned(e.Finally))
+	}
+	c.ctx = c.ctx.join(paths...)
+	return t
+}
+
+// returnExpr checks the value returned from the enclosing function.
+func (c *Checker) returnExpr(e *ast.ReturnExpr) {
+	if e.Label.Spelling != "" || c.ctx.result == nil {
+		if e.Value != nil {
+			c.checkExpr(e.Value, nil)
+		}
+		return
+	}
+	if e.Value == nil {
+		c.mismatch(e.Return.Position, Unit, c.ctx.result)
+		return
+	}
+	c.expect(e.Value, c.ctx.result)
+}
@@ -0,0 +1,312 @@
+package types
+
+import (
+	"gotlin/frontend/ast"
+	"gotlin/frontend/token"
+)
+
+// cast returns a copy of ctx where the values declared by the keys of casts
+// have been smart cast to their types.
+func (ctx *context) cast(casts map[any]Type) *context {
+	if len(casts) == 0 {
+		return ctx
+	}
+	nested := ctx.with()
+	nested.casts = make(map[any]Type, len(ctx.casts)+len(casts))
+	for decl, t := range ctx.casts {
+		nested.casts[decl] = t
+	}
+	for decl, t := range casts {
+		nested.casts[decl] = t
+	}
+	return nested
+}
+
+// uncast returns a copy of ctx without the smart casts of the values
+// declared by decls, whose value has changed.
+func (ctx *context) uncast(decls map[any]bool) *context {
+	nested := ctx.with()
+	nested.casts = make(map[any]Type, len(ctx.casts))
+	for decl, t := range ctx.casts {
+		if !decls[decl] {
+			nested.casts[decl] = t
+		}
+	}
+	return nested
+}
+
+// closure returns the context of a lambda or local function declared in
+// ctx, which may run after the variables around it have changed and so
+// keeps only the smart casts of values that cannot.
+func (ctx *context) closure() *context {
+	variables := make(map[any]bool)
+	for decl := range ctx.casts {
+		if variable, ok := decl.(*ast.VariableDecl); ok && !variable.ReadOnly {
+			variables[decl] = true
+		}
+	}
+	return ctx.uncast(variables)
+}
+
+// smartCast smart casts the value id names to t in the rest of the code
+// being checked.
+func (c *Checker) smartCast(id ast.Expr, t Type) {
+	if decl := declOf(id); decl != nil && !isUnknown(t) {
+		c.ctx = c.ctx.cast(map[any]Type{decl: t})
+	}
+}
+
+// declOf returns the declaration of the value a plain name refers to, nil
+// for any other expression.
+func declOf(expr ast.Expr) any {
+	for {
+		grouping, ok := expr.(*ast.GroupingExpr)
+		if !ok {
+			break
+		}
+		expr = grouping.Expr
+	}
+	id, ok := expr.(*ast.IdentifierExpr)
+	if !ok || id.Binding == nil {
+		return nil
+	}
+	return id.Binding.Symbol.Decl
+}
+
+// casts returns the smart casts that hold where cond evaluated to truth:
+// `x != null` makes x non-null and `x is T` makes it a T.
+func (c *Checker) casts(cond ast.Expr, truth bool) map[any]Type {
+	switch e := cond.(type) {
+	case *ast.GroupingExpr:
+		return c.casts(e.Expr, truth)
+	case *ast.UnaryExpr:
+		if e.Op.Kind == token.NOT {
+			return c.casts(e.Right, !truth)
+		}
+	case *ast.IsExpr:
+		if decl := declOf(e.Expr); decl != nil && (e.Op.Kind == token.IS) == truth {
+			return map[any]Type{decl: castTo(c.types[e.Expr], c.typeOf(e.Type))}
+		}
+	case *ast.BinaryExpr:
+		switch e.Op.Kind {
+		case token.EQ_EQ, token.NOT_EQ:
+			if (e.Op.Kind == token.NOT_EQ) != truth {
+				return nil
+			}
+			value := e.Left
+			if _, ok := e.Left.(*ast.NullLiteral); ok {
+				value = e.Right
+			} else if _, ok := e.Right.(*ast.NullLiteral); !ok {
+				return nil
+			}
+			if decl := declOf(value); decl != nil {
+				return map[any]Type{decl: nonNull(c.types[value])}
+			}
+		case token.AND, token.OR:
+			// Both operands hold after a true `&&` or a false `||`.
+			if (e.Op.Kind == token.AND) != truth {
+				return nil
+			}
+			casts := c.casts(e.Left, truth)
+			for decl, t := range c.casts(e.Right, truth) {
+				if casts == nil {
+					casts = make(map[any]Type)
+				}
+				casts[decl] = t
+			}
+			return casts
+		}
+	}
+	return nil
+}
+
+// castTo returns the type a value of type t has once known to be a target.
+func castTo(t Type, target Type) Type {
+	if !isUnknown(t) && Assignable(t, target) {
+		return t
+	}
+	return target
+}
+
+// assigned returns the declarations of the variables the statements
+// assign to.
+func assigned(stmts ...ast.Stmt) map[any]bool {
+	decls := make(map[any]bool)
+	for _, stmt := range stmts {
+		if stmt == nil {
+			continue
+		}
+		ast.Inspect(stmt, func(node any) bool {
+			if assign, ok := node.(*ast.AssignStmt); ok {
+				if decl := declOf(assign.Assigne); decl != nil {
+					decls[decl] = true
+				}
+			}
+			return true
+		})
+	}
+	return decls
+}
+
+// collectChanging records the local variables that a lambda, anonymous or
+// local function assigns to although it does not declare them, which can
+// change at any time once it has been created.
+func (c *Checker) collectChanging(stmts []ast.Stmt) {
+	for _, stmt := range stmts {
+		ast.Inspect(stmt, func(node any) bool {
+			if assign, ok := node.(*ast.AssignStmt); ok {
+				if id, ok := assign.Assigne.(*ast.IdentifierExpr); ok && id.Binding != nil && id.Binding.Depth > 0 {
+					c.changing[id.Binding.Symbol.Decl] = true
+				}
+			}
+			return true
+		})
+	}
+}
+
+// unstable returns why the value a name refers to cannot be smart cast,
+// or "" if it can: its value may change between the check and the use.
+func (c *Checker) unstable(id *ast.IdentifierExpr) string {
+	switch decl := id.Binding.Symbol.Decl.(type) {
+	case *ast.VariableDecl:
+		local := id.Binding.Symbol.Storage == ast.StorageLocal
+		switch {
+		case !decl.ReadOnly && local && c.changing[decl]:
+			return "a local variable that is captured by a changing closure"
+		case !decl.ReadOnly && !local:
+			return "a mutable property that could have been changed by this time"
+		case decl.Delegate != nil:
+			return "a property that has a delegate"
+		case decl.Getter != nil && !local:
+			return "a property that has open or custom getter"
+		}
+	case *ast.ClassParam:
+		if decl.Property && !decl.ReadOnly {
+			return "a mutable property that could have been changed by this time"
+		}
+	}
+	return ""
+}
+
+// impossibleCast is a smart cast that does not apply to an unstable value.
+type impossibleCast struct {
+	t      Type
+	reason string
+}
+
+// smartCastImpossible reports that expr, which was checked to have a type
+// that would fit, cannot be smart cast to it. It returns false if expr was
+// not checked.
+func (c *Checker) smartCastImpossible(expr ast.Expr, fits func(Type) bool) bool {
+	id, ok := expr.(*ast.IdentifierExpr)
+	if !ok {
+		return false
+	}
+	impossible, ok := c.impossible[id]
+	if !ok || !fits(impossible.t) {
+		return false
+	}
+	c.report(ast.Position(expr), "Smart cast to '%s' is impossible, because '%s' is %s", impossible.t, id.Value.Spelling, impossible.reason)
+	return true
+}
+
+// nullSafe reports the use of the member called name of a nullable
+// receiver without `?.` or `!!`, unless an extension or scope function
+// that takes nullable receivers is called instead.
+func (c *Checker) nullSafe(receiver ast.Expr, t Type, name string, pos token.Pos) {
+	if !isNullable(t) || isUnknown(t) || nullableMembers[name] {
+		return
+	}
+	if scopeFunction(t, name) != nil || len(c.extensionsOf(t, name)) > 0 {
+		return
+	}
+	if c.smartCastImpossible(receiver, func(t Type) bool { return !isNullable(t) }) {
+		return
+	}
+	c.report(pos, "Only safe (?.) or non-null asserted (!!.) calls are allowed on a nullable receiver of type %s", t)
+}
+
+// assignment is what the code run so far may have done to a local variable
+// declared without a value: left it unassigned, assigned it, or either,
+// depending on the path taken.
+type assignment int
+
+const (
+	maybeUnassigned assignment = 1 << iota
+	maybeAssigned
+)
+
+// assign returns a copy of ctx where the variable declared by decl is in
+// state.
+func (ctx *context) assign(decl any, state assignment) *context {
+	nested := ctx.with()
+	nested.assignments = make(map[any]assignment, len(ctx.assignments)+1)
+	for variable, s := range ctx.assignments {
+		nested.assignments[variable] = s
+	}
+	nested.assignments[decl] = state
+	return nested
+}
+
+// join returns a copy of ctx where each variable is in any of the states
+// it is in at the end of paths, the contexts of the code that leads to ctx.
+// Without paths, ctx cannot be reached and is returned as is.
+func (ctx *context) join(paths ...*context) *context {
+	if len(paths) == 0 {
+		return ctx
+	}
+	nested := ctx.with()
+	nested.assignments = make(map[any]assignment, len(ctx.assignments))
+	for _, path := range paths {
+		for variable, s := range path.assignments {
+			nested.assignments[variable] |= s
+		}
+	}
+	return nested
+}
+
+// reassigned returns a copy of ctx where the variables decls declares may
+// also have been assigned, as by a previous iteration of a loop.
+func (ctx *context) reassigned(decls map[any]bool) *context {
+	nested := ctx
+	for decl := range decls {
+		if s, ok := ctx.assignments[decl]; ok {
+			nested = nested.assign(decl, s|maybeAssigned)
+		}
+	}
+	return nested
+}
+
+// deferred reports whether decl declares a local val without a value,
+// which must be assigned exactly once.
+func deferred(decl any) bool {
+	variable, ok := decl.(*ast.VariableDecl)
+	return ok && variable.ReadOnly && variable.Value == nil && variable.Delegate == nil
+}
+
+// initialized reports the read of a local variable that may not have been
+// assigned yet.
+func (c *Checker) initialized(id *ast.IdentifierExpr) {
+	if c.ctx.assignments[id.Binding.Symbol.Decl]&maybeUnassigned != 0 {
+		c.report(id.Value.Position, "Variable '%s' must be initialized", id.Value.Spelling)
+	}
+}
+
+// assignOnce records the assignment of a local variable declared without a
+// value, and reports a val that may already have been assigned, or that a
+// closure assigns and so may run any number of times.
+func (c *Checker) assignOnce(id *ast.IdentifierExpr) {
+	decl := id.Binding.Symbol.Decl
+	s, ok := c.ctx.assignments[decl]
+	if !ok {
+		return
+	}
+	switch {
+	case !deferred(decl):
+	case id.Binding.Depth > 0:
+		c.report(id.Value.Position, "Captured values initialization is forbidden due to possible reassignment")
+	case s&maybeAssigned != 0:
+		c.report(id.Value.Position, "Val cannot be reassigned")
+	}
+	c.ctx = c.ctx.assign(decl, maybeAssigned)
+}
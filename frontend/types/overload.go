@@ -0,0 +1,274 @@
+package types
+
+import (
+	"strings"
+
+	"gotlin/frontend/ast"
+	"gotlin/frontend/token"
+)
+
+// overload is a candidate a call can invoke, with the parameter each
+// argument is passed to and the types its parameters take in the call.
+type overload struct {
+	candidate
+	mapping []int
+	params  []Type
+}
+
+// resolveOverload picks the function a call with args invokes among several
+// candidates, as Kotlin does: the most specific of those the arguments
+// fit, whose parameters each take no value the others do not. It reports
+// an error and returns false when none or several remain.
+func (c *Checker) resolveOverload(candidates []candidate, args []ast.Expr, names []token.Token, pos token.Pos) (candidate, bool) {
+	var mapped []candidate
+	for _, cand := range candidates {
+		if _, ok := mapArgs(cand.sig, args, names); ok {
+			mapped = append(mapped, cand)
+		}
+	}
+	if len(mapped) == 1 {
+		return mapped[0], true
+	}
+
+	types := c.argTypes(args)
+	var applicable []*overload
+	for _, cand := range mapped {
+		if o := newOverload(cand, args, names, types); o.fits(args, types) {
+			applicable = append(applicable, o)
+		}
+	}
+	if len(applicable) == 1 {
+		return applicable[0].candidate, true
+	}
+	if len(applicable) == 0 {
+		c.report(pos, "None of the following functions can be called with the arguments supplied: %s", listCandidates(candidates))
+		return candidate{}, false
+	}
+
+	best := mostSpecific(applicable, args)
+	if len(best) == 1 {
+		return best[0].candidate, true
+	}
+	if len(best) == 0 {
+		best = applicable
+	}
+	ambiguous := make([]candidate, len(best))
+	for i, o := range best {
+		ambiguous[i] = o.candidate
+	}
+	c.report(pos, "Overload resolution ambiguity: %s", listCandidates(ambiguous))
+	return candidate{}, false
+}
+
+// argTypes types the arguments of a call before the function it invokes is
+// chosen, except for those whose type depends on the parameter they are
+// passed to: lambdas and integer literals, left nil.
+func (c *Checker) argTypes(args []ast.Expr) []Type {
+	types := make([]Type, len(args))
+	for i, arg := range args {
+		if _, ok := integerLiteral(arg); !ok && !isLambda(arg) {
+			types[i] = c.checkExpr(arg, nil)
+		}
+	}
+	return types
+}
+
+// integerLiteral returns the value of an integer literal, negated or not.
+func integerLiteral(expr ast.Expr) (int64, bool) {
+	switch e := expr.(type) {
+	case *ast.IntLiteral:
+		return e.Value, true
+	case *ast.UnaryExpr:
+		if literal, ok := e.Right.(*ast.IntLiteral); ok && e.Op.Kind == token.DASH {
+			return -literal.Value, true
+		}
+	}
+	return 0, false
+}
+
+// newOverload maps args to the parameters of cand, whose type parameters
+// are inferred from the arguments already typed.
+func newOverload(cand candidate, args []ast.Expr, names []token.Token, types []Type) *overload {
+	mapping, _ := mapArgs(cand.sig, args, names)
+	typeParams := make(map[string]bool, len(cand.sig.TypeParams))
+	for _, name := range cand.sig.TypeParams {
+		typeParams[name] = true
+	}
+	declared := make([]Type, len(cand.sig.Params))
+	for i, p := range cand.sig.Params {
+		declared[i] = substitute(p.Type, cand.bindings)
+	}
+	inferred := make(map[string]Type)
+	for i, t := range types {
+		if t != nil {
+			unify(declared[mapping[i]], t, inferred, typeParams)
+		}
+	}
+	for name := range typeParams {
+		if _, ok := inferred[name]; !ok {
+			inferred[name] = Unknown
+		}
+	}
+	params := make([]Type, len(declared))
+	for i, t := range declared {
+		params[i] = substitute(t, inferred)
+	}
+	return &overload{candidate: cand, mapping: mapping, params: params}
+}
+
+// fits reports whether each argument can be passed to its parameter.
+func (o *overload) fits(args []ast.Expr, types []Type) bool {
+	for i, arg := range args {
+		param := o.params[o.mapping[i]]
+		if containsTypeParam(param) {
+			continue
+		}
+		if value, ok := integerLiteral(arg); ok {
+			if t, ok := integerType(value, param); !ok || !Assignable(t, param) {
+				return false
+			}
+		} else if isLambda(arg) {
+			if !takesLambda(param, arg) {
+				return false
+			}
+		} else if !Assignable(types[i], param) {
+			return false
+		}
+	}
+	return true
+}
+
+// takesLambda reports whether a lambda can be passed where a value of type
+// param is expected. A lambda without parameters may take one, `it`.
+func takesLambda(param Type, lambda ast.Expr) bool {
+	switch p := nonNull(param).(type) {
+	case *Function:
+		switch l := lambda.(type) {
+		case *ast.LambdaExpr:
+			return len(l.Parameters) == len(p.Params) || len(l.Parameters) == 0 && len(p.Params) == 1
+		case *ast.FunctionLiteral:
+			return len(l.Parameters) == len(p.Params)
+		}
+	case *Named:
+		return p.Class == anyClass
+	}
+	return isUnknown(param)
+}
+
+// mostSpecific returns the overloads at least as specific as all others.
+// Of several equally specific, Kotlin prefers those that are not generic,
+// then those without a vararg parameter, then those leaving out the fewest
+// parameters with a default.
+func mostSpecific(overloads []*overload, args []ast.Expr) []*overload {
+	var best []*overload
+	for _, o := range overloads {
+		specific := true
+		for _, other := range overloads {
+			specific = specific && (o == other || o.asSpecific(other, args))
+		}
+		if specific {
+			best = append(best, o)
+		}
+	}
+	best = prefer(best, func(o *overload) bool { return len(o.sig.TypeParams) == 0 })
+	best = prefer(best, func(o *overload) bool { return !hasVararg(o.sig) })
+	fewest := -1
+	for _, o := range best {
+		if n := o.defaulted(); fewest < 0 || n < fewest {
+			fewest = n
+		}
+	}
+	return prefer(best, func(o *overload) bool { return o.defaulted() == fewest })
+}
+
+// asSpecific reports whether each parameter o passes an argument to takes
+// no value the one other passes it to does not. An integer literal is an
+// Int before anything else.
+func (o *overload) asSpecific(other *overload, args []ast.Expr) bool {
+	for i, arg := range args {
+		param := o.params[o.mapping[i]]
+		if _, ok := integerLiteral(arg); ok && isClass(param, intClass) {
+			continue
+		}
+		if !Assignable(param, other.params[other.mapping[i]]) {
+			return false
+		}
+	}
+	return true
+}
+
+// defaulted counts the parameters with a default the call leaves out.
+func (o *overload) defaulted() int {
+	provided := make(map[int]bool, len(o.mapping))
+	for _, p := range o.mapping {
+		provided[p] = true
+	}
+	n := 0
+	for i, p := range o.sig.Params {
+		if p.Default && !provided[i] {
+			n++
+		}
+	}
+	return n
+}
+
+// prefer returns the overloads that satisfy preferred, or all of them if
+// none does.
+func prefer(overloads []*overload, preferred func(*overload) bool) []*overload {
+	var kept []*overload
+	for _, o := range overloads {
+		if preferred(o) {
+			kept = append(kept, o)
+		}
+	}
+	if len(kept) == 0 {
+		return overloads
+	}
+	return kept
+}
+
+func hasVararg(sig *Signature) bool {
+	for _, p := range sig.Params {
+		if p.Vararg {
+			return true
+		}
+	}
+	return false
+}
+
+// listCandidates spells the signatures of candidates in a diagnostic.
+func listCandidates(candidates []candidate) string {
+	sigs := make([]string, len(candidates))
+	for i, cand := range candidates {
+		sigs[i] = cand.sig.String()
+	}
+	return strings.Join(sigs, "; ")
+}
+
+// conflictingOverloads reports a function declared with the parameters of
+// an overload declared before it.
+func (c *Checker) conflictingOverloads(decl *ast.FunctionDecl) {
+	sig := c.signatureOf(decl)
+	for _, other := range c.overloads(decl) {
+		if other.sig == sig {
+			return
+		}
+		if sameParams(sig, other.sig) {
+			c.report(decl.Name.Position, "Conflicting overloads: %s; %s", other.sig, sig)
+			return
+		}
+	}
+}
+
+func sameParams(a *Signature, b *Signature) bool {
+	if len(a.Params) != len(b.Params) {
+		return false
+	}
+	for i, p := range a.Params {
+		q := b.Params[i]
+		if isUnknown(p.Type) || p.Vararg != q.Vararg || p.Type.String() != q.Type.String() {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,244 @@
+package types
+
+// isUnknown reports whether the checker cannot tell what t holds, so that
+// any use of it is allowed.
+func isUnknown(t Type) bool {
+	switch tp := t.(type) {
+	case unknown, *classifier, *TypeParam, nil:
+		return true
+	case *Nullable:
+		return isUnknown(tp.Type)
+	}
+	return false
+}
+
+func isNothing(t Type) bool {
+	named, ok := t.(*Named)
+	return ok && named.Class == nothingClass
+}
+
+func isClass(t Type, class *Class) bool {
+	named, ok := t.(*Named)
+	return ok && named.Class == class
+}
+
+func nonNull(t Type) Type {
+	if nullable, ok := t.(*Nullable); ok {
+		return nullable.Type
+	}
+	return t
+}
+
+func nullable(t Type) Type {
+	switch t.(type) {
+	case *Nullable, unknown:
+		return t
+	}
+	return &Nullable{Type: t}
+}
+
+func isNullable(t Type) bool {
+	_, ok := t.(*Nullable)
+	return ok
+}
+
+// Assignable reports whether a value of type from can be used where a value
+// of type to is expected.
+func Assignable(from Type, to Type) bool {
+	if isUnknown(from) || isUnknown(to) {
+		return true
+	}
+	if target, ok := to.(*Nullable); ok {
+		return Assignable(nonNull(from), target.Type)
+	}
+	if isNullable(from) {
+		return false
+	}
+	if isNothing(from) {
+		return true
+	}
+
+	switch target := to.(type) {
+	case *Function:
+		source, ok := from.(*Function)
+		if !ok || len(source.Params) != len(target.Params) {
+			return false
+		}
+		for i, param := range target.Params {
+			if !Assignable(param, source.Params[i]) {
+				return false
+			}
+		}
+		return Assignable(source.Result, target.Result)
+	case *Named:
+		if target.Class == anyClass {
+			return true
+		}
+		source, ok := from.(*Named)
+		if !ok {
+			return false
+		}
+		super := supertype(source, target.Class)
+		if super == nil {
+			return source.Class.Open
+		}
+		return argsAssignable(super.Args, target.Args, target.Class.Covariant)
+	}
+	return false
+}
+
+func argsAssignable(from []Type, to []Type, covariant bool) bool {
+	if len(from) != len(to) {
+		return true
+	}
+	for i := range from {
+		if !Assignable(from[i], to[i]) || !covariant && !Assignable(to[i], from[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// supertype returns t seen as an instance of class, with the type arguments
+// class gets through the supertypes of t, or nil when t is not one.
+func supertype(t *Named, class *Class) *Named {
+	return findSupertype(t, class, make(map[*Class]bool))
+}
+
+func findSupertype(t *Named, class *Class, seen map[*Class]bool) *Named {
+	if t.Class == class {
+		return t
+	}
+	if seen[t.Class] {
+		return nil
+	}
+	seen[t.Class] = true
+	bindings := bindingsOf(t)
+	for _, super := range t.Class.Supers {
+		if named, ok := substitute(super, bindings).(*Named); ok {
+			if found := findSupertype(named, class, seen); found != nil {
+				return found
+			}
+		}
+	}
+	return nil
+}
+
+// bindingsOf maps the type parameters of the class of t to its arguments.
+func bindingsOf(t *Named) map[string]Type {
+	if len(t.Class.TypeParams) == 0 {
+		return nil
+	}
+	bindings := make(map[string]Type, len(t.Class.TypeParams))
+	for i, name := range t.Class.TypeParams {
+		if i < len(t.Args) {
+			bindings[name] = t.Args[i]
+		} else {
+			bindings[name] = Unknown
+		}
+	}
+	return bindings
+}
+
+// substitute replaces the type parameters t refers to with their bindings.
+func substitute(t Type, bindings map[string]Type) Type {
+	if len(bindings) == 0 {
+		return t
+	}
+	switch tp := t.(type) {
+	case *TypeParam:
+		if bound, ok := bindings[tp.Name]; ok {
+			return bound
+		}
+	case *Nullable:
+		return nullable(substitute(tp.Type, bindings))
+	case *Named:
+		if len(tp.Args) == 0 {
+			return tp
+		}
+		args := make([]Type, len(tp.Args))
+		for i, arg := range tp.Args {
+			args[i] = substitute(arg, bindings)
+		}
+		return &Named{Class: tp.Class, Args: args}
+	case *Function:
+		params := make([]Type, len(tp.Params))
+		for i, param := range tp.Params {
+			params[i] = substitute(param, bindings)
+		}
+		return &Function{Params: params, Result: substitute(tp.Result, bindings)}
+	}
+	return t
+}
+
+// lub returns the most specific type both a and b are assignable to, which
+// is the type of an expression whose value comes from either.
+func lub(a Type, b Type) Type {
+	switch {
+	case a == nil:
+		return b
+	case b == nil, isNothing(b):
+		return a
+	case isNothing(a):
+		return b
+	case isUnknown(a) || isUnknown(b):
+		return Unknown
+	case isNullable(a) || isNullable(b):
+		return nullable(lub(nonNull(a), nonNull(b)))
+	case Assignable(a, b):
+		return b
+	case Assignable(b, a):
+		return a
+	}
+	if named, ok := a.(*Named); ok {
+		queue := []*Named{named}
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+			if Assignable(b, current) {
+				return current
+			}
+			bindings := bindingsOf(current)
+			for _, super := range current.Class.Supers {
+				if s, ok := substitute(super, bindings).(*Named); ok {
+					queue = append(queue, s)
+				}
+			}
+		}
+	}
+	return Any
+}
+
+// numericRanks orders the number types by width. Arithmetic on two
+// numbers yields the wider, and never less than Int.
+var numericRanks = map[*Class]int{
+	byteClass:   1,
+	shortClass:  2,
+	intClass:    3,
+	longClass:   4,
+	floatClass:  5,
+	doubleClass: 6,
+}
+
+func isNumeric(t Type) bool {
+	named, ok := t.(*Named)
+	return ok && numericRanks[named.Class] > 0
+}
+
+// widen returns the type of arithmetic on numbers of types a and b.
+func widen(a Type, b Type) Type {
+	wider := Int
+	for _, t := range []Type{a, b} {
+		if named := t.(*Named); numericRanks[named.Class] > numericRanks[wider.(*Named).Class] {
+			wider = t
+		}
+	}
+	return wider
+}
+
+// final reports whether t is a built-in type no other type extends, so
+// that values of distinct final types can never be equal.
+func final(t Type) bool {
+	named, ok := nonNull(t).(*Named)
+	return ok && (numericRanks[named.Class] > 0 || named.Class == charClass || named.Class == booleanClass || named.Class == stringClass)
+}
@@ -0,0 +1,423 @@
+package types
+
+import (
+	"gotlin/frontend/ast"
+	"gotlin/frontend/cfg"
+	"gotlin/frontend/token"
+)
+
+func (c *Checker) checkStmts(stmts []ast.Stmt) {
+	for _, stmt := range stmts {
+		c.checkStmt(stmt)
+	}
+}
+
+func (c *Checker) checkStmt(stmt ast.Stmt) {
+	switch s := stmt.(type) {
+	case *ast.ExprStmt:
+		c.value(s.Expr, false)
+	case *ast.BlockStmt:
+		c.checkStmts(s.Statements)
+	case *ast.VariableDecl:
+		c.checkVariable(s)
+	case *ast.DestructuringDecl:
+		t := c.checkExpr(s.Value, nil)
+		if isNullable(t) && !isUnknown(t) {
+			c.report(ast.Position(s.Value), "Not nullable value required to call '%s()' function of destructuring declaration initializer", ast.ComponentFunction(0))
+		}
+		c.destructure(s.Pattern, t)
+	case *ast.FunctionDecl:
+		c.checkFunction(s)
+	case *ast.ClassDeclStmt:
+		c.checkClass(s)
+	case *ast.ObjectDeclStmt:
+		c.checkObject(s)
+	case *ast.ForStmt:
+		element := c.element(s.Iterable)
+		if s.Pattern != nil {
+			c.destructure(s.Pattern, element)
+		} else {
+			c.decls[s] = element
+		}
+		c.loop(s.Body, nil)
+	case *ast.WhileStmt:
+		c.loop(s.Body, s.Condition)
+	case *ast.AssignStmt:
+		c.checkAssignment(s)
+	}
+}
+
+// checkVariable checks a variable or property against its declared type,
+// or gives it the type of its initializer.
+func (c *Checker) checkVariable(decl *ast.VariableDecl) {
+	// The smart casts of the initializer of a local variable hold after it,
+	// and one declared without a value must be assigned before it is read.
+	if ctx, ok := c.contexts[decl]; ok {
+		defer c.enter(ctx)()
+	} else {
+		c.contexts[decl] = c.ctx
+		if decl.Value == nil && decl.Delegate == nil {
+			defer func() { c.ctx = c.ctx.assign(decl, maybeUnassigned) }()
+		}
+	}
+
+	var declared Type
+	if decl.Type != nil {
+		declared = c.typeOf(decl.Type)
+		c.decls[decl] = declared
+	}
+	if decl.Value != nil {
+		t := c.checkExpr(decl.Value, declared)
+		if declared != nil {
+			c.conform(decl.Value, t, declared)
+		}
+	}
+	if decl.Delegate != nil {
+		delegate := c.checkExpr(decl.Delegate, nil)
+		c.checkDelegate(decl, delegate)
+		if declared != nil {
+			c.mismatch(ast.Position(decl.Delegate), c.delegateType(delegate), declared)
+		}
+	}
+	t := c.declType(decl)
+
+	ctx := c.ctx
+	if decl.Receiver != nil {
+		ctx = ctx.receiver(decl.Name.Spelling, c.typeOf(decl.Receiver))
+	}
+	if getter := decl.Getter; getter != nil && getter.Body != nil {
+		body := ctx.with()
+		body.result = t
+		restore := c.enter(body)
+		if getter.Body.Expr != nil && decl.Type != nil {
+			c.expect(getter.Body.Expr, t)
+		} else if getter.Body.Expr != nil {
+			c.checkExpr(getter.Body.Expr, nil)
+		} else {
+			c.checkStmts(getter.Body.Block)
+			c.controlFlow(getter.Body.Block, t, getter.Keyword.Position)
+		}
+		restore()
+	}
+	if setter := decl.Setter; setter != nil && setter.Body != nil {
+		if setter.Parameter != nil {
+			if setter.Parameter.Type != nil {
+				c.decls[setter.Parameter] = c.typeOf(setter.Parameter.Type)
+			} else {
+				c.decls[setter.Parameter] = t
+			}
+		}
+		body := ctx.with()
+		body.result = Unit
+		restore := c.enter(body)
+		if setter.Body.Expr != nil {
+			c.checkExpr(setter.Body.Expr, nil)
+		} else {
+			c.checkStmts(setter.Body.Block)
+			c.controlFlow(setter.Body.Block, Unit, setter.Keyword.Position)
+		}
+		restore()
+	}
+}
+
+// destructure gives the entries of a pattern the types of the components
+// of a value of type t, and checks that the value has a componentN()
+// function for each used entry and that its result is the declared type.
+func (c *Checker) destructure(pattern *ast.DestructuringPattern, t Type) {
+	for i, entry := range pattern.Entries {
+		if entry.Name.Spelling == ast.Underscore {
+			continue
+		}
+		component := Unknown
+		if candidates := c.methods(nonNull(t), ast.ComponentFunction(i)); len(candidates) > 0 {
+			cand := candidates[0]
+			component = known(substitute(cand.sig.Result, cand.bindings))
+		} else if knowsComponents(t) {
+			c.report(entry.Name.Position, "Destructuring declaration initializer of type %s must have a '%s()' function", t, ast.ComponentFunction(i))
+		}
+		if entry.Type == nil {
+			c.decls[entry] = component
+			continue
+		}
+		declared := c.typeOf(entry.Type)
+		if !isUnknown(component) && !isUnknown(declared) {
+			c.mismatch(entry.Name.Position, component, declared)
+		}
+		c.decls[entry] = declared
+	}
+}
+
+// knowsComponents reports whether the checker knows every componentN()
+// function of a value of type t. Built-in classes declare theirs in full.
+func knowsComponents(t Type) bool {
+	if named, ok := nonNull(t).(*Named); ok && named.Class.decl == nil {
+		return true
+	}
+	return knowsMembers(t)
+}
+
+// loop checks the body of a loop and the condition it repeats while. The
+// variables the loop assigns lose their smart casts in the loop, which may
+// have assigned them in a previous iteration, and after it, where they may
+// or may not have been assigned. A while loop smart casts in its body what
+// its condition makes true.
+func (c *Checker) loop(body ast.Stmt, condition ast.Expr) {
+	changed := assigned(body)
+	c.ctx = c.ctx.uncast(changed).reassigned(changed)
+	restore := c.enter(c.ctx)
+	switch {
+	case cfg.IsTrue(condition):
+		// `while (true)` is how a loop that only ends with a break is
+		// written, which is no reason for a warning.
+		c.checkExpr(condition, Boolean)
+	case condition != nil:
+		c.condition(condition)
+		c.ctx = c.ctx.cast(c.casts(condition, true))
+	}
+	c.checkStmt(body)
+	restore()
+}
+
+// element returns the type of the elements a for loop iterates over.
+func (c *Checker) element(iterable ast.Expr) Type {
+	t := c.checkExpr(iterable, nil)
+	if isNullable(t) && !isUnknown(t) {
+		c.report(ast.Position(iterable), "Not nullable value required to call an 'iterator()' method on for-loop range")
+		t = nonNull(t)
+	}
+	named, ok := t.(*Named)
+	if !ok {
+		if !isUnknown(t) {
+			c.report(ast.Position(iterable), "For-loop range must have an 'iterator()' method")
+		}
+		return Unknown
+	}
+	switch {
+	case named.Class == stringClass:
+		return Char
+	case named.Class == mapClass || supertype(named, mapClass) != nil:
+		m := supertype(named, mapClass)
+		return &Named{Class: entryClass, Args: m.Args}
+	}
+	if it := supertype(named, iterableClass); it != nil {
+		return it.Args[0]
+	}
+	if iterator := c.methods(named, "iterator"); len(iterator) > 0 {
+		next := c.methods(known(substitute(iterator[0].sig.Result, iterator[0].bindings)), "next")
+		if len(next) > 0 {
+			return known(substitute(next[0].sig.Result, next[0].bindings))
+		}
+		return Unknown
+	}
+	if !named.Class.Open {
+		c.report(ast.Position(iterable), "For-loop range must have an 'iterator()' method")
+	}
+	return Unknown
+}
+
+// checkAssignment checks the value assigned against the type of the
+// variable or property assigned.
+func (c *Checker) checkAssignment(s *ast.AssignStmt) {
+	target := Unknown
+	switch assignee := s.Assigne.(type) {
+	case *ast.IdentifierExpr:
+		if assignee.Binding != nil && assignee.Binding.Symbol.Decl != nil {
+			target = c.declType(assignee.Binding.Symbol.Decl)
+		}
+		c.checkImplicitAccess(assignee, true)
+		c.types[assignee] = target
+	case *ast.MemberExpr:
+		receiver := c.checkExpr(assignee.Receiver, nil)
+		if assignee.Safe {
+			receiver = nonNull(receiver)
+		} else {
+			c.nullSafe(assignee.Receiver, receiver, assignee.Name.Spelling, assignee.Name.Position)
+		}
+		var ok bool
+		if target, ok = c.property(receiver, assignee.Name.Spelling); !ok {
+			c.report(assignee.Name.Position, "Unresolved reference: %s", assignee.Name.Spelling)
+		}
+		c.checkAccess(receiver, assignee.Name, true)
+		c.types[assignee] = target
+	default:
+		c.checkExpr(s.Assigne, nil)
+	}
+	t := c.expect(s.Value, known(target))
+	if id, ok := s.Assigne.(*ast.IdentifierExpr); ok && id.Binding != nil {
+		c.assignOnce(id)
+	}
+	if decl := declOf(s.Assigne); decl != nil {
+		// The variable now holds a value of the type assigned.
+		c.ctx = c.ctx.uncast(map[any]bool{decl: true})
+		if Assignable(t, target) && !isNothing(nonNull(t)) {
+			c.smartCast(s.Assigne, t)
+		}
+	}
+}
+
+// checkDelegate checks that the delegate of a property, of type t, has the
+// operator functions its reads and, for a var, its writes are forwarded
+// to.
+func (c *Checker) checkDelegate(decl *ast.VariableDecl, t Type) {
+	operators := []string{delegateGetter}
+	if !decl.ReadOnly {
+		operators = append(operators, delegateSetter)
+	}
+	for _, operator := range operators {
+		if !c.delegates(t, operator) {
+			c.report(decl.Name.Position, "Property delegate must have a '%s' method", operator)
+		}
+	}
+}
+
+// delegates reports whether a value of type t may provide the delegate
+// operator called name: as a member or extension function, or as the
+// built-in Lazy and maps do. Other built-in types provide none, and a class
+// with an unknown supertype may provide any.
+func (c *Checker) delegates(t Type, name string) bool {
+	if isUnknown(t) || len(c.methods(t, name)) > 0 {
+		return true
+	}
+	named, ok := nonNull(t).(*Named)
+	if !ok {
+		return false
+	}
+	switch named.Class {
+	case lazyClass:
+		return name == delegateGetter
+	case mapClass:
+		return name == delegateGetter
+	case mutableMapClass:
+		return true
+	}
+	return named.Class.Open
+}
+
+// delegateType returns the type of the values a delegate of type t gives
+// its property, the result of its getValue operator. A map gives whatever
+// type the property has.
+func (c *Checker) delegateType(t Type) Type {
+	if named, ok := nonNull(t).(*Named); ok {
+		switch named.Class {
+		case lazyClass:
+			return named.Args[0]
+		case mapClass, mutableMapClass:
+			return Unknown
+		}
+	}
+	for _, cand := range c.methods(t, delegateGetter) {
+		if len(cand.sig.Params) == 2 {
+			return known(substitute(cand.sig.Result, cand.bindings))
+		}
+	}
+	return Unknown
+}
+
+// checkFunction checks the body of a function against its result type.
+func (c *Checker) checkFunction(decl *ast.FunctionDecl) {
+	if _, ok := c.contexts[decl]; !ok {
+		c.contexts[decl] = c.ctx.closure()
+	}
+	sig := c.signatureOf(decl)
+	c.conflictingOverloads(decl)
+	defer c.enter(c.contexts[decl])()
+	defer c.enter(c.functionContext(decl, sig.Result))()
+	c.checkParameters(decl.Parameters, sig)
+	if decl.Body == nil {
+		return
+	}
+	if decl.Body.Expr != nil {
+		t := c.checkExpr(decl.Body.Expr, sig.Result)
+		if decl.Type != nil {
+			c.conform(decl.Body.Expr, t, sig.Result)
+		}
+		return
+	}
+	c.checkStmts(decl.Body.Block)
+	c.controlFlow(decl.Body.Block, sig.Result, decl.Name.Position)
+}
+
+// checkParameters checks the default values of the parameters of a
+// function, and that at most one of them is a vararg.
+func (c *Checker) checkParameters(params []*ast.ParameterWithOptionalType, sig *Signature) {
+	varargs := 0
+	for i, p := range params {
+		if p.DefaultValue != nil {
+			c.expect(p.DefaultValue, sig.Params[i].Type)
+		}
+		if p.Vararg {
+			varargs++
+			if varargs == 2 {
+				c.report(p.Position, "Multiple vararg-parameters are prohibited")
+			}
+		}
+	}
+}
+
+// checkClass checks the constructor, supertypes and members of a class.
+func (c *Checker) checkClass(decl *ast.ClassDeclStmt) {
+	class := c.classes[decl]
+	outer := c.ctx
+	if ctx, ok := c.contexts[decl]; ok {
+		outer = ctx
+	}
+	restore := c.enter(outer)
+	ctx := c.bodies[class]
+	if decl.PrimaryConstructor != nil {
+		for i := range decl.PrimaryConstructor.Parameters {
+			p := &decl.PrimaryConstructor.Parameters[i]
+			if p.DefaultValue != nil {
+				c.enter(ctx)
+				c.expect(p.DefaultValue, c.decls[p])
+				c.enter(outer)
+			}
+		}
+	}
+	c.enter(ctx)
+	c.checkSuperTypes(decl.SuperTypes, decl.Name.Position)
+	for _, entry := range decl.Entries {
+		if class.Constructor != nil && (len(entry.Args) > 0 || class.Constructor.Params != nil) {
+			c.checkCall(candidate{sig: class.Constructor}, entry.Args, entry.Names, nil, entry.Name.Position)
+		}
+		c.checkStmts(entry.Members)
+	}
+	c.checkStmts(decl.Members)
+	restore()
+}
+
+// checkObject checks the supertypes and members of an object declaration.
+func (c *Checker) checkObject(decl *ast.ObjectDeclStmt) {
+	class := c.classes[decl]
+	if ctx, ok := c.contexts[decl]; ok {
+		defer c.enter(ctx)()
+	}
+	defer c.enter(c.bodies[class])()
+	c.checkSuperTypes(decl.SuperTypes, decl.Object.Position)
+	c.checkStmts(decl.Members)
+}
+
+// checkSuperTypes checks the supertypes of a class or object declared at
+// pos, and the arguments it passes to the constructor of its superclass.
+func (c *Checker) checkSuperTypes(superTypes []*ast.SuperType, pos token.Pos) {
+	for _, superType := range superTypes {
+		super, ok := c.typeOf(superType.Type).(*Named)
+		if ok {
+			c.superCall(super, superType, pos)
+		} else {
+			c.checkArgs(superType.Args)
+		}
+		c.superDelegate(super, superType.Delegate)
+	}
+}
+
+// superDelegate checks the delegate, if any, a class implements its
+// supertype super by, which must be a value of that interface.
+func (c *Checker) superDelegate(super *Named, delegate ast.Expr) {
+	switch {
+	case delegate == nil:
+	case super != nil && super.Class.Interface:
+		c.expect(delegate, super)
+	default:
+		c.checkExpr(delegate, nil)
+	}
+}
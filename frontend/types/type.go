@@ -0,0 +1,215 @@
+package types
+
+import (
+	"strings"
+
+	"gotlin/frontend/ast"
+)
+
+// Type is the static type of an expression or declaration.
+type Type interface {
+	String() string
+}
+
+// Class is a class, interface or object, built in or declared by the
+// program. Supertypes and members refer to the type parameters of the
+// class through TypeParam.
+type Class struct {
+	// Name is qualified with the names of the enclosing classes.
+	Name       string
+	TypeParams []string
+	// Covariant reports whether the class only produces values of its type
+	// parameters, as read-only collections do, so that List<Int> is a
+	// List<Any>. Other classes are invariant.
+	Covariant bool
+	Interface bool
+	Object    bool
+	Supers    []Type
+	// Open reports that the class has a supertype the checker does not
+	// know, which may provide any member and be any type.
+	Open    bool
+	Members map[string][]*Member
+	// Static holds what the class name gives access to: nested classes,
+	// enum entries and the members of the companion object.
+	Static map[string][]*Member
+	// Constructor is the signature of the primary constructor, nil for
+	// interfaces and objects.
+	Constructor *Signature
+	decl        ast.Stmt
+	// file is the file that declares the class.
+	file *sourceFile
+	// outer is the class the declaration is nested in, and companion its
+	// companion object.
+	outer     *Class
+	companion *Class
+}
+
+func newClass(name string, typeParams ...string) *Class {
+	return &Class{
+		Name:       name,
+		TypeParams: typeParams,
+		Members:    make(map[string][]*Member),
+		Static:     make(map[string][]*Member),
+	}
+}
+
+// subclasses returns the classes of the package of class that name it as
+// a supertype, in declaration order.
+func (class *Class) subclasses() []*Class {
+	if class.file == nil {
+		return nil
+	}
+	var found []*Class
+	for _, sub := range class.file.pkg.classes {
+		for _, super := range sub.Supers {
+			if isClass(super, class) {
+				found = append(found, sub)
+				break
+			}
+		}
+	}
+	return found
+}
+
+// Member is a property or method of a class. Built-in members carry their
+// Type or Signature, those of the program are computed from Decl when they
+// are first needed.
+type Member struct {
+	Name      string
+	Type      Type
+	Signature *Signature
+	Decl      any
+	// owner is the class of the program that declares the member, nil
+	// for built-in members.
+	owner *Class
+}
+
+// Signature is the signature of a function, method or constructor.
+type Signature struct {
+	Name       string
+	TypeParams []string
+	Params     []*Param
+	Result     Type
+	// Decl is the declaration of a function of the program.
+	Decl *ast.FunctionDecl
+}
+
+// Param is a parameter of a signature. A parameter with a Default may be
+// left out, a Vararg one takes any number of arguments.
+type Param struct {
+	Name    string
+	Type    Type
+	Default bool
+	Vararg  bool
+}
+
+func (s *Signature) String() string {
+	params := make([]string, len(s.Params))
+	for i, param := range s.Params {
+		params[i] = param.Name + ": " + param.Type.String()
+		if param.Vararg {
+			params[i] = "vararg " + params[i]
+		}
+	}
+	return "fun " + s.Name + "(" + strings.Join(params, ", ") + "): " + s.Result.String()
+}
+
+// Named is a class type, with the type arguments of a generic class.
+type Named struct {
+	Class *Class
+	Args  []Type
+	alias string
+}
+
+func (t *Named) String() string {
+	if t.alias != "" {
+		return t.alias
+	}
+	if len(t.Args) == 0 {
+		return t.Class.Name
+	}
+	return t.Class.Name + "<" + typeStrings(t.Args) + ">"
+}
+
+// Nullable is `Type?`, which also holds null.
+type Nullable struct {
+	Type  Type
+	alias string
+}
+
+func (t *Nullable) String() string {
+	if t.alias != "" {
+		return t.alias
+	}
+	if fn, ok := t.Type.(*Function); ok && fn.alias == "" {
+		return "(" + t.Type.String() + ")?"
+	}
+	return t.Type.String() + "?"
+}
+
+// Function is the type of functions and lambdas, `(Int) -> String`.
+type Function struct {
+	Params []Type
+	Result Type
+	alias  string
+}
+
+func (t *Function) String() string {
+	if t.alias != "" {
+		return t.alias
+	}
+	return "(" + typeStrings(t.Params) + ") -> " + t.Result.String()
+}
+
+// TypeParam is a type parameter of a built-in generic class or function.
+type TypeParam struct {
+	Name string
+}
+
+func (t *TypeParam) String() string { return t.Name }
+
+// classifier is the type of a class name used as a value, through which
+// its static members are reached. Calling it constructs an instance.
+type classifier struct {
+	class *Class
+}
+
+func (t *classifier) String() string { return t.class.Name }
+
+type unknown struct{}
+
+func (unknown) String() string { return "???" }
+
+// Unknown is the type of expressions the checker cannot type, such as
+// members of a receiver whose class it does not know. It is compatible with
+// every type, so that one unknown does not cascade into mismatches.
+var Unknown Type = unknown{}
+
+// aliased returns t as written through the type alias called name, which
+// messages show instead of its expansion. The alias is dropped as soon as
+// the type is rebuilt, as substituting type arguments does.
+func aliased(t Type, name string) Type {
+	switch tp := t.(type) {
+	case *Named:
+		copied := *tp
+		copied.alias = name
+		return &copied
+	case *Nullable:
+		copied := *tp
+		copied.alias = name
+		return &copied
+	case *Function:
+		copied := *tp
+		copied.alias = name
+		return &copied
+	}
+	return t
+}
+
+func typeStrings(types []Type) string {
+	strs := make([]string, len(types))
+	for i, t := range types {
+		strs[i] = t.String()
+	}
+	return strings.Join(strs, ", ")
+}
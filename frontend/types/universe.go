@@ -0,0 +1,572 @@
+package types
+
+import (
+	"gotlin/frontend/ast"
+	"gotlin/frontend/object"
+)
+
+// The built-in classes.
+var (
+	anyClass          = newClass("Any")
+	nothingClass      = newClass("Nothing")
+	unitClass         = newClass("Unit")
+	numberClass       = newClass("Number")
+	intClass          = newClass("Int")
+	longClass         = newClass("Long")
+	shortClass        = newClass("Short")
+	byteClass         = newClass("Byte")
+	doubleClass       = newClass("Double")
+	floatClass        = newClass("Float")
+	charClass         = newClass("Char")
+	booleanClass      = newClass("Boolean")
+	charSequenceClass = newClass("CharSequence")
+	stringClass       = newClass("String")
+	comparableClass   = newClass("Comparable", "T")
+	iterableClass     = newClass("Iterable", "E")
+	collectionClass   = newClass("Collection", "E")
+	listClass         = newClass("List", "E")
+	mutableListClass  = newClass("MutableList", "E")
+	setClass          = newClass("Set", "E")
+	mutableSetClass   = newClass("MutableSet", "E")
+	mapClass          = newClass("Map", "K", "V")
+	mutableMapClass   = newClass("MutableMap", "K", "V")
+	entryClass        = newClass("Map.Entry", "K", "V")
+	arrayClass        = newClass("Array", "E")
+	intRangeClass     = newClass("IntRange")
+	charRangeClass    = newClass("CharRange")
+	pairClass         = newClass("Pair", "A", "B")
+	tripleClass       = newClass("Triple", "A", "B", "C")
+	lazyClass         = newClass("Lazy", "T")
+	builderClass      = newClass("StringBuilder")
+	delegatesClass    = newClass("Delegates")
+	propertyClass     = newClass("ReadWriteProperty", "T", "V")
+)
+
+// The built-in types.
+var (
+	Any     Type = &Named{Class: anyClass}
+	Nothing Type = &Named{Class: nothingClass}
+	Unit    Type = &Named{Class: unitClass}
+	Int     Type = &Named{Class: intClass}
+	Long    Type = &Named{Class: longClass}
+	Short   Type = &Named{Class: shortClass}
+	Byte    Type = &Named{Class: byteClass}
+	Double  Type = &Named{Class: doubleClass}
+	Float   Type = &Named{Class: floatClass}
+	Char    Type = &Named{Class: charClass}
+	Boolean Type = &Named{Class: booleanClass}
+	String  Type = &Named{Class: stringClass}
+	// Null is the type of `null`.
+	Null Type = &Nullable{Type: Nothing}
+)
+
+var (
+	typeE = &TypeParam{Name: "E"}
+	typeK = &TypeParam{Name: "K"}
+	typeV = &TypeParam{Name: "V"}
+	typeA = &TypeParam{Name: "A"}
+	typeB = &TypeParam{Name: "B"}
+	typeC = &TypeParam{Name: "C"}
+	typeT = &TypeParam{Name: "T"}
+	typeR = &TypeParam{Name: "R"}
+
+	anyOrNull = &Nullable{Type: Any}
+
+	// throwable is the type every thrown value must have.
+	throwable Type
+)
+
+// builtinClasses are the classes every file sees, by name.
+var builtinClasses = map[string]*Class{}
+
+// builtinFunctions are the functions every file sees, by name.
+var builtinFunctions = map[string][]*Signature{}
+
+// nullableMembers holds the built-in members that are extensions of nullable
+// receivers, which can be called without `?.`.
+var nullableMembers = map[string]bool{
+	"toString":      true,
+	"equals":        true,
+	"hashCode":      true,
+	"isNullOrEmpty": true,
+	"isNullOrBlank": true,
+	"orEmpty":       true,
+}
+
+func named(class *Class, args ...Type) *Named {
+	return &Named{Class: class, Args: args}
+}
+
+func fn(params []Type, result Type) *Function {
+	return &Function{Params: params, Result: result}
+}
+
+func param(name string, t Type) *Param {
+	return &Param{Name: name, Type: t}
+}
+
+func optional(name string, t Type) *Param {
+	return &Param{Name: name, Type: t, Default: true}
+}
+
+func vararg(name string, t Type) *Param {
+	return &Param{Name: name, Type: t, Vararg: true}
+}
+
+func signature(name string, result Type, params ...*Param) *Signature {
+	return &Signature{Name: name, Params: params, Result: result}
+}
+
+// generic makes s generic in typeParams.
+func generic(s *Signature, typeParams ...string) *Signature {
+	s.TypeParams = typeParams
+	return s
+}
+
+func (c *Class) property(name string, t Type) {
+	c.Members[name] = append(c.Members[name], &Member{Name: name, Type: t})
+}
+
+func (c *Class) method(s *Signature) {
+	c.Members[s.Name] = append(c.Members[s.Name], &Member{Name: s.Name, Signature: s})
+}
+
+func (c *Class) methods(result Type, names ...string) {
+	for _, name := range names {
+		c.method(signature(name, result))
+	}
+}
+
+func builtinFunction(s *Signature) {
+	builtinFunctions[s.Name] = append(builtinFunctions[s.Name], s)
+}
+
+func init() {
+	for _, class := range []*Class{
+		anyClass, nothingClass, unitClass, numberClass, intClass, longClass,
+		shortClass, byteClass, doubleClass, floatClass, charClass,
+		booleanClass, charSequenceClass, stringClass, comparableClass,
+		iterableClass, collectionClass, listClass, mutableListClass,
+		setClass, mutableSetClass, mapClass, mutableMapClass, arrayClass,
+		intRangeClass, charRangeClass, pairClass, tripleClass, lazyClass,
+		builderClass, delegatesClass,
+	} {
+		builtinClasses[class.Name] = class
+	}
+	for _, class := range []*Class{iterableClass, collectionClass, listClass, setClass, mapClass, entryClass, pairClass, tripleClass, lazyClass} {
+		class.Covariant = true
+	}
+	for _, class := range []*Class{comparableClass, iterableClass, collectionClass, listClass, mutableListClass, setClass, mutableSetClass, mapClass, mutableMapClass, charSequenceClass} {
+		class.Interface = true
+	}
+
+	anyClass.methods(String, "toString")
+	anyClass.methods(Int, "hashCode")
+	anyClass.method(signature("equals", Boolean, param("other", anyOrNull)))
+
+	declareNumbers()
+	declareText()
+	declareCollections()
+	declareThrowables()
+	declareFunctions()
+}
+
+func declareNumbers() {
+	numberClass.Supers = []Type{Any}
+	for _, number := range []*Class{intClass, longClass, shortClass, byteClass, doubleClass, floatClass} {
+		self := &Named{Class: number}
+		number.Supers = []Type{&Named{Class: numberClass}, named(comparableClass, self)}
+		number.methods(Int, "toInt")
+		number.methods(Long, "toLong")
+		number.methods(Short, "toShort")
+		number.methods(Byte, "toByte")
+		number.methods(Double, "toDouble")
+		number.methods(Float, "toFloat")
+		number.methods(Char, "toChar")
+		number.method(signature("compareTo", Int, param("other", self)))
+		number.method(signature("coerceAtLeast", self, param("minimumValue", self)))
+		number.method(signature("coerceAtMost", self, param("maximumValue", self)))
+		number.method(signature("coerceIn", self, param("minimumValue", self), param("maximumValue", self)))
+	}
+	for _, name := range []string{"rangeTo", "until", "downTo"} {
+		intClass.method(signature(name, &Named{Class: intRangeClass}, param("other", Int)))
+	}
+	for _, integer := range []*Class{intClass, longClass, shortClass, byteClass} {
+		integer.methods(String, "toString")
+		integer.method(signature("toString", String, param("radix", Int)))
+	}
+	for _, name := range []string{"and", "or", "xor", "shl", "shr", "ushr"} {
+		intClass.method(signature(name, Int, param("other", Int)))
+		longClass.method(signature(name, Long, param("other", Long)))
+	}
+
+	booleanClass.Supers = []Type{named(comparableClass, Boolean)}
+	booleanClass.methods(Boolean, "not")
+	for _, name := range []string{"and", "or", "xor"} {
+		booleanClass.method(signature(name, Boolean, param("other", Boolean)))
+	}
+
+	charClass.Supers = []Type{named(comparableClass, Char)}
+	charClass.property("code", Int)
+	charClass.methods(Boolean, "isDigit", "isLetter", "isLetterOrDigit", "isWhitespace", "isUpperCase", "isLowerCase")
+	charClass.methods(Char, "uppercaseChar", "lowercaseChar")
+	charClass.methods(String, "uppercase", "lowercase")
+	charClass.methods(Int, "digitToInt")
+	charClass.method(signature("compareTo", Int, param("other", Char)))
+	charClass.method(signature("rangeTo", &Named{Class: charRangeClass}, param("other", Char)))
+}
+
+func declareText() {
+	charSequenceClass.Supers = []Type{Any}
+	charSequenceClass.property("length", Int)
+
+	stringClass.Supers = []Type{&Named{Class: charSequenceClass}, named(comparableClass, String)}
+	stringClass.property("length", Int)
+	stringClass.property("indices", &Named{Class: intRangeClass})
+	stringClass.property("lastIndex", Int)
+	stringClass.method(signature("plus", String, param("other", anyOrNull)))
+	stringClass.method(signature("get", Char, param("index", Int)))
+	stringClass.method(signature("compareTo", Int, param("other", String)))
+	stringClass.methods(String, "uppercase", "lowercase", "trim", "trimIndent", "reversed", "capitalize")
+	stringClass.methods(Boolean, "isEmpty", "isNotEmpty", "isBlank", "isNotBlank", "isNullOrEmpty", "isNullOrBlank")
+	stringClass.methods(String, "orEmpty")
+	stringClass.methods(Int, "toInt")
+	stringClass.methods(&Nullable{Type: Int}, "toIntOrNull")
+	stringClass.methods(Long, "toLong")
+	stringClass.methods(Double, "toDouble")
+	stringClass.methods(&Nullable{Type: Double}, "toDoubleOrNull")
+	stringClass.methods(Char, "first", "last")
+	stringClass.methods(named(listClass, String), "lines")
+	stringClass.methods(named(listClass, Char), "toList")
+	stringClass.method(signature("substring", String, param("startIndex", Int), optional("endIndex", Int)))
+	stringClass.method(signature("repeat", String, param("n", Int)))
+	stringClass.method(signature("padStart", String, param("length", Int), optional("padChar", Char)))
+	stringClass.method(signature("padEnd", String, param("length", Int), optional("padChar", Char)))
+	stringClass.method(signature("split", named(listClass, String), vararg("delimiters", String)))
+	for _, name := range []string{"startsWith", "endsWith", "contains"} {
+		stringClass.method(signature(name, Boolean, param("other", String)))
+	}
+	for _, name := range []string{"indexOf", "lastIndexOf"} {
+		stringClass.method(signature(name, Int, param("string", String)))
+	}
+	stringClass.method(signature("replace", String, param("oldValue", String), param("newValue", String)))
+	stringClass.method(signature("format", String, vararg("args", anyOrNull)))
+
+	builderClass.Supers = []Type{&Named{Class: charSequenceClass}}
+	builderClass.Constructor = signature("StringBuilder", &Named{Class: builderClass}, optional("value", String))
+	builderClass.property("length", Int)
+	builder := &Named{Class: builderClass}
+	builderClass.method(signature("append", builder, param("value", anyOrNull)))
+	builderClass.method(signature("appendLine", builder, optional("value", anyOrNull)))
+	builderClass.method(signature("insert", builder, param("index", Int), param("value", anyOrNull)))
+	builderClass.methods(builder, "clear", "reverse")
+}
+
+func declareCollections() {
+	iterable := func(t Type) Type { return named(iterableClass, t) }
+	list := func(t Type) Type { return named(listClass, t) }
+	predicate := fn([]Type{typeE}, Boolean)
+
+	comparableClass.Supers = []Type{Any}
+	iterableClass.Supers = []Type{Any}
+	iterableClass.method(signature("forEach", Unit, param("action", fn([]Type{typeE}, Unit))))
+	iterableClass.method(signature("forEachIndexed", Unit, param("action", fn([]Type{Int, typeE}, Unit))))
+	iterableClass.method(generic(signature("map", list(typeR), param("transform", fn([]Type{typeE}, typeR))), "R"))
+	iterableClass.method(generic(signature("mapIndexed", list(typeR), param("transform", fn([]Type{Int, typeE}, typeR))), "R"))
+	iterableClass.method(generic(signature("mapNotNull", list(typeR), param("transform", fn([]Type{typeE}, &Nullable{Type: typeR}))), "R"))
+	iterableClass.method(generic(signature("flatMap", list(typeR), param("transform", fn([]Type{typeE}, iterable(typeR)))), "R"))
+	iterableClass.method(generic(signature("fold", typeR, param("initial", typeR), param("operation", fn([]Type{typeR, typeE}, typeR))), "R"))
+	iterableClass.method(generic(signature("sortedBy", list(typeE), param("selector", fn([]Type{typeE}, typeR))), "R"))
+	iterableClass.method(generic(signature("sortedByDescending", list(typeE), param("selector", fn([]Type{typeE}, typeR))), "R"))
+	iterableClass.method(generic(signature("groupBy", named(mapClass, typeK, list(typeE)), param("keySelector", fn([]Type{typeE}, typeK))), "K"))
+	iterableClass.method(generic(signature("associateWith", named(mapClass, typeE, typeV), param("valueSelector", fn([]Type{typeE}, typeV))), "V"))
+	iterableClass.method(generic(signature("associateBy", named(mapClass, typeK, typeE), param("keySelector", fn([]Type{typeE}, typeK))), "K"))
+	iterableClass.method(generic(signature("maxOf", typeR, param("selector", fn([]Type{typeE}, typeR))), "R"))
+	iterableClass.method(generic(signature("minOf", typeR, param("selector", fn([]Type{typeE}, typeR))), "R"))
+	iterableClass.method(generic(signature("sumOf", typeR, param("selector", fn([]Type{typeE}, typeR))), "R"))
+	for _, name := range []string{"filter", "filterNot", "takeWhile", "dropWhile"} {
+		iterableClass.method(signature(name, list(typeE), param("predicate", predicate)))
+	}
+	for _, name := range []string{"any", "all", "none"} {
+		iterableClass.method(signature(name, Boolean, optional("predicate", predicate)))
+	}
+	iterableClass.method(signature("count", Int, optional("predicate", predicate)))
+	iterableClass.method(signature("first", typeE, optional("predicate", predicate)))
+	iterableClass.method(signature("last", typeE, optional("predicate", predicate)))
+	iterableClass.method(signature("firstOrNull", &Nullable{Type: typeE}, optional("predicate", predicate)))
+	iterableClass.method(signature("lastOrNull", &Nullable{Type: typeE}, optional("predicate", predicate)))
+	iterableClass.method(signature("find", &Nullable{Type: typeE}, param("predicate", predicate)))
+	iterableClass.method(signature("single", typeE))
+	iterableClass.method(signature("contains", Boolean, param("element", typeE)))
+	iterableClass.method(signature("indexOf", Int, param("element", typeE)))
+	iterableClass.method(signature("joinToString", String, optional("separator", String), optional("prefix", String), optional("postfix", String), optional("transform", fn([]Type{typeE}, anyOrNull))))
+	iterableClass.method(signature("take", list(typeE), param("n", Int)))
+	iterableClass.method(signature("drop", list(typeE), param("n", Int)))
+	iterableClass.methods(list(typeE), "toList", "sorted", "sortedDescending", "reversed", "distinct", "shuffled")
+	iterableClass.methods(named(mutableListClass, typeE), "toMutableList")
+	iterableClass.methods(named(setClass, typeE), "toSet")
+	iterableClass.methods(named(mutableSetClass, typeE), "toMutableSet")
+	iterableClass.methods(&Nullable{Type: typeE}, "maxOrNull", "minOrNull")
+	iterableClass.methods(typeE, "sum")
+	iterableClass.methods(list(named(pairClass, Int, typeE)), "withIndex")
+	iterableClass.method(generic(signature("zip", list(named(pairClass, typeE, typeR)), param("other", iterable(typeR))), "R"))
+	iterableClass.method(signature("plus", list(typeE), param("elements", iterable(typeE))))
+	iterableClass.method(signature("minus", list(typeE), param("elements", iterable(typeE))))
+
+	collectionClass.Supers = []Type{iterable(typeE)}
+	collectionClass.property("size", Int)
+	collectionClass.methods(Boolean, "isEmpty", "isNotEmpty")
+	collectionClass.method(signature("containsAll", Boolean, param("elements", named(collectionClass, typeE))))
+
+	listClass.Supers = []Type{named(collectionClass, typeE)}
+	listClass.property("lastIndex", Int)
+	listClass.property("indices", &Named{Class: intRangeClass})
+	listClass.method(signature("get", typeE, param("index", Int)))
+	listClass.method(signature("getOrNull", &Nullable{Type: typeE}, param("index", Int)))
+	listClass.method(signature("getOrElse", typeE, param("index", Int), param("defaultValue", fn([]Type{Int}, typeE))))
+	listClass.method(signature("subList", list(typeE), param("fromIndex", Int), param("toIndex", Int)))
+
+	declareMutable := func(class *Class) {
+		class.method(signature("add", Boolean, param("element", typeE)))
+		class.method(signature("addAll", Boolean, param("elements", iterable(typeE))))
+		class.method(signature("remove", Boolean, param("element", typeE)))
+		class.method(signature("removeAll", Boolean, param("elements", iterable(typeE))))
+		class.method(signature("removeIf", Boolean, param("predicate", predicate)))
+		class.method(signature("retainAll", Boolean, param("elements", iterable(typeE))))
+		class.methods(Unit, "clear")
+	}
+	listClass.methods(typeE, "component1", "component2", "component3", "component4", "component5")
+	mutableListClass.Supers = []Type{list(typeE)}
+	declareMutable(mutableListClass)
+	mutableListClass.method(signature("removeAt", typeE, param("index", Int)))
+	mutableListClass.method(signature("set", typeE, param("index", Int), param("element", typeE)))
+	mutableListClass.methods(typeE, "removeFirst", "removeLast")
+	mutableListClass.methods(Unit, "sort", "reverse", "shuffle")
+
+	setClass.Supers = []Type{named(collectionClass, typeE)}
+	mutableSetClass.Supers = []Type{named(setClass, typeE)}
+	declareMutable(mutableSetClass)
+
+	mapClass.Supers = []Type{Any}
+	mapClass.property("size", Int)
+	mapClass.property("keys", named(setClass, typeK))
+	mapClass.property("values", named(collectionClass, typeV))
+	mapClass.property("entries", named(setClass, named(entryClass, typeK, typeV)))
+	mapClass.methods(Boolean, "isEmpty", "isNotEmpty")
+	mapClass.method(signature("get", &Nullable{Type: typeV}, param("key", typeK)))
+	mapClass.method(signature("getValue", typeV, param("key", typeK)))
+	mapClass.method(signature("getOrDefault", typeV, param("key", typeK), param("defaultValue", typeV)))
+	mapClass.method(signature("getOrElse", typeV, param("key", typeK), param("defaultValue", fn(nil, typeV))))
+	mapClass.method(signature("containsKey", Boolean, param("key", typeK)))
+	mapClass.method(signature("containsValue", Boolean, param("value", typeV)))
+	mapClass.method(signature("contains", Boolean, param("key", typeK)))
+	mapClass.method(signature("forEach", Unit, param("action", fn([]Type{named(entryClass, typeK, typeV)}, Unit))))
+	mapClass.method(generic(signature("map", list(typeR), param("transform", fn([]Type{named(entryClass, typeK, typeV)}, typeR))), "R"))
+	mapClass.method(generic(signature("mapValues", named(mapClass, typeK, typeR), param("transform", fn([]Type{named(entryClass, typeK, typeV)}, typeR))), "R"))
+	mapClass.method(signature("filter", named(mapClass, typeK, typeV), param("predicate", fn([]Type{named(entryClass, typeK, typeV)}, Boolean))))
+	mapClass.methods(list(named(pairClass, typeK, typeV)), "toList")
+	mapClass.methods(named(mutableMapClass, typeK, typeV), "toMutableMap")
+
+	mutableMapClass.Supers = []Type{named(mapClass, typeK, typeV)}
+	mutableMapClass.method(signature("put", &Nullable{Type: typeV}, param("key", typeK), param("value", typeV)))
+	mutableMapClass.method(signature("set", Unit, param("key", typeK), param("value", typeV)))
+	mutableMapClass.method(signature("remove", &Nullable{Type: typeV}, param("key", typeK)))
+	mutableMapClass.method(signature("getOrPut", typeV, param("key", typeK), param("defaultValue", fn(nil, typeV))))
+	mutableMapClass.method(signature("putAll", Unit, param("from", named(mapClass, typeK, typeV))))
+	mutableMapClass.methods(Unit, "clear")
+
+	entryClass.Supers = []Type{Any}
+	entryClass.property("key", typeK)
+	entryClass.property("value", typeV)
+	entryClass.methods(typeK, "component1")
+	entryClass.methods(typeV, "component2")
+
+	arrayClass.Supers = []Type{iterable(typeE)}
+	arrayClass.property("size", Int)
+	arrayClass.property("indices", &Named{Class: intRangeClass})
+	arrayClass.property("lastIndex", Int)
+	arrayClass.method(signature("get", typeE, param("index", Int)))
+	arrayClass.method(signature("set", Unit, param("index", Int), param("value", typeE)))
+	arrayClass.methods(Boolean, "isEmpty", "isNotEmpty")
+	arrayClass.methods(typeE, "component1", "component2", "component3", "component4", "component5")
+
+	intRangeClass.Supers = []Type{iterable(Int)}
+	intRangeClass.property("first", Int)
+	intRangeClass.property("last", Int)
+	intRangeClass.method(signature("step", &Named{Class: intRangeClass}, param("step", Int)))
+	intRangeClass.method(signature("contains", Boolean, param("value", Int)))
+	charRangeClass.Supers = []Type{iterable(Char)}
+	charRangeClass.method(signature("contains", Boolean, param("value", Char)))
+
+	pairClass.Supers = []Type{Any}
+	pairClass.Constructor = generic(signature("Pair", named(pairClass, typeA, typeB), param("first", typeA), param("second", typeB)), "A", "B")
+	pairClass.property("first", typeA)
+	pairClass.property("second", typeB)
+	pairClass.methods(typeA, "component1")
+	pairClass.methods(typeB, "component2")
+	pairClass.methods(list(typeA), "toList")
+
+	tripleClass.Supers = []Type{Any}
+	tripleClass.Constructor = generic(signature("Triple", named(tripleClass, typeA, typeB, typeC), param("first", typeA), param("second", typeB), param("third", typeC)), "A", "B", "C")
+	tripleClass.property("first", typeA)
+	tripleClass.property("second", typeB)
+	tripleClass.property("third", typeC)
+	tripleClass.methods(typeA, "component1")
+	tripleClass.methods(typeB, "component2")
+	tripleClass.methods(typeC, "component3")
+
+	lazyClass.Supers = []Type{Any}
+	lazyClass.property("value", typeT)
+	lazyClass.methods(Boolean, "isInitialized")
+
+	propertyClass.Supers = []Type{Any}
+	propertyClass.method(signature("getValue", typeV, param("thisRef", typeT), param("property", anyOrNull)))
+	propertyClass.method(signature("setValue", Unit, param("thisRef", typeT), param("property", anyOrNull), param("value", typeV)))
+	delegatesClass.Object = true
+	delegatesClass.Supers = []Type{Any}
+	property := named(propertyClass, anyOrNull, typeT)
+	delegatesClass.method(generic(signature("notNull", property), "T"))
+	delegatesClass.method(generic(signature("observable", property, param("initialValue", typeT), param("onChange", fn([]Type{anyOrNull, typeT, typeT}, Unit))), "T"))
+	delegatesClass.method(generic(signature("vetoable", property, param("initialValue", typeT), param("onChange", fn([]Type{anyOrNull, typeT, typeT}, Boolean))), "T"))
+}
+
+// declareThrowables mirrors the Throwable hierarchy the runtime provides.
+func declareThrowables() {
+	classes := make(map[*object.Class]*Class)
+	var declare func(runtime *object.Class) *Class
+	declare = func(runtime *object.Class) *Class {
+		if class, ok := classes[runtime]; ok {
+			return class
+		}
+		class := newClass(runtime.Name)
+		classes[runtime] = class
+		builtinClasses[class.Name] = class
+		self := &Named{Class: class}
+		if runtime.Super == nil {
+			class.Supers = []Type{Any}
+			class.property("message", &Nullable{Type: String})
+			class.property("cause", &Nullable{Type: self})
+			class.methods(Unit, "printStackTrace")
+			class.methods(String, "stackTraceToString")
+		} else {
+			class.Supers = []Type{&Named{Class: declare(runtime.Super)}}
+		}
+		cause := &Nullable{Type: &Named{Class: declare(object.ThrowableClass)}}
+		class.Constructor = signature(class.Name, self, optional("message", &Nullable{Type: String}), optional("cause", cause))
+		return class
+	}
+	for _, name := range []string{
+		"Throwable", "Error", "Exception", "RuntimeException",
+		"IllegalArgumentException", "NumberFormatException",
+		"IllegalStateException", "ArithmeticException",
+		"NullPointerException", "ClassCastException",
+		"IndexOutOfBoundsException", "NoSuchElementException",
+		"UnsupportedOperationException",
+		"UninitializedPropertyAccessException",
+	} {
+		if runtime, ok := object.LookupThrowableClass(name); ok {
+			declare(runtime)
+		}
+	}
+	throwable = &Named{Class: declare(object.ThrowableClass)}
+}
+
+func declareFunctions() {
+	for _, name := range []string{"log", "print", "println"} {
+		builtinFunction(signature(name, Unit, optional("message", anyOrNull)))
+	}
+	builtinFunction(signature("error", Nothing, param("message", Any)))
+	builtinFunction(signature("TODO", Nothing, optional("reason", String)))
+	for _, name := range []string{"require", "check"} {
+		builtinFunction(signature(name, Unit, param("value", Boolean), optional("lazyMessage", fn(nil, Any))))
+	}
+	builtinFunction(generic(signature("requireNotNull", typeT, param("value", &Nullable{Type: typeT}), optional("lazyMessage", fn(nil, Any))), "T"))
+	builtinFunction(generic(signature("checkNotNull", typeT, param("value", &Nullable{Type: typeT}), optional("lazyMessage", fn(nil, Any))), "T"))
+	builtinFunction(signature("repeat", Unit, param("times", Int), param("action", fn([]Type{Int}, Unit))))
+	for _, name := range []string{"maxOf", "minOf"} {
+		builtinFunction(generic(signature(name, typeT, param("a", typeT), vararg("other", typeT)), "T"))
+	}
+
+	collections := []struct {
+		empty, of, mutableOf string
+		class, mutable       *Class
+	}{
+		{"emptyList", "listOf", "mutableListOf", listClass, mutableListClass},
+		{"emptySet", "setOf", "mutableSetOf", setClass, mutableSetClass},
+		{"emptyArray", "arrayOf", "", arrayClass, nil},
+	}
+	for _, collection := range collections {
+		builtinFunction(generic(signature(collection.empty, named(collection.class, typeT)), "T"))
+		builtinFunction(generic(signature(collection.of, named(collection.class, typeT), vararg("elements", typeT)), "T"))
+		if collection.mutable != nil {
+			builtinFunction(generic(signature(collection.mutableOf, named(collection.mutable, typeT), vararg("elements", typeT)), "T"))
+		}
+	}
+	pair := named(pairClass, typeK, typeV)
+	builtinFunction(generic(signature("emptyMap", named(mapClass, typeK, typeV)), "K", "V"))
+	builtinFunction(generic(signature("mapOf", named(mapClass, typeK, typeV), vararg("pairs", pair)), "K", "V"))
+	builtinFunction(generic(signature("mutableMapOf", named(mutableMapClass, typeK, typeV), vararg("pairs", pair)), "K", "V"))
+
+	builtinFunction(generic(signature("lazy", named(lazyClass, typeT), param("initializer", fn(nil, typeT))), "T"))
+	builtinFunction(generic(signature("run", typeR, param("block", fn(nil, typeR))), "R"))
+	builtinFunction(generic(signature("with", typeR, param("receiver", typeT), param("block", fn(nil, typeR))), "T", "R"))
+	builtinFunction(signature("buildString", String, param("builderAction", fn(nil, Unit))))
+	builtinFunction(generic(signature("buildList", named(listClass, typeE), param("builderAction", fn(nil, Unit))), "E"))
+	builtinFunction(generic(signature("buildMap", named(mapClass, typeK, typeV), param("builderAction", fn(nil, Unit))), "K", "V"))
+}
+
+// standardPackages are the packages of the standard library. Their
+// declarations are seen without imports.
+var standardPackages = map[string]bool{
+	"kotlin":             true,
+	"kotlin.collections": true,
+	"kotlin.properties":  true,
+	"kotlin.ranges":      true,
+	"kotlin.text":        true,
+}
+
+// IsStandardPackage reports whether name is a package of the standard
+// library.
+func IsStandardPackage(name string) bool {
+	return standardPackages[name]
+}
+
+// LookupBuiltin returns the kind of symbol, as in ast.Symbol, of the
+// standard library declaration called name that every file sees: a
+// class, an object, a function, or a member of Any.
+func LookupBuiltin(name string) (string, bool) {
+	if class, ok := builtinClasses[name]; ok {
+		if class.Object {
+			return ast.SymbolObject, true
+		}
+		return ast.SymbolClass, true
+	}
+	if len(builtinFunctions[name]) > 0 || len(anyClass.Members[name]) > 0 {
+		return ast.SymbolFunction, true
+	}
+	return "", false
+}
+
+// IsBuiltinClass reports whether name is a class of the standard library.
+func IsBuiltinClass(name string) bool {
+	_, ok := builtinClasses[name]
+	return ok
+}
+
+// HasBuiltinMember reports whether a value of the built-in class called
+// class has a member called name, counting the scope functions every value
+// has.
+func HasBuiltinMember(class, name string) bool {
+	builtin, ok := builtinClasses[class]
+	if !ok {
+		return false
+	}
+	members, _ := inherited(&Named{Class: builtin}, name)
+	return len(members) > 0 || nullableMembers[name] || scopeFunction(Any, name) != nil
+}
+
+// ReceiverLambda reports whether the lambda given to the standard library
+// function called name runs with a receiver.
+func ReceiverLambda(name string) bool {
+	return receiverLambdas[name]
+}
@@ -0,0 +1,168 @@
+package types
+
+import (
+	"gotlin/frontend/ast"
+	"gotlin/frontend/token"
+)
+
+// visibility is how far a member of a class can be seen, along with the
+// setter of a property, which may be seen less far.
+type visibility struct {
+	get token.Token
+	set token.Token
+	// pos is where the member is declared.
+	pos token.Pos
+}
+
+// visibilityOf returns the visibility of a member the program declares.
+// Declarations without a visibility modifier are public.
+func visibilityOf(m *Member) visibility {
+	var modifiers, setter ast.Modifiers
+	var pos token.Pos
+	switch decl := m.Decl.(type) {
+	case *ast.VariableDecl:
+		modifiers, pos = decl.Modifiers, decl.Name.Position
+		if decl.Setter != nil {
+			setter = decl.Setter.Modifiers
+		}
+	case *ast.FunctionDecl:
+		modifiers, pos = decl.Modifiers, decl.Name.Position
+	case *ast.ClassParam:
+		modifiers, pos = decl.Modifiers, decl.Position
+	case nil:
+		var class *Class
+		switch t := m.Type.(type) {
+		case *classifier:
+			class = t.class
+		case *Named:
+			class = t.Class
+		}
+		if class != nil && class.decl != nil {
+			modifiers = ast.DeclarationModifiers(class.decl)
+			pos = ast.DeclaredNames(class.decl)[0].Position
+		}
+	}
+	v := visibility{get: token.Token{Spelling: ast.Public}, pos: pos}
+	if modifier, ok := modifiers.Visibility(); ok {
+		v.get = modifier
+	}
+	v.set = v.get
+	if modifier, ok := setter.Visibility(); ok {
+		v.set = modifier
+	}
+	return v
+}
+
+// checkAccess reports a use of the member called name of a value of type
+// receiver that its visibility does not allow from the code being checked.
+// An assignment needs the setter to be visible as well. Everything checked
+// together forms one module, so internal members are visible everywhere.
+func (c *Checker) checkAccess(receiver Type, name token.Token, assign bool) {
+	members, _ := c.members(receiver, name.Spelling)
+	c.checkMembers(members, name, assign)
+}
+
+// checkImplicitAccess is checkAccess for a name bound to a member of a
+// class without a receiver, which `this` or an outer receiver provides.
+func (c *Checker) checkImplicitAccess(id *ast.IdentifierExpr, assign bool) {
+	if id.Binding == nil {
+		return
+	}
+	if owner, ok := c.owners[id.Binding.Symbol.Decl]; ok {
+		c.checkMembers(owner.Members[id.Value.Spelling], id.Value, assign)
+	}
+}
+
+// checkMembers reports a use of name that the visibility of none of the
+// members it may refer to allows.
+func (c *Checker) checkMembers(members []*Member, name token.Token, assign bool) {
+	var denied, readOnly *Member
+	for _, m := range members {
+		if m.owner == nil {
+			return
+		}
+		v := visibilityOf(m)
+		switch {
+		case !c.accessible(m.owner, v.get.Spelling):
+			if denied == nil {
+				denied = m
+			}
+		case assign && !c.accessible(m.owner, v.set.Spelling):
+			if readOnly == nil {
+				readOnly = m
+			}
+		default:
+			return
+		}
+	}
+	if readOnly != nil {
+		v := visibilityOf(readOnly)
+		c.report(name.Position, "Cannot assign to '%s': the setter is %s in '%s' (declared at %s)", name.Spelling, v.set.Spelling, readOnly.owner.Name, c.declaredAt(readOnly.owner, v.pos))
+	} else if denied != nil {
+		v := visibilityOf(denied)
+		c.report(name.Position, "Cannot access '%s': it is %s in '%s' (declared at %s)", name.Spelling, v.get.Spelling, denied.owner.Name, c.declaredAt(denied.owner, v.pos))
+	}
+}
+
+// declaredAt locates a member of owner declared at pos, naming the file
+// when it is not the one being checked.
+func (c *Checker) declaredAt(owner *Class, pos token.Pos) string {
+	if owner.file == nil || owner.file.path == c.ctx.file.path {
+		return pos.String()
+	}
+	return owner.file.path + " " + pos.String()
+}
+
+// accessible reports whether a member of owner with the given visibility
+// can be used from the code being checked. Private members are visible
+// inside their class, which shares them with its companion, and protected
+// ones in subclasses too.
+func (c *Checker) accessible(owner *Class, visibility string) bool {
+	from := c.ctx.class
+	switch visibility {
+	case ast.Private:
+		return encloses(owner, from) || isCompanion(owner) && encloses(owner.outer, from)
+	case ast.Protected:
+		if encloses(owner, from) || isCompanion(owner) && encloses(owner.outer, from) {
+			return true
+		}
+		for class := from; class != nil; class = class.outer {
+			if inherits(class, owner, make(map[*Class]bool)) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// encloses reports whether class is outer or is nested in it.
+func encloses(outer *Class, class *Class) bool {
+	for ; class != nil; class = class.outer {
+		if class == outer {
+			return true
+		}
+	}
+	return false
+}
+
+func isCompanion(class *Class) bool {
+	return class.outer != nil && class.outer.companion == class
+}
+
+// inherits reports whether class is ancestor or one of its subclasses.
+func inherits(class *Class, ancestor *Class, seen map[*Class]bool) bool {
+	if class == ancestor {
+		return true
+	}
+	if seen[class] {
+		return false
+	}
+	seen[class] = true
+	for _, super := range class.Supers {
+		if named, ok := super.(*Named); ok && inherits(named.Class, ancestor, seen) {
+			return true
+		}
+	}
+	return false
+}
@@ -2,4 +2,4 @@ module gotlin
 
 go 1.21
 
-require github.com/sanity-io/litter v1.5.5 // indirect
+require github.com/sanity-io/litter v1.5.5
@@ -6,8 +6,10 @@ import (
 	"time"
 
 	"github.com/sanity-io/litter"
-	"gotlin/frontend/parser"
-	"gotlin/frontend/scanner"
+	"gotlin/frontend/checker"
+	"gotlin/frontend/module"
+	"gotlin/frontend/resolver"
+	"gotlin/frontend/types"
 )
 
 type Executor interface {
@@ -15,16 +17,37 @@ type Executor interface {
 }
 
 func main() {
-	file, err := os.Open("example.gt")
-	if err != nil {
-		panic(err)
+	entry := "example.gt"
+	if len(os.Args) > 1 {
+		entry = os.Args[1]
 	}
 
+	// Imported packages are resolved against the source root of the
+	// entry's package.
+	root, file := module.SourceRoot(entry)
+
 	start := time.Now()
-	s := scanner.NewScanner(file)
-	p := parser.New(s)
-	program := p.Parse()
+	program, errs := module.NewLoader(root).Load(file)
+	exports := program.Exports()
+	var warnings []error
+	// The type checker sees the declarations of the packages it checked
+	// before, which are the ones each package imports.
+	typeChecker := types.New()
+	for _, pkg := range program.Packages {
+		errs = append(errs, resolver.New().ResolvePackage(pkg.Programs(), exports)...)
+		errs = append(errs, typeChecker.CheckPackage(pkg.Programs())...)
+		warnings = append(warnings, typeChecker.Warnings()...)
+		errs = append(errs, checker.New().CheckPackage(pkg.Programs())...)
+	}
 	duration := time.Since(start)
-	litter.Dump(program)
+	for _, pkg := range program.Packages {
+		litter.Dump(pkg.Programs())
+	}
+	for _, warning := range warnings {
+		fmt.Println("warning:", warning)
+	}
+	for _, err := range errs {
+		fmt.Println(err)
+	}
 	fmt.Printf("\n\nExecution time: %s\n", duration)
 }